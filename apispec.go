@@ -0,0 +1,9 @@
+// Package bookshop embeds assets that ship with the bookstore binaries.
+package bookshop
+
+import _ "embed"
+
+// APISpec is the OpenAPI document describing the HTTP API.
+//
+//go:embed apispec.json
+var APISpec []byte
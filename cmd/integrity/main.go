@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// Integrity reports orders whose user or book no longer exists.
+// It exits with status 1 when orphaned orders are found.
+func main() {
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	// Initialize database
+	database, err := config.NewDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	repos := config.NewRepositories(cfg.Database.Driver, database)
+	integrityUsecase := usecase.NewIntegrityUsecase(repos.Orders)
+
+	orphans, err := integrityUsecase.FindOrphanedOrders(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to check integrity: %v", err)
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned orders found")
+		return
+	}
+
+	fmt.Printf("Found %d orphaned orders:\n", len(orphans))
+	for _, orphan := range orphans {
+		var missing string
+		switch {
+		case orphan.MissingUser && orphan.MissingBook:
+			missing = "user and book"
+		case orphan.MissingUser:
+			missing = "user"
+		default:
+			missing = "book"
+		}
+		fmt.Printf("  order %d: missing %s (user_id=%d, book_id=%d)\n",
+			orphan.OrderID, missing, orphan.UserID, orphan.BookID)
+	}
+
+	os.Exit(1)
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
-	"kikukafandi/book-shop-api/internal/adapter/db"
+	bookshop "kikukafandi/book-shop-api"
 	httpAdapter "kikukafandi/book-shop-api/internal/adapter/http"
 	"kikukafandi/book-shop-api/internal/config"
 	"kikukafandi/book-shop-api/internal/usecase"
@@ -26,28 +28,99 @@ func main() {
 		log.Fatalf("Failed to run migration: %v", err)
 	}
 
+	// Initialize mailer
+	mailer, err := config.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
+	// Initialize low-stock notifier
+	stockNotifier, err := config.NewStockNotifier(cfg.Alert, mailer)
+	if err != nil {
+		log.Fatalf("Failed to initialize stock notifier: %v", err)
+	}
+
+	// Initialize tax calculator
+	taxCalculator, err := config.NewTaxCalculator(cfg.Tax)
+	if err != nil {
+		log.Fatalf("Failed to initialize tax calculator: %v", err)
+	}
+
+	shippingCalculator, err := config.NewShippingCalculator(cfg.Shipping)
+	if err != nil {
+		log.Fatalf("Failed to initialize shipping calculator: %v", err)
+	}
+
+	// Initialize payment gateway
+	paymentGateway, err := config.NewPaymentGateway(cfg.Payment)
+	if err != nil {
+		log.Fatalf("Failed to initialize payment gateway: %v", err)
+	}
+
 	// ====================================
 	// DEPENDENCY INJECTION (Composition Root)
 	// Flow: DB Repo → Usecase → Handler → Router
 	// ====================================
 
 	// Initialize repositories (adapters for database)
-	bookRepo := db.NewBookRepositoryMySQL(database)
-	userRepo := db.NewUserRepositoryMySQL(database)
-	orderRepo := db.NewOrderRepositoryMySQL(database)
+	repos := config.NewRepositories(cfg.Database.Driver, database)
+	bookRepo := repos.Books
+	userRepo := repos.Users
+	orderRepo := repos.Orders
+	passwordResetRepo := repos.PasswordResets
+	stockMovementRepo := repos.StockMovements
+	reservationRepo := repos.Reservations
+	paymentRepo := repos.Payments
 
 	// Initialize usecases (business logic)
-	bookUsecase := usecase.NewBookUsecase(bookRepo)
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	orderUsecase := usecase.NewOrderUsecase(orderRepo, bookRepo, userRepo)
+	pricingService := usecase.NewPricingService(repos.BookPrices, bookRepo)
+	bookUsecase := usecase.NewBookUsecase(bookRepo, orderRepo, pricingService)
+	userUsecase := usecase.NewUserUsecase(userRepo, orderRepo, mailer, usecase.VerificationConfig{
+		Secret:         cfg.Auth.VerificationSecret,
+		AppURL:         cfg.Auth.AppURL,
+		TokenTTL:       cfg.Auth.VerificationTokenTTL,
+		ResendInterval: cfg.Auth.VerificationResendInterval,
+	})
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, bookRepo, userRepo, reservationRepo, paymentRepo, repos.Promotions, repos.Addresses, pricingService, taxCalculator, shippingCalculator, paymentGateway, stockNotifier, mailer, usecase.OrderConfig{
+		RequireVerifiedEmail:    cfg.Auth.RequireVerifiedEmail,
+		ReservationTTL:          cfg.Order.ReservationTTL,
+		BackorderReservationTTL: cfg.Order.BackorderReservationTTL,
+		TaxRegion:               cfg.Tax.Region,
+	})
+	passwordUsecase := usecase.NewPasswordUsecase(userRepo, passwordResetRepo, mailer, cfg.Auth.ResetURL, cfg.Auth.ResetTokenTTL)
+	inventoryUsecase := usecase.NewInventoryUsecase(bookRepo, stockMovementRepo, orderUsecase, pricingService)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(repos.IdempotencyKeys, cfg.Idempotency.KeyTTL)
+	returnUsecase := usecase.NewReturnUsecase(repos.ReturnRequests, orderRepo, bookRepo, paymentRepo, paymentGateway, orderUsecase)
+	promotionUsecase := usecase.NewPromotionUsecase(repos.Promotions, bookRepo)
+	addressUsecase := usecase.NewAddressUsecase(repos.Addresses, userRepo)
+	fulfilmentUsecase := usecase.NewFulfilmentUsecase(orderRepo, repos.OrderShipments, shippingCalculator)
+	webhookUsecase := usecase.NewWebhookUsecase(repos.WebhookEvents, orderUsecase, usecase.WebhookConfig{
+		Secret:    cfg.Payment.WebhookSecret,
+		Tolerance: cfg.Payment.WebhookTolerance,
+	})
 
 	// Initialize handlers (adapters for HTTP)
-	bookHandler := httpAdapter.NewBookHandler(bookUsecase)
+	bookHandler := httpAdapter.NewBookHandler(bookUsecase, pricingService)
 	userHandler := httpAdapter.NewUserHandler(userUsecase)
 	orderHandler := httpAdapter.NewOrderHandler(orderUsecase)
+	passwordHandler := httpAdapter.NewPasswordHandler(passwordUsecase)
+	inventoryHandler := httpAdapter.NewInventoryHandler(inventoryUsecase)
+	webhookHandler := httpAdapter.NewWebhookHandler(webhookUsecase)
+	returnHandler := httpAdapter.NewReturnHandler(returnUsecase)
+	promotionHandler := httpAdapter.NewPromotionHandler(promotionUsecase)
+	addressHandler := httpAdapter.NewAddressHandler(addressUsecase)
+	fulfilmentHandler := httpAdapter.NewFulfilmentHandler(fulfilmentUsecase)
+	docsHandler := httpAdapter.NewDocsHandler(bookshop.APISpec)
+	idempotencyMiddleware := httpAdapter.NewIdempotencyMiddleware(idempotencyUsecase)
+
+	// Release stock held by pending orders that were never completed
+	go sweepReservations(context.Background(), orderUsecase, cfg.Order.SweepInterval)
+
+	// Forget idempotency keys once clients can no longer retry with them
+	go sweepIdempotencyKeys(context.Background(), idempotencyUsecase, cfg.Idempotency.SweepInterval)
 
 	// Initialize router
-	router := httpAdapter.NewRouter(bookHandler, userHandler, orderHandler)
+	router := httpAdapter.NewRouter(bookHandler, userHandler, orderHandler, passwordHandler, inventoryHandler, webhookHandler, returnHandler, promotionHandler, addressHandler, fulfilmentHandler, docsHandler, idempotencyMiddleware)
 	httpRouter := router.Setup()
 
 	// Start server
@@ -58,3 +131,42 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// sweepReservations cancels pending orders with expired reservations every interval.
+func sweepReservations(ctx context.Context, orderUsecase *usecase.OrderUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := orderUsecase.ExpireReservations(ctx, now)
+			if err != nil {
+				log.Printf("Failed to expire reservations: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("Cancelled %d orders with expired reservations", expired)
+			}
+		}
+	}
+}
+
+// sweepIdempotencyKeys deletes expired idempotency keys every interval.
+func sweepIdempotencyKeys(ctx context.Context, idempotencyUsecase *usecase.IdempotencyUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := idempotencyUsecase.ExpireKeys(ctx, now); err != nil {
+				log.Printf("Failed to expire idempotency keys: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// Purge permanently removes records that were soft deleted longer than the retention period ago.
+func main() {
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	retention := flag.Duration("retention", cfg.Purge.Retention, "how long soft deleted records are kept")
+	flag.Parse()
+
+	// Initialize database
+	database, err := config.NewDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	repos := config.NewRepositories(cfg.Database.Driver, database)
+	purgeUsecase := usecase.NewPurgeUsecase(repos.Books, repos.Users, repos.Orders)
+
+	output, err := purgeUsecase.Purge(context.Background(), *retention)
+	if err != nil {
+		log.Fatalf("Failed to purge records: %v", err)
+	}
+
+	log.Printf("Purged records deleted more than %s ago: %d orders, %d books, %d users",
+		*retention, output.Orders, output.Books, output.Users)
+}
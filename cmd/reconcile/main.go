@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// Reconcile recomputes book stock from the stock movement ledger.
+// It reports books whose stock disagrees with the ledger and exits with
+// status 1, unless -apply is given to reset their stock to the ledger.
+func main() {
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	apply := flag.Bool("apply", false, "reset drifted stock to the ledger balance")
+	flag.Parse()
+
+	// Initialize database
+	database, err := config.NewDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Stock reset upwards is handed to backordered orders
+	mailer, err := config.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	stockNotifier, err := config.NewStockNotifier(cfg.Alert, mailer)
+	if err != nil {
+		log.Fatalf("Failed to initialize stock notifier: %v", err)
+	}
+
+	taxCalculator, err := config.NewTaxCalculator(cfg.Tax)
+	if err != nil {
+		log.Fatalf("Failed to initialize tax calculator: %v", err)
+	}
+
+	shippingCalculator, err := config.NewShippingCalculator(cfg.Shipping)
+	if err != nil {
+		log.Fatalf("Failed to initialize shipping calculator: %v", err)
+	}
+
+	paymentGateway, err := config.NewPaymentGateway(cfg.Payment)
+	if err != nil {
+		log.Fatalf("Failed to initialize payment gateway: %v", err)
+	}
+
+	repos := config.NewRepositories(cfg.Database.Driver, database)
+	pricingService := usecase.NewPricingService(repos.BookPrices, repos.Books)
+	orderUsecase := usecase.NewOrderUsecase(repos.Orders, repos.Books, repos.Users, repos.Reservations, repos.Payments, repos.Promotions, repos.Addresses, pricingService, taxCalculator, shippingCalculator, paymentGateway, stockNotifier, mailer, usecase.OrderConfig{
+		RequireVerifiedEmail:    cfg.Auth.RequireVerifiedEmail,
+		ReservationTTL:          cfg.Order.ReservationTTL,
+		BackorderReservationTTL: cfg.Order.BackorderReservationTTL,
+		TaxRegion:               cfg.Tax.Region,
+	})
+	inventoryUsecase := usecase.NewInventoryUsecase(repos.Books, repos.StockMovements, orderUsecase, pricingService)
+
+	drifts, err := inventoryUsecase.Reconcile(context.Background(), *apply)
+	if err != nil {
+		log.Fatalf("Failed to reconcile stock: %v", err)
+	}
+
+	if len(drifts) == 0 {
+		fmt.Println("Stock matches the ledger")
+		return
+	}
+
+	fmt.Printf("Found %d books whose stock disagrees with the ledger:\n", len(drifts))
+	for _, drift := range drifts {
+		fmt.Printf("  book %d (%s): stock %d, ledger %d\n", drift.BookID, drift.Title, drift.Stock, drift.LedgerStock)
+	}
+
+	if *apply {
+		fmt.Println("Stock has been reset to the ledger")
+		return
+	}
+
+	os.Exit(1)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	httpAdapter "kikukafandi/book-shop-api/internal/adapter/http"
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// Replay re-sends stored payment webhooks to a running server, signed
+// with the configured secret, e.g. after fixing a bug that made them fail.
+// It exits with status 1 if the server rejects any of them.
+func main() {
+	// Load configuration
+	cfg := config.LoadConfig()
+
+	defaultURL := fmt.Sprintf("http://%s:%s/webhooks/payments", cfg.Server.Host, cfg.Server.Port)
+	url := flag.String("url", defaultURL, "webhook endpoint to send the events to")
+	eventID := flag.String("event", "", "replay only the event with this provider event ID")
+	unprocessed := flag.Bool("unprocessed", false, "replay only events that were not processed")
+	flag.Parse()
+
+	// Initialize database
+	database, err := config.NewDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Events are only read and signed here; the server processes them
+	repos := config.NewRepositories(cfg.Database.Driver, database)
+	webhookUsecase := usecase.NewWebhookUsecase(repos.WebhookEvents, nil, usecase.WebhookConfig{
+		Secret:    cfg.Payment.WebhookSecret,
+		Tolerance: cfg.Payment.WebhookTolerance,
+	})
+
+	events, err := webhookUsecase.StoredEvents(context.Background(), usecase.StoredWebhooksInput{
+		EventID:     *eventID,
+		Unprocessed: *unprocessed,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load webhook events: %v", err)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No webhook events to replay")
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var failed int
+	for _, event := range events {
+		resp, err := send(client, *url, event.Payload, webhookUsecase.Sign(event.Payload, time.Now()))
+		if err != nil {
+			fmt.Printf("  %s (%s): %v\n", event.EventID, event.Type, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  %s (%s): %s\n", event.EventID, event.Type, resp.Status)
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			failed++
+		}
+	}
+
+	fmt.Printf("Replayed %d webhook events, %d failed\n", len(events), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// send posts a signed webhook payload. The response body is discarded.
+func send(client *http.Client, url string, payload []byte, signature string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(httpAdapter.PaymentSignatureHeader, signature)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return resp, nil
+}
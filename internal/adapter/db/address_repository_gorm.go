@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// AddressColumns are the columns of an address. They are embedded in
+// AddressModel and, with a prefix, twice in OrderModel.
+type AddressColumns struct {
+	Name       string `gorm:"size:255"`
+	Line1      string `gorm:"size:255"`
+	Line2      string `gorm:"size:255"`
+	City       string `gorm:"size:255"`
+	Region     string `gorm:"size:100"`
+	PostalCode string `gorm:"size:20"`
+	Country    string `gorm:"size:2"`
+	Phone      string `gorm:"size:50"`
+}
+
+// AddressModel is the database model for Address.
+type AddressModel struct {
+	ID             uint       `gorm:"primaryKey"`
+	UserID         uint       `gorm:"not null;index"`
+	User           *UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Label          string     `gorm:"size:100"`
+	AddressColumns `gorm:"embedded"`
+	CreatedAt      time.Time `gorm:"not null"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for AddressModel.
+func (AddressModel) TableName() string {
+	return "addresses"
+}
+
+// AddressRepositoryGORM implements domain.AddressRepository using GORM (MySQL, SQLite or PostgreSQL).
+type AddressRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewAddressRepositoryGORM creates a new AddressRepositoryGORM.
+func NewAddressRepositoryGORM(db *gorm.DB) *AddressRepositoryGORM {
+	return &AddressRepositoryGORM{db: db}
+}
+
+// Save saves an address to database.
+func (r *AddressRepositoryGORM) Save(ctx context.Context, address domain.Address) (domain.Address, error) {
+	model := toAddressModel(address)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.Address{}, err
+	}
+
+	return toAddressDomain(model), nil
+}
+
+// Update updates an address in database.
+func (r *AddressRepositoryGORM) Update(ctx context.Context, address domain.Address) (domain.Address, error) {
+	model := toAddressModel(address)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.Address{}, err
+	}
+
+	return toAddressDomain(model), nil
+}
+
+// FindByID finds an address by ID.
+func (r *AddressRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.Address, error) {
+	var model AddressModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Address{}, domain.ErrAddressNotFound
+		}
+		return domain.Address{}, err
+	}
+
+	return toAddressDomain(model), nil
+}
+
+// FindByUserID returns a user's addresses, oldest first.
+func (r *AddressRepositoryGORM) FindByUserID(ctx context.Context, userID uint) ([]domain.Address, error) {
+	var models []AddressModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	addresses := make([]domain.Address, len(models))
+	for i, model := range models {
+		addresses[i] = toAddressDomain(model)
+	}
+
+	return addresses, nil
+}
+
+// Delete deletes an address from database.
+func (r *AddressRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&AddressModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrAddressNotFound
+	}
+
+	return nil
+}
+
+// toAddressModel converts domain.Address to AddressModel.
+func toAddressModel(address domain.Address) AddressModel {
+	return AddressModel{
+		ID:             address.ID,
+		UserID:         address.UserID,
+		Label:          address.Label,
+		AddressColumns: toAddressColumns(address.AddressSnapshot),
+		CreatedAt:      address.CreatedAt,
+		UpdatedAt:      address.UpdatedAt,
+	}
+}
+
+// toAddressDomain converts AddressModel to domain.Address.
+func toAddressDomain(model AddressModel) domain.Address {
+	return domain.Address{
+		ID:              model.ID,
+		UserID:          model.UserID,
+		Label:           model.Label,
+		AddressSnapshot: toAddressSnapshot(model.AddressColumns),
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+	}
+}
+
+// toAddressColumns converts domain.AddressSnapshot to AddressColumns.
+func toAddressColumns(snapshot domain.AddressSnapshot) AddressColumns {
+	return AddressColumns{
+		Name:       snapshot.Name,
+		Line1:      snapshot.Line1,
+		Line2:      snapshot.Line2,
+		City:       snapshot.City,
+		Region:     snapshot.Region,
+		PostalCode: snapshot.PostalCode,
+		Country:    snapshot.Country,
+		Phone:      snapshot.Phone,
+	}
+}
+
+// toAddressSnapshot converts AddressColumns to domain.AddressSnapshot.
+func toAddressSnapshot(columns AddressColumns) domain.AddressSnapshot {
+	return domain.AddressSnapshot{
+		Name:       columns.Name,
+		Line1:      columns.Line1,
+		Line2:      columns.Line2,
+		City:       columns.City,
+		Region:     columns.Region,
+		PostalCode: columns.PostalCode,
+		Country:    columns.Country,
+		Phone:      columns.Phone,
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// BookPriceModel is the database model for BookPrice.
+type BookPriceModel struct {
+	ID            uint       `gorm:"primaryKey"`
+	BookID        uint       `gorm:"not null;index:idx_book_prices_book_from"`
+	Book          *BookModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Type          string     `gorm:"size:20;not null"`
+	Price         float64    `gorm:"not null"`
+	EffectiveFrom time.Time  `gorm:"not null;index:idx_book_prices_book_from"`
+	EffectiveTo   *time.Time
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for BookPriceModel.
+func (BookPriceModel) TableName() string {
+	return "book_prices"
+}
+
+// BookPriceRepositoryGORM implements domain.BookPriceRepository using GORM (MySQL, SQLite or PostgreSQL).
+type BookPriceRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewBookPriceRepositoryGORM creates a new BookPriceRepositoryGORM.
+func NewBookPriceRepositoryGORM(db *gorm.DB) *BookPriceRepositoryGORM {
+	return &BookPriceRepositoryGORM{db: db}
+}
+
+// Save saves a price to database.
+func (r *BookPriceRepositoryGORM) Save(ctx context.Context, price domain.BookPrice) (domain.BookPrice, error) {
+	model := toBookPriceModel(price)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.BookPrice{}, err
+	}
+
+	return toBookPriceDomain(model), nil
+}
+
+// Update updates a price in database.
+func (r *BookPriceRepositoryGORM) Update(ctx context.Context, price domain.BookPrice) (domain.BookPrice, error) {
+	model := toBookPriceModel(price)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.BookPrice{}, err
+	}
+
+	return toBookPriceDomain(model), nil
+}
+
+// FindByBookID returns a book's price history ordered by effective_from, then ID.
+func (r *BookPriceRepositoryGORM) FindByBookID(ctx context.Context, bookID uint) ([]domain.BookPrice, error) {
+	return r.find(r.db.WithContext(ctx).Where("book_id = ?", bookID))
+}
+
+// FindEffective returns the prices of the given books in effect at a point in time.
+func (r *BookPriceRepositoryGORM) FindEffective(ctx context.Context, bookIDs []uint, at time.Time) ([]domain.BookPrice, error) {
+	if len(bookIDs) == 0 {
+		return []domain.BookPrice{}, nil
+	}
+
+	return r.find(r.db.WithContext(ctx).
+		Where("book_id IN ?", bookIDs).
+		Where("effective_from <= ?", at).
+		Where("effective_to IS NULL OR effective_to > ?", at))
+}
+
+// find returns the prices matching a query ordered by effective_from, then ID.
+func (r *BookPriceRepositoryGORM) find(query *gorm.DB) ([]domain.BookPrice, error) {
+	var models []BookPriceModel
+
+	if err := query.Order("effective_from").Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	prices := make([]domain.BookPrice, len(models))
+	for i, model := range models {
+		prices[i] = toBookPriceDomain(model)
+	}
+
+	return prices, nil
+}
+
+// toBookPriceModel converts domain.BookPrice to BookPriceModel.
+func toBookPriceModel(price domain.BookPrice) BookPriceModel {
+	return BookPriceModel{
+		ID:            price.ID,
+		BookID:        price.BookID,
+		Type:          price.Type,
+		Price:         price.Price,
+		EffectiveFrom: price.EffectiveFrom,
+		EffectiveTo:   price.EffectiveTo,
+		CreatedAt:     price.CreatedAt,
+	}
+}
+
+// toBookPriceDomain converts BookPriceModel to domain.BookPrice.
+func toBookPriceDomain(model BookPriceModel) domain.BookPrice {
+	return domain.BookPrice{
+		ID:            model.ID,
+		BookID:        model.BookID,
+		Type:          model.Type,
+		Price:         model.Price,
+		EffectiveFrom: model.EffectiveFrom,
+		EffectiveTo:   model.EffectiveTo,
+		CreatedAt:     model.CreatedAt,
+	}
+}
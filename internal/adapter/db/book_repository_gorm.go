@@ -0,0 +1,361 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookModel is the database model for Book.
+// GORM tags are only here in adapter layer - domain stays clean.
+type BookModel struct {
+	ID               uint           `gorm:"primaryKey"`
+	Title            string         `gorm:"size:255;not null"`
+	Category         string         `gorm:"size:100;index"`
+	Weight           int            `gorm:"not null;default:0"`
+	Price            float64        `gorm:"not null"`
+	Stock            int            `gorm:"not null"`
+	Reserved         int            `gorm:"not null;default:0"`
+	ReorderThreshold int            `gorm:"not null;default:0"`
+	ReorderQuantity  int            `gorm:"not null;default:0"`
+	AllowBackorder   bool           `gorm:"not null;default:false"`
+	AllowPreorder    bool           `gorm:"not null;default:false"`
+	ReleaseDate      *time.Time     `gorm:"index"`
+	Version          uint           `gorm:"not null;default:1"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name for BookModel.
+func (BookModel) TableName() string {
+	return "books"
+}
+
+// BookRepositoryGORM implements domain.BookRepository using GORM with
+// portable queries (MySQL or SQLite). PostgreSQL uses BookRepositoryPostgres,
+// which shares these queries but locks and searches books its own way.
+type BookRepositoryGORM struct {
+	db *gorm.DB
+	// lock is the row lock taken by UpdateStock.
+	lock clause.Locking
+}
+
+// NewBookRepositoryGORM creates a new BookRepositoryGORM.
+func NewBookRepositoryGORM(db *gorm.DB) *BookRepositoryGORM {
+	return &BookRepositoryGORM{
+		db: db,
+		// SQLite has no row locks; its driver drops the clause and
+		// relies on the database-wide write lock instead.
+		lock: clause.Locking{Strength: clause.LockingStrengthUpdate},
+	}
+}
+
+// Save saves a book to database and records its initial stock in the ledger.
+func (r *BookRepositoryGORM) Save(ctx context.Context, book domain.Book, movement domain.StockMovement) (domain.Book, error) {
+	model := toBookModel(book)
+	model.Version = 1
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&model).Error; err != nil {
+			return err
+		}
+
+		movement.BookID = model.ID
+		movement.Delta = model.Stock
+		movement.Balance = model.Stock
+		return recordMovement(tx, movement)
+	})
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	return toBookDomain(model), nil
+}
+
+// FindByID finds a book by ID.
+func (r *BookRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.Book, error) {
+	var model BookModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Book{}, domain.ErrBookNotFound
+		}
+		return domain.Book{}, err
+	}
+
+	return toBookDomain(model), nil
+}
+
+// FindAll returns a page of books, oldest first.
+func (r *BookRepositoryGORM) FindAll(ctx context.Context, page domain.Page) ([]domain.Book, int64, error) {
+	var models []BookModel
+
+	total, err := findPage(r.db.WithContext(ctx).Model(&BookModel{}), page, "id", &models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toBookDomains(models), total, nil
+}
+
+// Search returns books whose title contains the query, ignoring case.
+func (r *BookRepositoryGORM) Search(ctx context.Context, query string, page domain.Page) ([]domain.Book, int64, error) {
+	var models []BookModel
+
+	pattern := "%" + strings.ToLower(query) + "%"
+	matching := r.db.WithContext(ctx).Model(&BookModel{}).Where("LOWER(title) LIKE ?", pattern)
+	total, err := findPage(matching, page, "id", &models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toBookDomains(models), total, nil
+}
+
+// FindLowStock returns books whose available stock is below their reorder threshold.
+func (r *BookRepositoryGORM) FindLowStock(ctx context.Context) ([]domain.Book, error) {
+	var models []BookModel
+
+	if err := r.db.WithContext(ctx).
+		Where("reorder_threshold > 0 AND stock - reserved < reorder_threshold").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return toBookDomains(models), nil
+}
+
+// UpdateStock changes a book's stock and reservations inside a transaction holding a row lock
+// and appends the change to the ledger.
+func (r *BookRepositoryGORM) UpdateStock(ctx context.Context, id uint, movement domain.StockMovement, apply func(book *domain.Book) error) (domain.Book, error) {
+	var book domain.Book
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model BookModel
+		if err := tx.Clauses(r.lock).First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return domain.ErrBookNotFound
+			}
+			return err
+		}
+
+		book = toBookDomain(model)
+		if err := apply(&book); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&BookModel{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"stock":    book.Stock,
+				"reserved": book.Reserved,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		movement.BookID = id
+		movement.Delta = book.Stock - model.Stock
+		movement.Balance = book.Stock
+		if err := recordMovement(tx, movement); err != nil {
+			return err
+		}
+
+		book.Version++
+		return nil
+	})
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	return book, nil
+}
+
+// Update updates a book in database if its version has not changed.
+func (r *BookRepositoryGORM) Update(ctx context.Context, book domain.Book) (domain.Book, error) {
+	model := toBookModel(book)
+
+	result := r.db.WithContext(ctx).
+		Model(&BookModel{}).
+		Where("id = ? AND version = ?", model.ID, model.Version).
+		Updates(map[string]interface{}{
+			"title":             model.Title,
+			"category":          model.Category,
+			"weight":            model.Weight,
+			"price":             model.Price,
+			"reorder_threshold": model.ReorderThreshold,
+			"reorder_quantity":  model.ReorderQuantity,
+			"allow_backorder":   model.AllowBackorder,
+			"allow_preorder":    model.AllowPreorder,
+			"release_date":      model.ReleaseDate,
+			"version":           gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return domain.Book{}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the book is gone or someone else updated it first
+		if _, err := r.FindByID(ctx, model.ID); err != nil {
+			return domain.Book{}, err
+		}
+		return domain.Book{}, domain.ErrBookConflict
+	}
+
+	return r.FindByID(ctx, model.ID)
+}
+
+// ReconcileStock resets a book's stock to its ledger under a row lock.
+func (r *BookRepositoryGORM) ReconcileStock(ctx context.Context, id uint, movement domain.StockMovement) (domain.Book, int, error) {
+	var (
+		book   domain.Book
+		ledger int
+	)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model BookModel
+		if err := tx.Unscoped().Clauses(r.lock).First(&model, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return domain.ErrBookNotFound
+			}
+			return err
+		}
+		book = toBookDomain(model)
+
+		if err := tx.Model(&StockMovementModel{}).
+			Where("book_id = ?", id).
+			Select("COALESCE(SUM(delta), 0)").
+			Scan(&ledger).Error; err != nil {
+			return err
+		}
+		if book.Stock == ledger {
+			return nil
+		}
+
+		if err := tx.Unscoped().
+			Model(&BookModel{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"stock":   ledger,
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+
+		movement.BookID = id
+		movement.Balance = ledger
+		movement.Reference = fmt.Sprintf("stock was %d", book.Stock)
+		movement.CreatedAt = time.Now()
+		recorded := toStockMovementModel(movement)
+		return tx.Create(&recorded).Error
+	})
+	if err != nil {
+		return domain.Book{}, 0, err
+	}
+
+	return book, ledger, nil
+}
+
+// Delete soft deletes a book from database.
+func (r *BookRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&BookModel{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindDeleted returns all soft deleted books.
+func (r *BookRepositoryGORM) FindDeleted(ctx context.Context) ([]domain.Book, error) {
+	var models []BookModel
+
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return toBookDomains(models), nil
+}
+
+// Restore restores a soft deleted book.
+func (r *BookRepositoryGORM) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&BookModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrBookNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes books soft deleted before the given time.
+// Books still referenced by orders are kept so order history stays intact.
+// Their ledger entries are removed by the cascading foreign key.
+func (r *BookRepositoryGORM) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.book_id = books.id)").
+		Delete(&BookModel{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// toBookModel converts domain.Book to BookModel.
+func toBookModel(book domain.Book) BookModel {
+	return BookModel{
+		ID:               book.ID,
+		Title:            book.Title,
+		Category:         book.Category,
+		Weight:           book.Weight,
+		Price:            book.Price,
+		Stock:            book.Stock,
+		Reserved:         book.Reserved,
+		ReorderThreshold: book.ReorderThreshold,
+		ReorderQuantity:  book.ReorderQuantity,
+		AllowBackorder:   book.AllowBackorder,
+		AllowPreorder:    book.AllowPreorder,
+		ReleaseDate:      book.ReleaseDate,
+		Version:          book.Version,
+		DeletedAt:        toGormDeletedAt(book.DeletedAt),
+	}
+}
+
+// toBookDomain converts BookModel to domain.Book.
+func toBookDomain(model BookModel) domain.Book {
+	return domain.Book{
+		ID:               model.ID,
+		Title:            model.Title,
+		Category:         model.Category,
+		Weight:           model.Weight,
+		Price:            model.Price,
+		Stock:            model.Stock,
+		Reserved:         model.Reserved,
+		ReorderThreshold: model.ReorderThreshold,
+		ReorderQuantity:  model.ReorderQuantity,
+		AllowBackorder:   model.AllowBackorder,
+		AllowPreorder:    model.AllowPreorder,
+		ReleaseDate:      model.ReleaseDate,
+		Version:          model.Version,
+		DeletedAt:        fromGormDeletedAt(model.DeletedAt),
+	}
+}
+
+// toBookDomains converts BookModel values to domain.Book values.
+func toBookDomains(models []BookModel) []domain.Book {
+	books := make([]domain.Book, len(models))
+	for i, model := range models {
+		books[i] = toBookDomain(model)
+	}
+	return books
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BookRepositoryPostgres implements domain.BookRepository using GORM on PostgreSQL.
+// It shares the portable queries of BookRepositoryGORM and overrides
+// stock locking and search with PostgreSQL specific versions.
+type BookRepositoryPostgres struct {
+	*BookRepositoryGORM
+}
+
+// NewBookRepositoryPostgres creates a new BookRepositoryPostgres.
+func NewBookRepositoryPostgres(db *gorm.DB) *BookRepositoryPostgres {
+	repo := NewBookRepositoryGORM(db)
+	// FOR NO KEY UPDATE still serializes stock changes, but unlike FOR UPDATE
+	// it does not block inserting orders that reference the locked book.
+	repo.lock = clause.Locking{Strength: "NO KEY UPDATE"}
+
+	return &BookRepositoryPostgres{BookRepositoryGORM: repo}
+}
+
+// Search returns books matching the query using full-text search, best match first.
+// It is backed by the idx_books_title_search GIN index created in migrations.
+func (r *BookRepositoryPostgres) Search(ctx context.Context, query string, page domain.Page) ([]domain.Book, int64, error) {
+	var models []BookModel
+
+	matching := r.db.WithContext(ctx).
+		Model(&BookModel{}).
+		Where("to_tsvector('simple', title) @@ plainto_tsquery('simple', ?)", query)
+	rank := clause.OrderBy{Expression: clause.Expr{
+		SQL:                "ts_rank(to_tsvector('simple', title), plainto_tsquery('simple', ?)) DESC, id",
+		Vars:               []interface{}{query},
+		WithoutParentheses: true,
+	}}
+	total, err := findPage(matching, page, rank, &models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toBookDomains(models), total, nil
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKeyModel is the database model for IdempotencyKey.
+type IdempotencyKeyModel struct {
+	ID           uint   `gorm:"primaryKey"`
+	Key          string `gorm:"size:255;not null;uniqueIndex"`
+	Fingerprint  string `gorm:"size:64;not null"`
+	StatusCode   int    `gorm:"not null;default:0"`
+	ResponseBody []byte
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for IdempotencyKeyModel.
+func (IdempotencyKeyModel) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyKeyRepositoryGORM implements domain.IdempotencyKeyRepository using GORM (MySQL, SQLite or PostgreSQL).
+type IdempotencyKeyRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyKeyRepositoryGORM creates a new IdempotencyKeyRepositoryGORM.
+func NewIdempotencyKeyRepositoryGORM(db *gorm.DB) *IdempotencyKeyRepositoryGORM {
+	return &IdempotencyKeyRepositoryGORM{db: db}
+}
+
+// Create saves a new idempotency key. The unique index on the key decides
+// which of two concurrent requests claims it.
+func (r *IdempotencyKeyRepositoryGORM) Create(ctx context.Context, key domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	model := toIdempotencyKeyModel(key)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.IdempotencyKey{}, domain.ErrIdempotencyKeyExists
+		}
+		return domain.IdempotencyKey{}, err
+	}
+
+	return toIdempotencyKeyDomain(model), nil
+}
+
+// FindByKey finds an idempotency key.
+func (r *IdempotencyKeyRepositoryGORM) FindByKey(ctx context.Context, key string) (domain.IdempotencyKey, error) {
+	var model IdempotencyKeyModel
+
+	// key is a reserved word in MySQL, so let GORM quote the column
+	if err := r.db.WithContext(ctx).Where(&IdempotencyKeyModel{Key: key}).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.IdempotencyKey{}, domain.ErrIdempotencyKeyNotFound
+		}
+		return domain.IdempotencyKey{}, err
+	}
+
+	return toIdempotencyKeyDomain(model), nil
+}
+
+// Complete stores the response of the request that claimed the key.
+func (r *IdempotencyKeyRepositoryGORM) Complete(ctx context.Context, id uint, statusCode int, body []byte) error {
+	result := r.db.WithContext(ctx).
+		Model(&IdempotencyKeyModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": body,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+// Delete deletes an idempotency key.
+func (r *IdempotencyKeyRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&IdempotencyKeyModel{}, id).Error
+}
+
+// DeleteExpired deletes idempotency keys that expired at or before now.
+func (r *IdempotencyKeyRepositoryGORM) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&IdempotencyKeyModel{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// toIdempotencyKeyModel converts domain.IdempotencyKey to IdempotencyKeyModel.
+func toIdempotencyKeyModel(key domain.IdempotencyKey) IdempotencyKeyModel {
+	return IdempotencyKeyModel{
+		ID:           key.ID,
+		Key:          key.Key,
+		Fingerprint:  key.Fingerprint,
+		StatusCode:   key.StatusCode,
+		ResponseBody: key.ResponseBody,
+		ExpiresAt:    key.ExpiresAt,
+		CreatedAt:    key.CreatedAt,
+	}
+}
+
+// toIdempotencyKeyDomain converts IdempotencyKeyModel to domain.IdempotencyKey.
+func toIdempotencyKeyDomain(model IdempotencyKeyModel) domain.IdempotencyKey {
+	return domain.IdempotencyKey{
+		ID:           model.ID,
+		Key:          model.Key,
+		Fingerprint:  model.Fingerprint,
+		StatusCode:   model.StatusCode,
+		ResponseBody: model.ResponseBody,
+		ExpiresAt:    model.ExpiresAt,
+		CreatedAt:    model.CreatedAt,
+	}
+}
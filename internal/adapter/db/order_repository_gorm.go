@@ -0,0 +1,372 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// OrderModel is the database model for Order.
+type OrderModel struct {
+	ID              uint           `gorm:"primaryKey"`
+	UserID          uint           `gorm:"not null;index"`
+	BookID          uint           `gorm:"not null;index"`
+	Quantity        int            `gorm:"not null"`
+	UnitPrice       float64        `gorm:"not null;default:0"`
+	Subtotal        float64        `gorm:"not null;default:0"`
+	Tax             float64        `gorm:"not null;default:0"`
+	ShippingMethod  string         `gorm:"size:50"`
+	ShippingCost    float64        `gorm:"not null;default:0"`
+	Total           float64        `gorm:"not null"`
+	Discount        float64        `gorm:"not null;default:0"`
+	PromotionCode   string         `gorm:"size:50"`
+	RefundedAmount  float64        `gorm:"not null;default:0"`
+	ShippingAddress AddressColumns `gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  AddressColumns `gorm:"embedded;embeddedPrefix:billing_"`
+	Status          string         `gorm:"size:50;not null"`
+	CreatedAt       time.Time      `gorm:"not null"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+
+	// Relations are only declared so migrations can create foreign keys.
+	User *UserModel `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Book *BookModel `gorm:"foreignKey:BookID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// TableName returns the table name for OrderModel.
+func (OrderModel) TableName() string {
+	return "orders"
+}
+
+// OrderRepositoryGORM implements domain.OrderRepository using GORM (MySQL, SQLite or PostgreSQL).
+type OrderRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewOrderRepositoryGORM creates a new OrderRepositoryGORM.
+func NewOrderRepositoryGORM(db *gorm.DB) *OrderRepositoryGORM {
+	return &OrderRepositoryGORM{db: db}
+}
+
+// Save saves an order to database.
+func (r *OrderRepositoryGORM) Save(ctx context.Context, order domain.Order) (domain.Order, error) {
+	model := toOrderModel(order)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.Order{}, err
+	}
+
+	return toOrderDomain(model), nil
+}
+
+// FindByID finds an order by ID.
+func (r *OrderRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.Order, error) {
+	var model OrderModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Order{}, domain.ErrOrderNotFound
+		}
+		return domain.Order{}, err
+	}
+
+	return toOrderDomain(model), nil
+}
+
+// FindByUserID finds all orders by user ID.
+func (r *OrderRepositoryGORM) FindByUserID(ctx context.Context, userID uint) ([]domain.Order, error) {
+	var models []OrderModel
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.Order, len(models))
+	for i, model := range models {
+		orders[i] = toOrderDomain(model)
+	}
+
+	return orders, nil
+}
+
+// FindAll returns a page of orders, oldest first.
+func (r *OrderRepositoryGORM) FindAll(ctx context.Context, page domain.Page) ([]domain.Order, int64, error) {
+	var models []OrderModel
+
+	total, err := findPage(r.db.WithContext(ctx).Model(&OrderModel{}), page, "id", &models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orders := make([]domain.Order, len(models))
+	for i, model := range models {
+		orders[i] = toOrderDomain(model)
+	}
+
+	return orders, total, nil
+}
+
+// Update updates an order in database.
+func (r *OrderRepositoryGORM) Update(ctx context.Context, order domain.Order) (domain.Order, error) {
+	model := toOrderModel(order)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.Order{}, err
+	}
+
+	return toOrderDomain(model), nil
+}
+
+// UpdateStatus moves an order from one status to another in a single
+// conditional update.
+func (r *OrderRepositoryGORM) UpdateStatus(ctx context.Context, id uint, from, to string) error {
+	result := r.db.WithContext(ctx).
+		Model(&OrderModel{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the order is gone or its status moved on
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrOrderStatusChanged
+	}
+
+	return nil
+}
+
+// UpdateRefund records a refund on an order in a single conditional update
+// on the refunded amount and status it was read with.
+func (r *OrderRepositoryGORM) UpdateRefund(ctx context.Context, before, after domain.Order) error {
+	result := r.db.WithContext(ctx).
+		Model(&OrderModel{}).
+		Where("id = ? AND status = ? AND refunded_amount = ?", before.ID, before.Status, before.RefundedAmount).
+		Updates(map[string]any{"refunded_amount": after.RefundedAmount, "status": after.Status})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the order is gone or another refund got there first
+		if _, err := r.FindByID(ctx, before.ID); err != nil {
+			return err
+		}
+		return domain.ErrOrderStatusChanged
+	}
+
+	return nil
+}
+
+// FindBackordered returns a book's backordered orders, oldest first.
+func (r *OrderRepositoryGORM) FindBackordered(ctx context.Context, bookID uint) ([]domain.Order, error) {
+	var models []OrderModel
+
+	if err := r.db.WithContext(ctx).
+		Where("book_id = ? AND status = ?", bookID, domain.OrderStatusBackordered).
+		Order("id").
+		Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.Order, len(models))
+	for i, model := range models {
+		orders[i] = toOrderDomain(model)
+	}
+
+	return orders, nil
+}
+
+// Delete soft deletes an order from database.
+func (r *OrderRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&OrderModel{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindDeleted returns all soft deleted orders.
+func (r *OrderRepositoryGORM) FindDeleted(ctx context.Context) ([]domain.Order, error) {
+	var models []OrderModel
+
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.Order, len(models))
+	for i, model := range models {
+		orders[i] = toOrderDomain(model)
+	}
+
+	return orders, nil
+}
+
+// Restore restores a soft deleted order.
+func (r *OrderRepositoryGORM) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&OrderModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrOrderNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes orders soft deleted before the given time.
+// Their reservations, payments and return requests are removed by the cascading foreign keys.
+func (r *OrderRepositoryGORM) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&OrderModel{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// unfinishedOrderStatuses are the statuses of orders that still wait for
+// stock, payment, shipping or completion.
+var unfinishedOrderStatuses = []string{
+	domain.OrderStatusPending, domain.OrderStatusBackordered, domain.OrderStatusPaid,
+	domain.OrderStatusPacked, domain.OrderStatusShipped, domain.OrderStatusDelivered,
+}
+
+// CountOpenByBookID counts unfinished orders for a book.
+func (r *OrderRepositoryGORM) CountOpenByBookID(ctx context.Context, bookID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).
+		Model(&OrderModel{}).
+		Where("book_id = ? AND status IN ?", bookID, unfinishedOrderStatuses).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountOpenByUserID counts unfinished orders of a user.
+func (r *OrderRepositoryGORM) CountOpenByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).
+		Model(&OrderModel{}).
+		Where("user_id = ? AND status IN ?", userID, unfinishedOrderStatuses).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// orphanedOrderRow is the scan target for FindOrphaned.
+type orphanedOrderRow struct {
+	OrderModel
+	MissingUser bool
+	MissingBook bool
+}
+
+// FindOrphaned returns orders, including soft deleted ones, whose user or book row no longer exists.
+func (r *OrderRepositoryGORM) FindOrphaned(ctx context.Context) ([]domain.OrphanedOrder, error) {
+	var rows []orphanedOrderRow
+
+	if err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&OrderModel{}).
+		Select("orders.*, users.id IS NULL AS missing_user, books.id IS NULL AS missing_book").
+		Joins("LEFT JOIN users ON users.id = orders.user_id").
+		Joins("LEFT JOIN books ON books.id = orders.book_id").
+		Where("users.id IS NULL OR books.id IS NULL").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	orphans := make([]domain.OrphanedOrder, len(rows))
+	for i, row := range rows {
+		orphans[i] = domain.OrphanedOrder{
+			Order:       toOrderDomain(row.OrderModel),
+			MissingUser: row.MissingUser,
+			MissingBook: row.MissingBook,
+		}
+	}
+
+	return orphans, nil
+}
+
+// toOrderModel converts domain.Order to OrderModel.
+func toOrderModel(order domain.Order) OrderModel {
+	return OrderModel{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		BookID:          order.BookID,
+		Quantity:        order.Quantity,
+		UnitPrice:       order.UnitPrice,
+		Subtotal:        order.Subtotal,
+		Tax:             order.Tax,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingCost:    order.ShippingCost,
+		Total:           order.Total,
+		Discount:        order.Discount,
+		PromotionCode:   order.PromotionCode,
+		RefundedAmount:  order.RefundedAmount,
+		ShippingAddress: toOrderAddressColumns(order.ShippingAddress),
+		BillingAddress:  toOrderAddressColumns(order.BillingAddress),
+		Status:          order.Status,
+		CreatedAt:       order.CreatedAt,
+		DeletedAt:       toGormDeletedAt(order.DeletedAt),
+	}
+}
+
+// toOrderDomain converts OrderModel to domain.Order.
+func toOrderDomain(model OrderModel) domain.Order {
+	return domain.Order{
+		ID:              model.ID,
+		UserID:          model.UserID,
+		BookID:          model.BookID,
+		Quantity:        model.Quantity,
+		UnitPrice:       model.UnitPrice,
+		Subtotal:        model.Subtotal,
+		Tax:             model.Tax,
+		ShippingMethod:  model.ShippingMethod,
+		ShippingCost:    model.ShippingCost,
+		Total:           model.Total,
+		Discount:        model.Discount,
+		PromotionCode:   model.PromotionCode,
+		RefundedAmount:  model.RefundedAmount,
+		ShippingAddress: toOrderAddress(model.ShippingAddress),
+		BillingAddress:  toOrderAddress(model.BillingAddress),
+		Status:          model.Status,
+		CreatedAt:       model.CreatedAt,
+		DeletedAt:       fromGormDeletedAt(model.DeletedAt),
+	}
+}
+
+// toOrderAddressColumns converts an order's address to AddressColumns,
+// leaving them empty for orders without one.
+func toOrderAddressColumns(snapshot *domain.AddressSnapshot) AddressColumns {
+	if snapshot == nil {
+		return AddressColumns{}
+	}
+	return toAddressColumns(*snapshot)
+}
+
+// toOrderAddress converts AddressColumns to an order's address. Every
+// address has a country, so empty columns mean the order has none.
+func toOrderAddress(columns AddressColumns) *domain.AddressSnapshot {
+	if columns.Country == "" {
+		return nil
+	}
+	snapshot := toAddressSnapshot(columns)
+	return &snapshot
+}
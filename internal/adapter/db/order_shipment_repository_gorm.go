@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// OrderShipmentModel is the database model for OrderShipment.
+type OrderShipmentModel struct {
+	ID             uint        `gorm:"primaryKey"`
+	OrderID        uint        `gorm:"not null;uniqueIndex"`
+	Order          *OrderModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Carrier        string      `gorm:"size:100"`
+	TrackingNumber string      `gorm:"size:100"`
+	PackedAt       time.Time   `gorm:"not null"`
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+}
+
+// TableName returns the table name for OrderShipmentModel.
+func (OrderShipmentModel) TableName() string {
+	return "order_shipments"
+}
+
+// OrderShipmentRepositoryGORM implements domain.OrderShipmentRepository using GORM (MySQL, SQLite or PostgreSQL).
+type OrderShipmentRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewOrderShipmentRepositoryGORM creates a new OrderShipmentRepositoryGORM.
+func NewOrderShipmentRepositoryGORM(db *gorm.DB) *OrderShipmentRepositoryGORM {
+	return &OrderShipmentRepositoryGORM{db: db}
+}
+
+// Save saves an order shipment to database.
+func (r *OrderShipmentRepositoryGORM) Save(ctx context.Context, shipment domain.OrderShipment) (domain.OrderShipment, error) {
+	model := toOrderShipmentModel(shipment)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.OrderShipment{}, err
+	}
+
+	return toOrderShipmentDomain(model), nil
+}
+
+// Update updates an order shipment in database.
+func (r *OrderShipmentRepositoryGORM) Update(ctx context.Context, shipment domain.OrderShipment) (domain.OrderShipment, error) {
+	model := toOrderShipmentModel(shipment)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.OrderShipment{}, err
+	}
+
+	return toOrderShipmentDomain(model), nil
+}
+
+// FindByOrderID finds the shipment of an order.
+func (r *OrderShipmentRepositoryGORM) FindByOrderID(ctx context.Context, orderID uint) (domain.OrderShipment, error) {
+	var model OrderShipmentModel
+
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.OrderShipment{}, domain.ErrOrderShipmentNotFound
+		}
+		return domain.OrderShipment{}, err
+	}
+
+	return toOrderShipmentDomain(model), nil
+}
+
+// toOrderShipmentModel converts domain.OrderShipment to OrderShipmentModel.
+func toOrderShipmentModel(shipment domain.OrderShipment) OrderShipmentModel {
+	return OrderShipmentModel{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		PackedAt:       shipment.PackedAt,
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+	}
+}
+
+// toOrderShipmentDomain converts OrderShipmentModel to domain.OrderShipment.
+func toOrderShipmentDomain(model OrderShipmentModel) domain.OrderShipment {
+	return domain.OrderShipment{
+		ID:             model.ID,
+		OrderID:        model.OrderID,
+		Carrier:        model.Carrier,
+		TrackingNumber: model.TrackingNumber,
+		PackedAt:       model.PackedAt,
+		ShippedAt:      model.ShippedAt,
+		DeliveredAt:    model.DeliveredAt,
+	}
+}
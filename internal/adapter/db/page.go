@@ -0,0 +1,29 @@
+package db
+
+import (
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// findPage counts the rows query selects and loads the page of them into
+// models, sorted by order. The query must name its model so it can be
+// counted without loading the rows.
+func findPage(query *gorm.DB, page domain.Page, order interface{}, models interface{}) (int64, error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	query = query.Order(order)
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit).Offset(page.Offset)
+	}
+	if err := query.Find(models).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
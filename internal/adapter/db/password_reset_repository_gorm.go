@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetModel is the database model for PasswordReset.
+type PasswordResetModel struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for PasswordResetModel.
+func (PasswordResetModel) TableName() string {
+	return "password_resets"
+}
+
+// PasswordResetRepositoryGORM implements domain.PasswordResetRepository using GORM (MySQL, SQLite or PostgreSQL).
+type PasswordResetRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepositoryGORM creates a new PasswordResetRepositoryGORM.
+func NewPasswordResetRepositoryGORM(db *gorm.DB) *PasswordResetRepositoryGORM {
+	return &PasswordResetRepositoryGORM{db: db}
+}
+
+// Save saves a password reset token to database.
+func (r *PasswordResetRepositoryGORM) Save(ctx context.Context, reset domain.PasswordReset) (domain.PasswordReset, error) {
+	model := toPasswordResetModel(reset)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.PasswordReset{}, err
+	}
+
+	return toPasswordResetDomain(model), nil
+}
+
+// FindByTokenHash finds a password reset token by its hash.
+func (r *PasswordResetRepositoryGORM) FindByTokenHash(ctx context.Context, tokenHash string) (domain.PasswordReset, error) {
+	var model PasswordResetModel
+
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.PasswordReset{}, domain.ErrInvalidResetToken
+		}
+		return domain.PasswordReset{}, err
+	}
+
+	return toPasswordResetDomain(model), nil
+}
+
+// MarkUsed marks a token as used only if it has not been used yet.
+func (r *PasswordResetRepositoryGORM) MarkUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&PasswordResetModel{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidResetToken
+	}
+	return nil
+}
+
+// DeleteByUserID deletes all password reset tokens of a user.
+func (r *PasswordResetRepositoryGORM) DeleteByUserID(ctx context.Context, userID uint) error {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&PasswordResetModel{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// toPasswordResetModel converts domain.PasswordReset to PasswordResetModel.
+func toPasswordResetModel(reset domain.PasswordReset) PasswordResetModel {
+	return PasswordResetModel{
+		ID:        reset.ID,
+		UserID:    reset.UserID,
+		TokenHash: reset.TokenHash,
+		ExpiresAt: reset.ExpiresAt,
+		UsedAt:    reset.UsedAt,
+		CreatedAt: reset.CreatedAt,
+	}
+}
+
+// toPasswordResetDomain converts PasswordResetModel to domain.PasswordReset.
+func toPasswordResetDomain(model PasswordResetModel) domain.PasswordReset {
+	return domain.PasswordReset{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		TokenHash: model.TokenHash,
+		ExpiresAt: model.ExpiresAt,
+		UsedAt:    model.UsedAt,
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// PaymentModel is the database model for Payment.
+type PaymentModel struct {
+	ID             uint        `gorm:"primaryKey"`
+	OrderID        uint        `gorm:"not null;index"`
+	Order          *OrderModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Amount         float64     `gorm:"not null"`
+	Status         string      `gorm:"size:20;not null"`
+	Reference      string      `gorm:"size:255"`
+	FailureReason  string      `gorm:"size:255"`
+	RefundedAmount float64     `gorm:"not null;default:0"`
+	// HeldOrderID is the order while the payment holds it and NULL
+	// otherwise, so that an order is held by one payment at a time.
+	HeldOrderID *uint     `gorm:"uniqueIndex"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for PaymentModel.
+func (PaymentModel) TableName() string {
+	return "payments"
+}
+
+// PaymentRepositoryGORM implements domain.PaymentRepository using GORM (MySQL, SQLite or PostgreSQL).
+type PaymentRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepositoryGORM creates a new PaymentRepositoryGORM.
+func NewPaymentRepositoryGORM(db *gorm.DB) *PaymentRepositoryGORM {
+	return &PaymentRepositoryGORM{db: db}
+}
+
+// Save saves a payment to database.
+func (r *PaymentRepositoryGORM) Save(ctx context.Context, payment domain.Payment) (domain.Payment, error) {
+	model := toPaymentModel(payment)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.Payment{}, domain.ErrPaymentExists
+		}
+		return domain.Payment{}, err
+	}
+
+	return toPaymentDomain(model), nil
+}
+
+// Update updates a payment in database.
+func (r *PaymentRepositoryGORM) Update(ctx context.Context, payment domain.Payment) (domain.Payment, error) {
+	model := toPaymentModel(payment)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.Payment{}, domain.ErrPaymentExists
+		}
+		return domain.Payment{}, err
+	}
+
+	return toPaymentDomain(model), nil
+}
+
+// UpdateRefund records a refund on a payment in a single conditional update
+// on the refunded amount and status it was read with.
+func (r *PaymentRepositoryGORM) UpdateRefund(ctx context.Context, before, after domain.Payment) error {
+	result := r.db.WithContext(ctx).
+		Model(&PaymentModel{}).
+		Where("id = ? AND status = ? AND refunded_amount = ?", before.ID, before.Status, before.RefundedAmount).
+		Updates(map[string]any{
+			"refunded_amount": after.RefundedAmount,
+			"status":          after.Status,
+			"held_order_id":   toPaymentModel(after).HeldOrderID,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the payment is gone or another refund got there first
+		if _, err := r.FindByID(ctx, before.ID); err != nil {
+			return err
+		}
+		return domain.ErrPaymentChanged
+	}
+
+	return nil
+}
+
+// FindByID finds a payment by ID.
+func (r *PaymentRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.Payment, error) {
+	var model PaymentModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Payment{}, domain.ErrPaymentNotFound
+		}
+		return domain.Payment{}, err
+	}
+
+	return toPaymentDomain(model), nil
+}
+
+// FindByOrderID returns an order's payments, oldest first.
+func (r *PaymentRepositoryGORM) FindByOrderID(ctx context.Context, orderID uint) ([]domain.Payment, error) {
+	var models []PaymentModel
+
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	payments := make([]domain.Payment, len(models))
+	for i, model := range models {
+		payments[i] = toPaymentDomain(model)
+	}
+
+	return payments, nil
+}
+
+// toPaymentModel converts domain.Payment to PaymentModel.
+func toPaymentModel(payment domain.Payment) PaymentModel {
+	var heldOrderID *uint
+	if payment.HoldsOrder() {
+		heldOrderID = &payment.OrderID
+	}
+
+	return PaymentModel{
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		Amount:         payment.Amount,
+		Status:         payment.Status,
+		Reference:      payment.Reference,
+		FailureReason:  payment.FailureReason,
+		RefundedAmount: payment.RefundedAmount,
+		HeldOrderID:    heldOrderID,
+		CreatedAt:      payment.CreatedAt,
+		UpdatedAt:      payment.UpdatedAt,
+	}
+}
+
+// toPaymentDomain converts PaymentModel to domain.Payment.
+func toPaymentDomain(model PaymentModel) domain.Payment {
+	return domain.Payment{
+		ID:             model.ID,
+		OrderID:        model.OrderID,
+		Amount:         model.Amount,
+		Status:         model.Status,
+		Reference:      model.Reference,
+		FailureReason:  model.FailureReason,
+		RefundedAmount: model.RefundedAmount,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+}
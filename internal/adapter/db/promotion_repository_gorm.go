@@ -0,0 +1,258 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromotionModel is the database model for Promotion.
+type PromotionModel struct {
+	ID           uint    `gorm:"primaryKey"`
+	Code         string  `gorm:"size:50;not null;uniqueIndex"`
+	Description  string  `gorm:"size:255"`
+	Type         string  `gorm:"size:20;not null"`
+	Value        float64 `gorm:"not null"`
+	MinSpend     float64 `gorm:"not null;default:0"`
+	UsageLimit   int     `gorm:"not null;default:0"`
+	PerUserLimit int     `gorm:"not null;default:0"`
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	BookID       *uint      `gorm:"index"`
+	Book         *BookModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Category     string     `gorm:"size:100"`
+	CreatedAt    time.Time  `gorm:"not null"`
+	UpdatedAt    time.Time  `gorm:"not null"`
+}
+
+// TableName returns the table name for PromotionModel.
+func (PromotionModel) TableName() string {
+	return "promotions"
+}
+
+// PromotionRedemptionModel is the database model for PromotionRedemption.
+type PromotionRedemptionModel struct {
+	ID          uint            `gorm:"primaryKey"`
+	PromotionID uint            `gorm:"not null;index"`
+	Promotion   *PromotionModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	UserID      uint            `gorm:"not null;index"`
+	// An order redeems at most one promotion.
+	OrderID   uint        `gorm:"not null;uniqueIndex"`
+	Order     *OrderModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CreatedAt time.Time   `gorm:"not null"`
+}
+
+// TableName returns the table name for PromotionRedemptionModel.
+func (PromotionRedemptionModel) TableName() string {
+	return "promotion_redemptions"
+}
+
+// promotionRow is the scan target for promotion queries.
+type promotionRow struct {
+	PromotionModel
+	UsedCount int
+}
+
+// PromotionRepositoryGORM implements domain.PromotionRepository using GORM (MySQL, SQLite or PostgreSQL).
+type PromotionRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepositoryGORM creates a new PromotionRepositoryGORM.
+func NewPromotionRepositoryGORM(db *gorm.DB) *PromotionRepositoryGORM {
+	return &PromotionRepositoryGORM{db: db}
+}
+
+// Save saves a promotion to database.
+func (r *PromotionRepositoryGORM) Save(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	model := toPromotionModel(promotion)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.Promotion{}, domain.ErrPromotionCodeExists
+		}
+		return domain.Promotion{}, err
+	}
+
+	return toPromotionDomain(model, 0), nil
+}
+
+// Update updates a promotion in database.
+func (r *PromotionRepositoryGORM) Update(ctx context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	model := toPromotionModel(promotion)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.Promotion{}, domain.ErrPromotionCodeExists
+		}
+		return domain.Promotion{}, err
+	}
+
+	return toPromotionDomain(model, promotion.UsedCount), nil
+}
+
+// FindByID finds a promotion by ID.
+func (r *PromotionRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.Promotion, error) {
+	return r.take(r.withUsage(ctx).Where("promotions.id = ?", id))
+}
+
+// FindByCode finds a promotion by its normalized code.
+func (r *PromotionRepositoryGORM) FindByCode(ctx context.Context, code string) (domain.Promotion, error) {
+	return r.take(r.withUsage(ctx).Where("promotions.code = ?", code))
+}
+
+// FindAll returns all promotions, oldest first.
+func (r *PromotionRepositoryGORM) FindAll(ctx context.Context) ([]domain.Promotion, error) {
+	var rows []promotionRow
+
+	if err := r.withUsage(ctx).Order("promotions.id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	promotions := make([]domain.Promotion, len(rows))
+	for i, row := range rows {
+		promotions[i] = toPromotionDomain(row.PromotionModel, row.UsedCount)
+	}
+
+	return promotions, nil
+}
+
+// Delete deletes a promotion; its redemptions go with it.
+func (r *PromotionRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&PromotionModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrPromotionNotFound
+	}
+
+	return nil
+}
+
+// Redeem records a redemption inside a transaction holding a row lock on
+// the promotion, so concurrent orders are counted one at a time.
+func (r *PromotionRepositoryGORM) Redeem(ctx context.Context, redemption domain.PromotionRedemption) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var model PromotionModel
+		if err := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).First(&model, redemption.PromotionID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return domain.ErrPromotionNotFound
+			}
+			return err
+		}
+
+		if model.UsageLimit > 0 {
+			var used int64
+			if err := tx.Model(&PromotionRedemptionModel{}).
+				Where("promotion_id = ?", model.ID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if used >= int64(model.UsageLimit) {
+				return domain.ErrPromotionUsedUp
+			}
+		}
+
+		if model.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&PromotionRedemptionModel{}).
+				Where("promotion_id = ? AND user_id = ?", model.ID, redemption.UserID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if used >= int64(model.PerUserLimit) {
+				return domain.ErrPromotionUserLimit
+			}
+		}
+
+		row := toPromotionRedemptionModel(redemption)
+		return tx.Create(&row).Error
+	})
+}
+
+// Release deletes an order's redemption.
+func (r *PromotionRepositoryGORM) Release(ctx context.Context, orderID uint) error {
+	return r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Delete(&PromotionRedemptionModel{}).Error
+}
+
+// withUsage selects promotions together with their redemption count.
+func (r *PromotionRepositoryGORM) withUsage(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&PromotionModel{}).
+		Select("promotions.*, (SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_redemptions.promotion_id = promotions.id) AS used_count")
+}
+
+// take returns the single promotion matching a query.
+func (r *PromotionRepositoryGORM) take(query *gorm.DB) (domain.Promotion, error) {
+	var row promotionRow
+
+	if err := query.Take(&row).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Promotion{}, domain.ErrPromotionNotFound
+		}
+		return domain.Promotion{}, err
+	}
+
+	return toPromotionDomain(row.PromotionModel, row.UsedCount), nil
+}
+
+// toPromotionModel converts domain.Promotion to PromotionModel.
+func toPromotionModel(promotion domain.Promotion) PromotionModel {
+	return PromotionModel{
+		ID:           promotion.ID,
+		Code:         promotion.Code,
+		Description:  promotion.Description,
+		Type:         promotion.Type,
+		Value:        promotion.Value,
+		MinSpend:     promotion.MinSpend,
+		UsageLimit:   promotion.UsageLimit,
+		PerUserLimit: promotion.PerUserLimit,
+		StartsAt:     promotion.StartsAt,
+		EndsAt:       promotion.EndsAt,
+		BookID:       promotion.BookID,
+		Category:     promotion.Category,
+		CreatedAt:    promotion.CreatedAt,
+		UpdatedAt:    promotion.UpdatedAt,
+	}
+}
+
+// toPromotionDomain converts PromotionModel to domain.Promotion.
+func toPromotionDomain(model PromotionModel, usedCount int) domain.Promotion {
+	return domain.Promotion{
+		ID:           model.ID,
+		Code:         model.Code,
+		Description:  model.Description,
+		Type:         model.Type,
+		Value:        model.Value,
+		MinSpend:     model.MinSpend,
+		UsageLimit:   model.UsageLimit,
+		PerUserLimit: model.PerUserLimit,
+		UsedCount:    usedCount,
+		StartsAt:     model.StartsAt,
+		EndsAt:       model.EndsAt,
+		BookID:       model.BookID,
+		Category:     model.Category,
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+	}
+}
+
+// toPromotionRedemptionModel converts domain.PromotionRedemption to PromotionRedemptionModel.
+func toPromotionRedemptionModel(redemption domain.PromotionRedemption) PromotionRedemptionModel {
+	return PromotionRedemptionModel{
+		ID:          redemption.ID,
+		PromotionID: redemption.PromotionID,
+		UserID:      redemption.UserID,
+		OrderID:     redemption.OrderID,
+		CreatedAt:   redemption.CreatedAt,
+	}
+}
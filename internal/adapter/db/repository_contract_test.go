@@ -0,0 +1,238 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/adapter/db"
+	"kikukafandi/book-shop-api/internal/adapter/repotest"
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestRepositoryContractSQLite runs the repository contract against an in-memory SQLite database.
+func TestRepositoryContractSQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		database := newSQLite(t)
+		return repotest.Repositories{
+			Books:           db.NewBookRepositoryGORM(database),
+			Users:           db.NewUserRepositoryGORM(database),
+			Orders:          db.NewOrderRepositoryGORM(database),
+			StockMovements:  db.NewStockMovementRepositoryGORM(database),
+			Reservations:    db.NewReservationRepositoryGORM(database),
+			IdempotencyKeys: db.NewIdempotencyKeyRepositoryGORM(database),
+			Payments:        db.NewPaymentRepositoryGORM(database),
+			WebhookEvents:   db.NewWebhookEventRepositoryGORM(database),
+			ReturnRequests:  db.NewReturnRequestRepositoryGORM(database),
+			Promotions:      db.NewPromotionRepositoryGORM(database),
+			BookPrices:      db.NewBookPriceRepositoryGORM(database),
+			Addresses:       db.NewAddressRepositoryGORM(database),
+			OrderShipments:  db.NewOrderShipmentRepositoryGORM(database),
+		}
+	})
+}
+
+func TestSQLiteEnforcesForeignKeys(t *testing.T) {
+	database := newSQLite(t)
+	orders := db.NewOrderRepositoryGORM(database)
+
+	_, err := orders.Save(context.Background(), domain.NewOrder(42, 7, 1, 10))
+	if !errors.Is(err, gorm.ErrForeignKeyViolated) {
+		t.Fatalf("expected foreign key violation, got %v", err)
+	}
+}
+
+func TestMigrationKeepsSubtotalOfShippingOnlyOrders(t *testing.T) {
+	ctx := context.Background()
+	database := newSQLite(t)
+	users := db.NewUserRepositoryGORM(database)
+	books := db.NewBookRepositoryGORM(database)
+	orders := db.NewOrderRepositoryGORM(database)
+
+	user, _ := users.Save(ctx, domain.NewUser("Test", "a@example.com", "secret123", "customer"))
+	book, _ := books.Save(ctx, domain.NewBook("A", 10, 5), domain.NewInitialStock(""))
+
+	// An order placed before tax was charged has no subtotal
+	legacy := domain.NewOrder(user.ID, book.ID, 1, 10)
+	legacy.Subtotal = 0
+	legacy, _ = orders.Save(ctx, legacy)
+
+	// A promotion covered the goods of this one; only shipping is left
+	free := domain.NewOrder(user.ID, book.ID, 1, 10)
+	free.Subtotal = 0
+	free.Total = 0
+	free.ApplyShipping("standard", 5)
+	free, _ = orders.Save(ctx, free)
+
+	// Migrations run on every start
+	if err := config.AutoMigrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if found, _ := orders.FindByID(ctx, legacy.ID); found.Subtotal != 10 {
+		t.Fatalf("expected the legacy order's subtotal to be its total, got %+v", found)
+	}
+	if found, _ := orders.FindByID(ctx, free.ID); found.Subtotal != 0 || found.Total != 5 {
+		t.Fatalf("expected the free order's subtotal to stay 0, got %+v", found)
+	}
+}
+
+func TestReconcileStockResetsDriftedStockToTheLedger(t *testing.T) {
+	ctx := context.Background()
+	database := newSQLite(t)
+	books := db.NewBookRepositoryGORM(database)
+	movements := db.NewStockMovementRepositoryGORM(database)
+
+	book, _ := books.Save(ctx, domain.NewBook("A", 10, 5), domain.NewInitialStock(""))
+	_ = books.Delete(ctx, book.ID)
+
+	// A write that bypassed the ledger
+	if err := database.Exec("UPDATE books SET stock = 9 WHERE id = ?", book.ID).Error; err != nil {
+		t.Fatalf("drift: %v", err)
+	}
+
+	movement := domain.NewStockMovement(domain.StockReasonReconcile, "system", "")
+	before, ledger, err := books.ReconcileStock(ctx, book.ID, movement)
+	if err != nil {
+		t.Fatalf("ReconcileStock: %v", err)
+	}
+	if before.Stock != 9 || before.DeletedAt == nil || ledger != 5 {
+		t.Fatalf("unexpected reconcile: %+v, ledger %d", before, ledger)
+	}
+
+	var stock int
+	database.Raw("SELECT stock FROM books WHERE id = ?", book.ID).Scan(&stock)
+	if stock != 5 {
+		t.Fatalf("expected stock reset to 5, got %d", stock)
+	}
+
+	history, _ := movements.FindByBookID(ctx, book.ID)
+	if len(history) != 2 {
+		t.Fatalf("expected a reconcile movement, got %+v", history)
+	}
+	if last := history[1]; last.Reason != domain.StockReasonReconcile || last.Delta != 0 || last.Balance != 5 || last.Reference != "stock was 9" {
+		t.Fatalf("unexpected reconcile movement: %+v", last)
+	}
+}
+
+// newSQLite opens and migrates a fresh in-memory SQLite database.
+func newSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	database, err := config.NewDatabase(config.DatabaseConfig{
+		Driver:   config.DriverSQLite,
+		Path:     ":memory:",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := config.AutoMigrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return database
+}
+
+// TestRepositoryContractMySQL runs the repository contract against MySQL.
+// It is skipped unless TEST_MYSQL_DSN points to a disposable database, e.g.
+// root:secret@tcp(localhost:3306)/bookstore_test?charset=utf8mb4&parseTime=True&loc=Local
+func TestRepositoryContractMySQL(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN not set")
+	}
+
+	database, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+		TranslateError:                           true,
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := config.AutoMigrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		truncate(t, database)
+		return repotest.Repositories{
+			Books:           db.NewBookRepositoryGORM(database),
+			Users:           db.NewUserRepositoryGORM(database),
+			Orders:          db.NewOrderRepositoryGORM(database),
+			StockMovements:  db.NewStockMovementRepositoryGORM(database),
+			Reservations:    db.NewReservationRepositoryGORM(database),
+			IdempotencyKeys: db.NewIdempotencyKeyRepositoryGORM(database),
+			Payments:        db.NewPaymentRepositoryGORM(database),
+			WebhookEvents:   db.NewWebhookEventRepositoryGORM(database),
+			ReturnRequests:  db.NewReturnRequestRepositoryGORM(database),
+			Promotions:      db.NewPromotionRepositoryGORM(database),
+			BookPrices:      db.NewBookPriceRepositoryGORM(database),
+			Addresses:       db.NewAddressRepositoryGORM(database),
+			OrderShipments:  db.NewOrderShipmentRepositoryGORM(database),
+		}
+	})
+}
+
+// TestRepositoryContractPostgres runs the repository contract against PostgreSQL.
+// It is skipped unless TEST_POSTGRES_DSN points to a disposable database, e.g.
+// host=localhost user=postgres password=secret dbname=bookstore_test sslmode=disable
+func TestRepositoryContractPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+		TranslateError:                           true,
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := config.AutoMigrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		truncate(t, database)
+		repos := config.NewRepositories(config.DriverPostgres, database)
+		return repotest.Repositories{
+			Books:           repos.Books,
+			Users:           repos.Users,
+			Orders:          repos.Orders,
+			StockMovements:  repos.StockMovements,
+			Reservations:    repos.Reservations,
+			IdempotencyKeys: repos.IdempotencyKeys,
+			Payments:        repos.Payments,
+			WebhookEvents:   repos.WebhookEvents,
+			ReturnRequests:  repos.ReturnRequests,
+			Promotions:      repos.Promotions,
+			BookPrices:      repos.BookPrices,
+			Addresses:       repos.Addresses,
+			OrderShipments:  repos.OrderShipments,
+		}
+	})
+}
+
+// truncate empties all tables so every subtest starts from a clean store.
+func truncate(t *testing.T, database *gorm.DB) {
+	t.Helper()
+
+	// Children first so foreign keys are never violated
+	tables := []string{"webhook_events", "idempotency_keys", "payments", "return_requests", "promotion_redemptions", "promotions", "stock_reservations", "order_shipments", "orders", "password_resets", "stock_movements", "book_prices", "books", "addresses", "users"}
+	for _, table := range tables {
+		if err := database.Exec("DELETE FROM " + table).Error; err != nil {
+			t.Fatalf("truncate %s: %v", table, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ReservationModel is the database model for Reservation.
+type ReservationModel struct {
+	ID        uint        `gorm:"primaryKey"`
+	OrderID   uint        `gorm:"not null;uniqueIndex"`
+	Order     *OrderModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	BookID    uint        `gorm:"not null;index"`
+	Quantity  int         `gorm:"not null"`
+	ExpiresAt time.Time   `gorm:"not null;index"`
+	CreatedAt time.Time   `gorm:"not null"`
+}
+
+// TableName returns the table name for ReservationModel.
+func (ReservationModel) TableName() string {
+	return "stock_reservations"
+}
+
+// ReservationRepositoryGORM implements domain.ReservationRepository using GORM (MySQL, SQLite or PostgreSQL).
+type ReservationRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewReservationRepositoryGORM creates a new ReservationRepositoryGORM.
+func NewReservationRepositoryGORM(db *gorm.DB) *ReservationRepositoryGORM {
+	return &ReservationRepositoryGORM{db: db}
+}
+
+// Save saves a reservation to database.
+func (r *ReservationRepositoryGORM) Save(ctx context.Context, reservation domain.Reservation) (domain.Reservation, error) {
+	model := toReservationModel(reservation)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.Reservation{}, err
+	}
+
+	return toReservationDomain(model), nil
+}
+
+// FindByOrderID finds the reservation of an order.
+func (r *ReservationRepositoryGORM) FindByOrderID(ctx context.Context, orderID uint) (domain.Reservation, error) {
+	var model ReservationModel
+
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.Reservation{}, domain.ErrReservationNotFound
+		}
+		return domain.Reservation{}, err
+	}
+
+	return toReservationDomain(model), nil
+}
+
+// FindExpired returns reservations that expired at or before now, oldest first.
+func (r *ReservationRepositoryGORM) FindExpired(ctx context.Context, now time.Time) ([]domain.Reservation, error) {
+	var models []ReservationModel
+
+	if err := r.db.WithContext(ctx).Where("expires_at <= ?", now).Order("expires_at, id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	reservations := make([]domain.Reservation, len(models))
+	for i, model := range models {
+		reservations[i] = toReservationDomain(model)
+	}
+
+	return reservations, nil
+}
+
+// Delete deletes a reservation only if it still exists.
+func (r *ReservationRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&ReservationModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReservationNotFound
+	}
+	return nil
+}
+
+// toReservationModel converts domain.Reservation to ReservationModel.
+func toReservationModel(reservation domain.Reservation) ReservationModel {
+	return ReservationModel{
+		ID:        reservation.ID,
+		OrderID:   reservation.OrderID,
+		BookID:    reservation.BookID,
+		Quantity:  reservation.Quantity,
+		ExpiresAt: reservation.ExpiresAt,
+		CreatedAt: reservation.CreatedAt,
+	}
+}
+
+// toReservationDomain converts ReservationModel to domain.Reservation.
+func toReservationDomain(model ReservationModel) domain.Reservation {
+	return domain.Reservation{
+		ID:        model.ID,
+		OrderID:   model.OrderID,
+		BookID:    model.BookID,
+		Quantity:  model.Quantity,
+		ExpiresAt: model.ExpiresAt,
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// ReturnRequestModel is the database model for ReturnRequest.
+type ReturnRequestModel struct {
+	ID              uint        `gorm:"primaryKey"`
+	OrderID         uint        `gorm:"not null;index"`
+	Order           *OrderModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Quantity        int         `gorm:"not null"`
+	Reason          string      `gorm:"size:255"`
+	Status          string      `gorm:"size:20;not null;index"`
+	Condition       string      `gorm:"size:20"`
+	RefundAmount    float64     `gorm:"not null;default:0"`
+	RejectionReason string      `gorm:"size:255"`
+	CreatedAt       time.Time   `gorm:"not null"`
+	UpdatedAt       time.Time   `gorm:"not null"`
+}
+
+// TableName returns the table name for ReturnRequestModel.
+func (ReturnRequestModel) TableName() string {
+	return "return_requests"
+}
+
+// ReturnRequestRepositoryGORM implements domain.ReturnRequestRepository using GORM (MySQL, SQLite or PostgreSQL).
+type ReturnRequestRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewReturnRequestRepositoryGORM creates a new ReturnRequestRepositoryGORM.
+func NewReturnRequestRepositoryGORM(db *gorm.DB) *ReturnRequestRepositoryGORM {
+	return &ReturnRequestRepositoryGORM{db: db}
+}
+
+// Save saves a return request to database.
+func (r *ReturnRequestRepositoryGORM) Save(ctx context.Context, request domain.ReturnRequest) (domain.ReturnRequest, error) {
+	model := toReturnRequestModel(request)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	return toReturnRequestDomain(model), nil
+}
+
+// Update updates a return request in database.
+func (r *ReturnRequestRepositoryGORM) Update(ctx context.Context, request domain.ReturnRequest) (domain.ReturnRequest, error) {
+	model := toReturnRequestModel(request)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	return toReturnRequestDomain(model), nil
+}
+
+// UpdateStatus moves a return request from one status to another in a
+// single conditional update.
+func (r *ReturnRequestRepositoryGORM) UpdateStatus(ctx context.Context, id uint, from, to string) error {
+	result := r.db.WithContext(ctx).
+		Model(&ReturnRequestModel{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{"status": to, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the request is gone or it was resolved in the meantime
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrReturnNotRequested
+	}
+
+	return nil
+}
+
+// FindByID finds a return request by ID.
+func (r *ReturnRequestRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.ReturnRequest, error) {
+	var model ReturnRequestModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.ReturnRequest{}, domain.ErrReturnNotFound
+		}
+		return domain.ReturnRequest{}, err
+	}
+
+	return toReturnRequestDomain(model), nil
+}
+
+// FindByOrderID returns an order's return requests, oldest first.
+func (r *ReturnRequestRepositoryGORM) FindByOrderID(ctx context.Context, orderID uint) ([]domain.ReturnRequest, error) {
+	return r.find(r.db.WithContext(ctx).Where("order_id = ?", orderID))
+}
+
+// FindByStatus returns a page of the return requests in a status, oldest first.
+func (r *ReturnRequestRepositoryGORM) FindByStatus(ctx context.Context, status string, page domain.Page) ([]domain.ReturnRequest, int64, error) {
+	var models []ReturnRequestModel
+
+	query := r.db.WithContext(ctx).Model(&ReturnRequestModel{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	total, err := findPage(query, page, "id", &models)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toReturnRequestDomains(models), total, nil
+}
+
+// find returns the return requests matching a query, oldest first.
+func (r *ReturnRequestRepositoryGORM) find(query *gorm.DB) ([]domain.ReturnRequest, error) {
+	var models []ReturnRequestModel
+
+	if err := query.Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	return toReturnRequestDomains(models), nil
+}
+
+// toReturnRequestModel converts domain.ReturnRequest to ReturnRequestModel.
+func toReturnRequestModel(request domain.ReturnRequest) ReturnRequestModel {
+	return ReturnRequestModel{
+		ID:              request.ID,
+		OrderID:         request.OrderID,
+		Quantity:        request.Quantity,
+		Reason:          request.Reason,
+		Status:          request.Status,
+		Condition:       request.Condition,
+		RefundAmount:    request.RefundAmount,
+		RejectionReason: request.RejectionReason,
+		CreatedAt:       request.CreatedAt,
+		UpdatedAt:       request.UpdatedAt,
+	}
+}
+
+// toReturnRequestDomain converts ReturnRequestModel to domain.ReturnRequest.
+func toReturnRequestDomain(model ReturnRequestModel) domain.ReturnRequest {
+	return domain.ReturnRequest{
+		ID:              model.ID,
+		OrderID:         model.OrderID,
+		Quantity:        model.Quantity,
+		Reason:          model.Reason,
+		Status:          model.Status,
+		Condition:       model.Condition,
+		RefundAmount:    model.RefundAmount,
+		RejectionReason: model.RejectionReason,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+	}
+}
+
+// toReturnRequestDomains converts ReturnRequestModel values to domain.ReturnRequest values.
+func toReturnRequestDomains(models []ReturnRequestModel) []domain.ReturnRequest {
+	requests := make([]domain.ReturnRequest, len(models))
+	for i, model := range models {
+		requests[i] = toReturnRequestDomain(model)
+	}
+	return requests
+}
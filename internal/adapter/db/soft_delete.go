@@ -0,0 +1,24 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// toGormDeletedAt converts a domain deletion time to gorm.DeletedAt.
+func toGormDeletedAt(deletedAt *time.Time) gorm.DeletedAt {
+	if deletedAt == nil {
+		return gorm.DeletedAt{}
+	}
+	return gorm.DeletedAt{Time: *deletedAt, Valid: true}
+}
+
+// fromGormDeletedAt converts gorm.DeletedAt to a domain deletion time.
+func fromGormDeletedAt(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+	t := deletedAt.Time
+	return &t
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// StockMovementModel is the database model for StockMovement.
+// Rows are only ever inserted; the ledger is append-only.
+type StockMovementModel struct {
+	ID        uint       `gorm:"primaryKey"`
+	BookID    uint       `gorm:"not null;index"`
+	Book      *BookModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Delta     int        `gorm:"not null"`
+	Balance   int        `gorm:"not null"`
+	Reason    string     `gorm:"size:20;not null"`
+	Actor     string     `gorm:"size:100"`
+	Reference string     `gorm:"size:100"`
+	CreatedAt time.Time  `gorm:"not null"`
+}
+
+// TableName returns the table name for StockMovementModel.
+func (StockMovementModel) TableName() string {
+	return "stock_movements"
+}
+
+// StockMovementRepositoryGORM implements domain.StockMovementRepository using GORM (MySQL, SQLite or PostgreSQL).
+type StockMovementRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepositoryGORM creates a new StockMovementRepositoryGORM.
+func NewStockMovementRepositoryGORM(db *gorm.DB) *StockMovementRepositoryGORM {
+	return &StockMovementRepositoryGORM{db: db}
+}
+
+// FindByBookID returns a book's movements, oldest first.
+func (r *StockMovementRepositoryGORM) FindByBookID(ctx context.Context, bookID uint) ([]domain.StockMovement, error) {
+	var models []StockMovementModel
+
+	if err := r.db.WithContext(ctx).Where("book_id = ?", bookID).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	movements := make([]domain.StockMovement, len(models))
+	for i, model := range models {
+		movements[i] = toStockMovementDomain(model)
+	}
+
+	return movements, nil
+}
+
+// Balances returns the sum of movement deltas per book.
+func (r *StockMovementRepositoryGORM) Balances(ctx context.Context) (map[uint]int, error) {
+	var rows []struct {
+		BookID  uint
+		Balance int
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&StockMovementModel{}).
+		Select("book_id, SUM(delta) AS balance").
+		Group("book_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	balances := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		balances[row.BookID] = row.Balance
+	}
+
+	return balances, nil
+}
+
+// recordMovement appends a movement to the ledger inside tx.
+// Movements that do not change stock are not recorded.
+func recordMovement(tx *gorm.DB, movement domain.StockMovement) error {
+	if movement.Delta == 0 {
+		return nil
+	}
+
+	movement.CreatedAt = time.Now()
+	model := toStockMovementModel(movement)
+	return tx.Create(&model).Error
+}
+
+// toStockMovementModel converts domain.StockMovement to StockMovementModel.
+func toStockMovementModel(movement domain.StockMovement) StockMovementModel {
+	return StockMovementModel{
+		ID:        movement.ID,
+		BookID:    movement.BookID,
+		Delta:     movement.Delta,
+		Balance:   movement.Balance,
+		Reason:    movement.Reason,
+		Actor:     movement.Actor,
+		Reference: movement.Reference,
+		CreatedAt: movement.CreatedAt,
+	}
+}
+
+// toStockMovementDomain converts StockMovementModel to domain.StockMovement.
+func toStockMovementDomain(model StockMovementModel) domain.StockMovement {
+	return domain.StockMovement{
+		ID:        model.ID,
+		BookID:    model.BookID,
+		Delta:     model.Delta,
+		Balance:   model.Balance,
+		Reason:    model.Reason,
+		Actor:     model.Actor,
+		Reference: model.Reference,
+		CreatedAt: model.CreatedAt,
+	}
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// UserModel is the database model for User.
+type UserModel struct {
+	ID                 uint   `gorm:"primaryKey"`
+	Name               string `gorm:"size:255;not null"`
+	Email              string `gorm:"size:255;not null;unique"`
+	Password           string `gorm:"size:255;not null"`
+	Role               string `gorm:"size:50;not null"`
+	Verified           bool   `gorm:"not null;default:false"`
+	VerificationSentAt *time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName returns the table name for UserModel.
+func (UserModel) TableName() string {
+	return "users"
+}
+
+// UserRepositoryGORM implements domain.UserRepository using GORM (MySQL, SQLite or PostgreSQL).
+type UserRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewUserRepositoryGORM creates a new UserRepositoryGORM.
+func NewUserRepositoryGORM(db *gorm.DB) *UserRepositoryGORM {
+	return &UserRepositoryGORM{db: db}
+}
+
+// Save saves a user to database.
+func (r *UserRepositoryGORM) Save(ctx context.Context, user domain.User) (domain.User, error) {
+	model := toUserModel(user)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		// Two registrations can pass ExistsByEmail at the same time
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.User{}, domain.ErrEmailExists
+		}
+		return domain.User{}, err
+	}
+
+	return toUserDomain(model), nil
+}
+
+// FindByID finds a user by ID.
+func (r *UserRepositoryGORM) FindByID(ctx context.Context, id uint) (domain.User, error) {
+	var model UserModel
+
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.User{}, domain.ErrUserNotFound
+		}
+		return domain.User{}, err
+	}
+
+	return toUserDomain(model), nil
+}
+
+// FindByEmail finds a user by email.
+func (r *UserRepositoryGORM) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	var model UserModel
+
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.User{}, domain.ErrUserNotFound
+		}
+		return domain.User{}, err
+	}
+
+	return toUserDomain(model), nil
+}
+
+// FindAll returns all users.
+func (r *UserRepositoryGORM) FindAll(ctx context.Context) ([]domain.User, error) {
+	var models []UserModel
+
+	if err := r.db.WithContext(ctx).Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, len(models))
+	for i, model := range models {
+		users[i] = toUserDomain(model)
+	}
+
+	return users, nil
+}
+
+// Update updates a user in database.
+func (r *UserRepositoryGORM) Update(ctx context.Context, user domain.User) (domain.User, error) {
+	model := toUserModel(user)
+
+	if err := r.db.WithContext(ctx).Save(&model).Error; err != nil {
+		return domain.User{}, err
+	}
+
+	return toUserDomain(model), nil
+}
+
+// Delete soft deletes a user from database.
+func (r *UserRepositoryGORM) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&UserModel{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindDeleted returns all soft deleted users.
+func (r *UserRepositoryGORM) FindDeleted(ctx context.Context) ([]domain.User, error) {
+	var models []UserModel
+
+	if err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, len(models))
+	for i, model := range models {
+		users[i] = toUserDomain(model)
+	}
+
+	return users, nil
+}
+
+// Restore restores a soft deleted user.
+func (r *UserRepositoryGORM) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&UserModel{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes users soft deleted before the given time.
+// Users still referenced by orders are kept so order history stays intact.
+func (r *UserRepositoryGORM) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)").
+		Delete(&UserModel{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// ExistsByEmail checks if a user with given email exists, including soft deleted users.
+func (r *UserRepositoryGORM) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).Unscoped().Model(&UserModel{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// toUserModel converts domain.User to UserModel.
+func toUserModel(user domain.User) UserModel {
+	return UserModel{
+		ID:                 user.ID,
+		Name:               user.Name,
+		Email:              user.Email,
+		Password:           user.Password,
+		Role:               user.Role,
+		Verified:           user.Verified,
+		VerificationSentAt: user.VerificationSentAt,
+		DeletedAt:          toGormDeletedAt(user.DeletedAt),
+	}
+}
+
+// toUserDomain converts UserModel to domain.User.
+func toUserDomain(model UserModel) domain.User {
+	return domain.User{
+		ID:                 model.ID,
+		Name:               model.Name,
+		Email:              model.Email,
+		Password:           model.Password,
+		Role:               model.Role,
+		Verified:           model.Verified,
+		VerificationSentAt: model.VerificationSentAt,
+		DeletedAt:          fromGormDeletedAt(model.DeletedAt),
+	}
+}
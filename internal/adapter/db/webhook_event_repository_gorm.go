@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEventModel is the database model for WebhookEvent.
+type WebhookEventModel struct {
+	ID          uint   `gorm:"primaryKey"`
+	EventID     string `gorm:"size:255;not null;uniqueIndex"`
+	Type        string `gorm:"size:50;not null"`
+	Payload     []byte `gorm:"not null"`
+	Error       string `gorm:"size:1000"`
+	ClaimedAt   *time.Time
+	ProcessedAt *time.Time
+	ReceivedAt  time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for WebhookEventModel.
+func (WebhookEventModel) TableName() string {
+	return "webhook_events"
+}
+
+// WebhookEventRepositoryGORM implements domain.WebhookEventRepository using GORM (MySQL, SQLite or PostgreSQL).
+type WebhookEventRepositoryGORM struct {
+	db *gorm.DB
+}
+
+// NewWebhookEventRepositoryGORM creates a new WebhookEventRepositoryGORM.
+func NewWebhookEventRepositoryGORM(db *gorm.DB) *WebhookEventRepositoryGORM {
+	return &WebhookEventRepositoryGORM{db: db}
+}
+
+// Create saves a new webhook event. The unique index on the event ID
+// rejects deliveries of an event that is already stored.
+func (r *WebhookEventRepositoryGORM) Create(ctx context.Context, event domain.WebhookEvent) (domain.WebhookEvent, error) {
+	model := toWebhookEventModel(event)
+
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.WebhookEvent{}, domain.ErrWebhookEventExists
+		}
+		return domain.WebhookEvent{}, err
+	}
+
+	return toWebhookEventDomain(model), nil
+}
+
+// FindByEventID finds a webhook event by the provider's event ID.
+func (r *WebhookEventRepositoryGORM) FindByEventID(ctx context.Context, eventID string) (domain.WebhookEvent, error) {
+	var model WebhookEventModel
+
+	if err := r.db.WithContext(ctx).Where("event_id = ?", eventID).First(&model).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return domain.WebhookEvent{}, domain.ErrWebhookEventNotFound
+		}
+		return domain.WebhookEvent{}, err
+	}
+
+	return toWebhookEventDomain(model), nil
+}
+
+// FindAll returns all webhook events, oldest first.
+func (r *WebhookEventRepositoryGORM) FindAll(ctx context.Context) ([]domain.WebhookEvent, error) {
+	var models []WebhookEventModel
+
+	if err := r.db.WithContext(ctx).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.WebhookEvent, len(models))
+	for i, model := range models {
+		events[i] = toWebhookEventDomain(model)
+	}
+
+	return events, nil
+}
+
+// Claim marks an unprocessed webhook event as being processed with a
+// conditional update, so concurrent deliveries cannot both claim it.
+func (r *WebhookEventRepositoryGORM) Claim(ctx context.Context, id uint, claimedAt, staleBefore time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&WebhookEventModel{}).
+		Where("id = ? AND processed_at IS NULL AND (claimed_at IS NULL OR claimed_at < ?)", id, staleBefore).
+		Update("claimed_at", claimedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Either the event is gone or another delivery has it
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&WebhookEventModel{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrWebhookEventNotFound
+		}
+		return domain.ErrWebhookEventInProgress
+	}
+
+	return nil
+}
+
+// MarkProcessed records that a webhook event was processed.
+func (r *WebhookEventRepositoryGORM) MarkProcessed(ctx context.Context, id uint, processedAt time.Time) error {
+	return r.update(ctx, id, map[string]interface{}{
+		"processed_at": processedAt,
+		"error":        "",
+	})
+}
+
+// MarkFailed records why processing a webhook event failed.
+func (r *WebhookEventRepositoryGORM) MarkFailed(ctx context.Context, id uint, reason string) error {
+	return r.update(ctx, id, map[string]interface{}{
+		"error":      reason,
+		"claimed_at": nil,
+	})
+}
+
+// update updates columns of a webhook event.
+func (r *WebhookEventRepositoryGORM) update(ctx context.Context, id uint, columns map[string]interface{}) error {
+	result := r.db.WithContext(ctx).
+		Model(&WebhookEventModel{}).
+		Where("id = ?", id).
+		Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWebhookEventNotFound
+	}
+	return nil
+}
+
+// toWebhookEventModel converts domain.WebhookEvent to WebhookEventModel.
+func toWebhookEventModel(event domain.WebhookEvent) WebhookEventModel {
+	return WebhookEventModel{
+		ID:          event.ID,
+		EventID:     event.EventID,
+		Type:        event.Type,
+		Payload:     event.Payload,
+		Error:       event.Error,
+		ClaimedAt:   event.ClaimedAt,
+		ProcessedAt: event.ProcessedAt,
+		ReceivedAt:  event.ReceivedAt,
+	}
+}
+
+// toWebhookEventDomain converts WebhookEventModel to domain.WebhookEvent.
+func toWebhookEventDomain(model WebhookEventModel) domain.WebhookEvent {
+	return domain.WebhookEvent{
+		ID:          model.ID,
+		EventID:     model.EventID,
+		Type:        model.Type,
+		Payload:     model.Payload,
+		Error:       model.Error,
+		ClaimedAt:   model.ClaimedAt,
+		ProcessedAt: model.ProcessedAt,
+		ReceivedAt:  model.ReceivedAt,
+	}
+}
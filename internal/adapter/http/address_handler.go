@@ -0,0 +1,248 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// AddressHandler handles HTTP requests for users' address books.
+type AddressHandler struct {
+	addressUsecase *usecase.AddressUsecase
+}
+
+// NewAddressHandler creates a new AddressHandler.
+func NewAddressHandler(addressUsecase *usecase.AddressUsecase) *AddressHandler {
+	return &AddressHandler{
+		addressUsecase: addressUsecase,
+	}
+}
+
+// AddressRequest is the request body for creating or updating an address.
+type AddressRequest struct {
+	Label      string `json:"label"`
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+}
+
+// AddressResponse is the response body for address operations.
+type AddressResponse struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"user_id"`
+	Label      string    `json:"label,omitempty"`
+	Name       string    `json:"name"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2,omitempty"`
+	City       string    `json:"city"`
+	Region     string    `json:"region,omitempty"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// OrderAddressResponse is an address as it was when an order was placed.
+type OrderAddressResponse struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone,omitempty"`
+}
+
+// Create handles POST /users/:userId/addresses.
+func (h *AddressHandler) Create(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	userID, err := strconv.ParseUint(ps.ByName("userId"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	var req AddressRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.CreateAddressInput{
+		UserID:        uint(userID),
+		Label:         req.Label,
+		AddressFields: toAddressFields(req),
+	}
+
+	output, err := h.addressUsecase.Create(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toAddressResponse(output)
+	helper.WriteJSON(w, http.StatusCreated, helper.Response{
+		Code:   http.StatusCreated,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// FindByUserID handles GET /users/:userId/addresses.
+func (h *AddressHandler) FindByUserID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	userID, err := strconv.ParseUint(ps.ByName("userId"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	outputs, err := h.addressUsecase.FindByUserID(r.Context(), uint(userID))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]AddressResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toAddressResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// FindByID handles GET /addresses/:id.
+func (h *AddressHandler) FindByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid address id")
+		return
+	}
+
+	output, err := h.addressUsecase.FindByID(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toAddressResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Update handles PUT /addresses/:id.
+func (h *AddressHandler) Update(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid address id")
+		return
+	}
+
+	var req AddressRequest
+	if err := helper.ReadJSONStrict(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.UpdateAddressInput{
+		ID:            uint(id),
+		Label:         req.Label,
+		AddressFields: toAddressFields(req),
+	}
+
+	output, err := h.addressUsecase.Update(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toAddressResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Delete handles DELETE /addresses/:id.
+func (h *AddressHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid address id")
+		return
+	}
+
+	if err := h.addressUsecase.Delete(r.Context(), uint(id)); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   nil,
+	})
+}
+
+// toAddressFields converts an HTTP request to usecase address fields.
+func toAddressFields(req AddressRequest) usecase.AddressFields {
+	return usecase.AddressFields{
+		Name:       req.Name,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		Phone:      req.Phone,
+	}
+}
+
+// toAddressResponse converts usecase output to HTTP response.
+func toAddressResponse(output usecase.AddressOutput) AddressResponse {
+	return AddressResponse{
+		ID:         output.ID,
+		UserID:     output.UserID,
+		Label:      output.Label,
+		Name:       output.Name,
+		Line1:      output.Line1,
+		Line2:      output.Line2,
+		City:       output.City,
+		Region:     output.Region,
+		PostalCode: output.PostalCode,
+		Country:    output.Country,
+		Phone:      output.Phone,
+		CreatedAt:  output.CreatedAt,
+		UpdatedAt:  output.UpdatedAt,
+	}
+}
+
+// toOrderAddressResponse converts an order's address to HTTP response.
+func toOrderAddressResponse(fields *usecase.AddressFields) *OrderAddressResponse {
+	if fields == nil {
+		return nil
+	}
+	return &OrderAddressResponse{
+		Name:       fields.Name,
+		Line1:      fields.Line1,
+		Line2:      fields.Line2,
+		City:       fields.City,
+		Region:     fields.Region,
+		PostalCode: fields.PostalCode,
+		Country:    fields.Country,
+		Phone:      fields.Phone,
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/helper"
 	"kikukafandi/book-shop-api/internal/usecase"
@@ -12,36 +13,87 @@ import (
 
 // BookHandler handles HTTP requests for books.
 type BookHandler struct {
-	bookUsecase *usecase.BookUsecase
+	bookUsecase    *usecase.BookUsecase
+	pricingService *usecase.PricingService
 }
 
 // NewBookHandler creates a new BookHandler.
-func NewBookHandler(bookUsecase *usecase.BookUsecase) *BookHandler {
+func NewBookHandler(bookUsecase *usecase.BookUsecase, pricingService *usecase.PricingService) *BookHandler {
 	return &BookHandler{
-		bookUsecase: bookUsecase,
+		bookUsecase:    bookUsecase,
+		pricingService: pricingService,
 	}
 }
 
 // CreateBookRequest is the request body for creating a book.
 type CreateBookRequest struct {
-	Title string  `json:"title"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	Title            string     `json:"title"`
+	Category         string     `json:"category"`
+	Weight           int        `json:"weight"`
+	Price            float64    `json:"price"`
+	Stock            int        `json:"stock"`
+	ReorderThreshold int        `json:"reorder_threshold"`
+	ReorderQuantity  int        `json:"reorder_quantity"`
+	AllowBackorder   bool       `json:"allow_backorder"`
+	AllowPreorder    bool       `json:"allow_preorder"`
+	ReleaseDate      *time.Time `json:"release_date"`
 }
 
 // UpdateBookRequest is the request body for updating a book.
+// Stock is changed through POST /books/:id/stock/adjust instead.
 type UpdateBookRequest struct {
-	Title string  `json:"title"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	Title            string     `json:"title"`
+	Category         string     `json:"category"`
+	Weight           int        `json:"weight"`
+	Price            float64    `json:"price"`
+	ReorderThreshold int        `json:"reorder_threshold"`
+	ReorderQuantity  int        `json:"reorder_quantity"`
+	AllowBackorder   bool       `json:"allow_backorder"`
+	AllowPreorder    bool       `json:"allow_preorder"`
+	ReleaseDate      *time.Time `json:"release_date"`
+}
+
+// BookPriceRequest is the request body for scheduling a price change or
+// a sale. EffectiveFrom defaults to now; only sales have an EffectiveTo.
+type BookPriceRequest struct {
+	Type          string     `json:"type"`
+	Price         float64    `json:"price"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
 }
 
 // BookResponse is the response body for book operations.
+// Stock is on hand; Available excludes stock reserved for pending orders.
+// Price is the current price, which is a sale price until SaleEndsAt.
 type BookResponse struct {
-	ID    uint    `json:"id"`
-	Title string  `json:"title"`
-	Price float64 `json:"price"`
-	Stock int     `json:"stock"`
+	ID               uint       `json:"id"`
+	Title            string     `json:"title"`
+	Category         string     `json:"category,omitempty"`
+	Weight           int        `json:"weight"`
+	Price            float64    `json:"price"`
+	RegularPrice     float64    `json:"regular_price"`
+	SaleEndsAt       *time.Time `json:"sale_ends_at,omitempty"`
+	Stock            int        `json:"stock"`
+	Reserved         int        `json:"reserved"`
+	Available        int        `json:"available"`
+	ReorderThreshold int        `json:"reorder_threshold"`
+	ReorderQuantity  int        `json:"reorder_quantity"`
+	AllowBackorder   bool       `json:"allow_backorder"`
+	AllowPreorder    bool       `json:"allow_preorder"`
+	ReleaseDate      *time.Time `json:"release_date,omitempty"`
+	Version          uint       `json:"version"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+// BookPriceResponse is the response body for price history operations.
+type BookPriceResponse struct {
+	ID            uint       `json:"id"`
+	BookID        uint       `json:"book_id"`
+	Type          string     `json:"type"`
+	Price         float64    `json:"price"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
 }
 
 // Create handles POST /books.
@@ -53,9 +105,16 @@ func (h *BookHandler) Create(w http.ResponseWriter, r *http.Request, _ httproute
 	}
 
 	input := usecase.CreateBookInput{
-		Title: req.Title,
-		Price: req.Price,
-		Stock: req.Stock,
+		Title:            req.Title,
+		Category:         req.Category,
+		Weight:           req.Weight,
+		Price:            req.Price,
+		Stock:            req.Stock,
+		ReorderThreshold: req.ReorderThreshold,
+		ReorderQuantity:  req.ReorderQuantity,
+		AllowBackorder:   req.AllowBackorder,
+		AllowPreorder:    req.AllowPreorder,
+		ReleaseDate:      req.ReleaseDate,
 	}
 
 	output, err := h.bookUsecase.Create(r.Context(), input)
@@ -64,6 +123,7 @@ func (h *BookHandler) Create(w http.ResponseWriter, r *http.Request, _ httproute
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(output.Version))
 	resp := toBookResponse(output)
 	helper.WriteJSON(w, http.StatusCreated, helper.Response{
 		Code:   http.StatusCreated,
@@ -86,6 +146,7 @@ func (h *BookHandler) FindByID(w http.ResponseWriter, r *http.Request, ps httpro
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(output.Version))
 	resp := toBookResponse(output)
 	helper.WriteJSON(w, http.StatusOK, helper.Response{
 		Code:   http.StatusOK,
@@ -95,23 +156,34 @@ func (h *BookHandler) FindByID(w http.ResponseWriter, r *http.Request, ps httpro
 }
 
 // FindAll handles GET /books.
+// An optional q query parameter searches book titles.
 func (h *BookHandler) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	outputs, err := h.bookUsecase.FindAll(r.Context())
+	page, ok := helper.ReadPage(w, r)
+	if !ok {
+		return
+	}
+
+	var (
+		outputs []usecase.BookOutput
+		total   int64
+		err     error
+	)
+	if query := r.URL.Query().Get("q"); query != "" {
+		outputs, total, err = h.bookUsecase.Search(r.Context(), query, page)
+	} else {
+		outputs, total, err = h.bookUsecase.FindAll(r.Context(), page)
+	}
 	if err != nil {
 		helper.WriteErrorFromDomain(w, err)
 		return
 	}
 
-	var responses []BookResponse
-	for _, output := range outputs {
-		responses = append(responses, toBookResponse(output))
+	responses := make([]BookResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toBookResponse(output)
 	}
 
-	helper.WriteJSON(w, http.StatusOK, helper.Response{
-		Code:   http.StatusOK,
-		Status: "success",
-		Data:   responses,
-	})
+	helper.WritePage(w, r, responses, page, total)
 }
 
 // Update handles PUT /books/:id.
@@ -122,17 +194,30 @@ func (h *BookHandler) Update(w http.ResponseWriter, r *http.Request, ps httprout
 		return
 	}
 
+	version, err := helper.IfMatchVersion(r)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
 	var req UpdateBookRequest
-	if err := helper.ReadJSON(r, &req); err != nil {
+	if err := helper.ReadJSONStrict(r, &req); err != nil {
 		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
 	input := usecase.UpdateBookInput{
-		ID:    uint(id),
-		Title: req.Title,
-		Price: req.Price,
-		Stock: req.Stock,
+		ID:               uint(id),
+		Title:            req.Title,
+		Category:         req.Category,
+		Weight:           req.Weight,
+		Price:            req.Price,
+		ReorderThreshold: req.ReorderThreshold,
+		ReorderQuantity:  req.ReorderQuantity,
+		AllowBackorder:   req.AllowBackorder,
+		AllowPreorder:    req.AllowPreorder,
+		ReleaseDate:      req.ReleaseDate,
+		Version:          version,
 	}
 
 	output, err := h.bookUsecase.Update(r.Context(), input)
@@ -141,6 +226,7 @@ func (h *BookHandler) Update(w http.ResponseWriter, r *http.Request, ps httprout
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(output.Version))
 	resp := toBookResponse(output)
 	helper.WriteJSON(w, http.StatusOK, helper.Response{
 		Code:   http.StatusOK,
@@ -157,7 +243,18 @@ func (h *BookHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprout
 		return
 	}
 
-	if err := h.bookUsecase.Delete(r.Context(), uint(id)); err != nil {
+	version, err := helper.IfMatchVersion(r)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	input := usecase.DeleteBookInput{
+		ID:      uint(id),
+		Version: version,
+	}
+
+	if err := h.bookUsecase.Delete(r.Context(), input); err != nil {
 		helper.WriteErrorFromDomain(w, err)
 		return
 	}
@@ -169,12 +266,134 @@ func (h *BookHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprout
 	})
 }
 
+// FindDeleted handles GET /admin/books/deleted.
+func (h *BookHandler) FindDeleted(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs, err := h.bookUsecase.FindDeleted(r.Context())
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]BookResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toBookResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Restore handles POST /admin/books/:id/restore.
+func (h *BookHandler) Restore(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	output, err := h.bookUsecase.Restore(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toBookResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// PriceHistory handles GET /books/:id/price-history.
+func (h *BookHandler) PriceHistory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	outputs, err := h.pricingService.History(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]BookPriceResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toBookPriceResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// SchedulePrice handles POST /books/:id/prices.
+func (h *BookHandler) SchedulePrice(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	var req BookPriceRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.SchedulePriceInput{
+		BookID:        uint(id),
+		Type:          req.Type,
+		Price:         req.Price,
+		EffectiveFrom: req.EffectiveFrom,
+		EffectiveTo:   req.EffectiveTo,
+	}
+
+	output, err := h.pricingService.Schedule(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toBookPriceResponse(output)
+	helper.WriteJSON(w, http.StatusCreated, helper.Response{
+		Code:   http.StatusCreated,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
 // toBookResponse converts usecase output to HTTP response.
 func toBookResponse(output usecase.BookOutput) BookResponse {
 	return BookResponse{
-		ID:    output.ID,
-		Title: output.Title,
-		Price: output.Price,
-		Stock: output.Stock,
+		ID:               output.ID,
+		Title:            output.Title,
+		Category:         output.Category,
+		Weight:           output.Weight,
+		Price:            output.Price,
+		RegularPrice:     output.RegularPrice,
+		SaleEndsAt:       output.SaleEndsAt,
+		Stock:            output.Stock,
+		Reserved:         output.Reserved,
+		Available:        output.Available,
+		ReorderThreshold: output.ReorderThreshold,
+		ReorderQuantity:  output.ReorderQuantity,
+		AllowBackorder:   output.AllowBackorder,
+		AllowPreorder:    output.AllowPreorder,
+		ReleaseDate:      output.ReleaseDate,
+		Version:          output.Version,
+		DeletedAt:        output.DeletedAt,
+	}
+}
+
+// toBookPriceResponse converts usecase output to HTTP response.
+func toBookPriceResponse(output usecase.BookPriceOutput) BookPriceResponse {
+	return BookPriceResponse{
+		ID:            output.ID,
+		BookID:        output.BookID,
+		Type:          output.Type,
+		Price:         output.Price,
+		EffectiveFrom: output.EffectiveFrom,
+		EffectiveTo:   output.EffectiveTo,
+		CreatedAt:     output.CreatedAt,
 	}
 }
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+// DocsHandler serves the OpenAPI document and a self-hosted Swagger UI.
+type DocsHandler struct {
+	spec []byte
+	ui   http.Handler
+}
+
+// NewDocsHandler creates a new DocsHandler for the given OpenAPI document.
+func NewDocsHandler(spec []byte) *DocsHandler {
+	return &DocsHandler{
+		spec: spec,
+		ui:   v5emb.New("Bookstore API", "/openapi.json", "/docs/"),
+	}
+}
+
+// OpenAPI handles GET /openapi.json.
+func (h *DocsHandler) OpenAPI(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.spec)
+}
+
+// UI handles GET /docs/*filepath.
+// Swagger UI assets are embedded in the binary, so the page works offline.
+func (h *DocsHandler) UI(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	h.ui.ServeHTTP(w, r)
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// FulfilmentHandler handles HTTP requests for shipping orders.
+type FulfilmentHandler struct {
+	fulfilmentUsecase *usecase.FulfilmentUsecase
+}
+
+// NewFulfilmentHandler creates a new FulfilmentHandler.
+func NewFulfilmentHandler(fulfilmentUsecase *usecase.FulfilmentUsecase) *FulfilmentHandler {
+	return &FulfilmentHandler{
+		fulfilmentUsecase: fulfilmentUsecase,
+	}
+}
+
+// ShipOrderRequest is the request body for shipping a packed order.
+type ShipOrderRequest struct {
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// ShippingMethodResponse is the response body for a shipping method.
+type ShippingMethodResponse struct {
+	Code  string                 `json:"code"`
+	Basis string                 `json:"basis"`
+	Rates []ShippingRateResponse `json:"rates"`
+}
+
+// ShippingRateResponse is one step of a shipping method's rate table.
+type ShippingRateResponse struct {
+	From int     `json:"from"`
+	Cost float64 `json:"cost"`
+}
+
+// TrackingResponse is the response body for fulfilment operations.
+type TrackingResponse struct {
+	OrderID         uint                  `json:"order_id"`
+	Status          string                `json:"status"`
+	ShippingMethod  string                `json:"shipping_method"`
+	ShippingAddress *OrderAddressResponse `json:"shipping_address,omitempty"`
+	Carrier         string                `json:"carrier,omitempty"`
+	TrackingNumber  string                `json:"tracking_number,omitempty"`
+	PackedAt        *time.Time            `json:"packed_at,omitempty"`
+	ShippedAt       *time.Time            `json:"shipped_at,omitempty"`
+	DeliveredAt     *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// Methods handles GET /shipping-methods.
+func (h *FulfilmentHandler) Methods(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs := h.fulfilmentUsecase.Methods()
+
+	responses := make([]ShippingMethodResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toShippingMethodResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Pack handles POST /orders/:id/pack.
+func (h *FulfilmentHandler) Pack(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.fulfilmentUsecase.Pack(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toTrackingResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Ship handles POST /orders/:id/ship.
+func (h *FulfilmentHandler) Ship(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	var req ShipOrderRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	output, err := h.fulfilmentUsecase.Ship(r.Context(), usecase.ShipOrderInput{
+		OrderID:        uint(id),
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+	})
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toTrackingResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Deliver handles POST /orders/:id/deliver.
+func (h *FulfilmentHandler) Deliver(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.fulfilmentUsecase.Deliver(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toTrackingResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Tracking handles GET /orders/:id/tracking.
+func (h *FulfilmentHandler) Tracking(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.fulfilmentUsecase.Tracking(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toTrackingResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// toShippingMethodResponse converts usecase output to HTTP response.
+func toShippingMethodResponse(output usecase.ShippingMethodOutput) ShippingMethodResponse {
+	rates := make([]ShippingRateResponse, len(output.Rates))
+	for i, rate := range output.Rates {
+		rates[i] = ShippingRateResponse{From: rate.From, Cost: rate.Cost}
+	}
+
+	return ShippingMethodResponse{
+		Code:  output.Code,
+		Basis: output.Basis,
+		Rates: rates,
+	}
+}
+
+// toTrackingResponse converts usecase output to HTTP response.
+func toTrackingResponse(output usecase.TrackingOutput) TrackingResponse {
+	return TrackingResponse{
+		OrderID:         output.OrderID,
+		Status:          output.Status,
+		ShippingMethod:  output.ShippingMethod,
+		ShippingAddress: toOrderAddressResponse(output.ShippingAddress),
+		Carrier:         output.Carrier,
+		TrackingNumber:  output.TrackingNumber,
+		PackedAt:        output.PackedAt,
+		ShippedAt:       output.ShippedAt,
+		DeliveredAt:     output.DeliveredAt,
+	}
+}
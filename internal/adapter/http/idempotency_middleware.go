@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// IdempotencyKeyHeader carries a client chosen key that identifies a request across retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyMiddleware answers retried requests with the response to the
+// first request sent with the same Idempotency-Key instead of running them again.
+type IdempotencyMiddleware struct {
+	idempotencyUsecase *usecase.IdempotencyUsecase
+}
+
+// NewIdempotencyMiddleware creates a new IdempotencyMiddleware.
+func NewIdempotencyMiddleware(idempotencyUsecase *usecase.IdempotencyUsecase) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		idempotencyUsecase: idempotencyUsecase,
+	}
+}
+
+// Wrap makes a handler idempotent for requests carrying an Idempotency-Key.
+// Requests without the header are passed through unchanged.
+func (m *IdempotencyMiddleware) Wrap(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r, ps)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		output, err := m.idempotencyUsecase.Begin(r.Context(), usecase.BeginRequestInput{
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+		})
+		if err != nil {
+			helper.WriteErrorFromDomain(w, err)
+			return
+		}
+
+		if output.Replay {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(output.StatusCode)
+			w.Write(output.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(recorder, r, ps)
+
+		// The outcome must be stored even if the client has gone away
+		ctx := context.WithoutCancel(r.Context())
+
+		// Server errors are not final, so the key is freed for a retry
+		if recorder.statusCode >= http.StatusInternalServerError {
+			if err := m.idempotencyUsecase.Release(ctx, output.ID); err != nil {
+				log.Printf("Failed to release idempotency key %d: %v", output.ID, err)
+			}
+			return
+		}
+
+		if err := m.idempotencyUsecase.Finish(ctx, output.ID, recorder.statusCode, recorder.body.Bytes()); err != nil {
+			log.Printf("Failed to store response for idempotency key %d: %v", output.ID, err)
+		}
+	}
+}
+
+// fingerprint hashes the parts of a request that must match on a retry.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+// WriteHeader records the status code.
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the body.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// InventoryHandler handles HTTP requests for the stock ledger.
+type InventoryHandler struct {
+	inventoryUsecase *usecase.InventoryUsecase
+}
+
+// NewInventoryHandler creates a new InventoryHandler.
+func NewInventoryHandler(inventoryUsecase *usecase.InventoryUsecase) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryUsecase: inventoryUsecase,
+	}
+}
+
+// AdjustStockRequest is the request body for a manual stock adjustment.
+type AdjustStockRequest struct {
+	Delta     int    `json:"delta"`
+	Reason    string `json:"reason"`
+	Reference string `json:"reference"`
+}
+
+// ShipmentRequest is the request body for receiving a shipment.
+type ShipmentRequest struct {
+	Reference string                `json:"reference"`
+	Items     []ShipmentItemRequest `json:"items"`
+}
+
+// ShipmentItemRequest is one line of a ShipmentRequest.
+type ShipmentItemRequest struct {
+	BookID   uint `json:"book_id"`
+	Quantity int  `json:"quantity"`
+}
+
+// ShipmentResponse is the response body for a received shipment.
+type ShipmentResponse struct {
+	Reference string         `json:"reference"`
+	Books     []BookResponse `json:"books"`
+}
+
+// StockMovementResponse is the response body for a stock ledger entry.
+type StockMovementResponse struct {
+	ID        uint      `json:"id"`
+	BookID    uint      `json:"book_id"`
+	Delta     int       `json:"delta"`
+	Balance   int       `json:"balance"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StockHistory handles GET /books/:id/stock-history.
+func (h *InventoryHandler) StockHistory(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	outputs, err := h.inventoryUsecase.StockHistory(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]StockMovementResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toStockMovementResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Adjust handles POST /books/:id/stock/adjust.
+func (h *InventoryHandler) Adjust(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid book id")
+		return
+	}
+
+	var req AdjustStockRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.AdjustStockInput{
+		BookID:    uint(id),
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		Reference: req.Reference,
+	}
+
+	output, err := h.inventoryUsecase.Adjust(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", helper.ETag(output.Version))
+	resp := toBookResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// ReceiveShipment handles POST /inventory/shipments.
+func (h *InventoryHandler) ReceiveShipment(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req ShipmentRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.ReceiveShipmentInput{
+		Reference: req.Reference,
+		Items:     make([]usecase.ShipmentItemInput, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		input.Items[i] = usecase.ShipmentItemInput{
+			BookID:   item.BookID,
+			Quantity: item.Quantity,
+		}
+	}
+
+	outputs, err := h.inventoryUsecase.ReceiveShipment(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := ShipmentResponse{
+		Reference: req.Reference,
+		Books:     make([]BookResponse, len(outputs)),
+	}
+	for i, output := range outputs {
+		resp.Books[i] = toBookResponse(output)
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// LowStock handles GET /inventory/low-stock.
+func (h *InventoryHandler) LowStock(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs, err := h.inventoryUsecase.LowStock(r.Context())
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]BookResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toBookResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// toStockMovementResponse converts usecase output to HTTP response.
+func toStockMovementResponse(output usecase.StockMovementOutput) StockMovementResponse {
+	return StockMovementResponse{
+		ID:        output.ID,
+		BookID:    output.BookID,
+		Delta:     output.Delta,
+		Balance:   output.Balance,
+		Reason:    output.Reason,
+		Actor:     output.Actor,
+		Reference: output.Reference,
+		CreatedAt: output.CreatedAt,
+	}
+}
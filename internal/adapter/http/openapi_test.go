@@ -0,0 +1,459 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bookshop "kikukafandi/book-shop-api"
+	httpAdapter "kikukafandi/book-shop-api/internal/adapter/http"
+	"kikukafandi/book-shop-api/internal/helper"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// spec is loaded once in TestMain and shared by every test server.
+var spec *specValidator
+
+// specValidator validates traffic against the OpenAPI document and records
+// which operations the tests have exercised.
+type specValidator struct {
+	doc    *openapi3.T
+	router routers.Router
+
+	mu      sync.Mutex
+	covered map[string]bool
+}
+
+func TestMain(m *testing.M) {
+	var err error
+	spec, err = loadSpec(bookshop.APISpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load apispec.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	// Only a full run can tell whether an operation was never exercised
+	if code == 0 && flag.Lookup("test.run").Value.String() == "" {
+		if missing := spec.uncovered(); len(missing) > 0 {
+			fmt.Fprintln(os.Stderr, "operations in apispec.json not exercised by any test:")
+			for _, operation := range missing {
+				fmt.Fprintf(os.Stderr, "\t%s\n", operation)
+			}
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+// loadSpec parses and validates the OpenAPI document.
+func loadSpec(data []byte) (*specValidator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// Test servers listen on random ports, so match paths regardless of host
+	doc.Servers = nil
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &specValidator{doc: doc, router: router, covered: map[string]bool{}}, nil
+}
+
+// operations returns every documented operation as "METHOD /path".
+func (s *specValidator) operations() []string {
+	var operations []string
+	for path, item := range s.doc.Paths.Map() {
+		for method := range item.Operations() {
+			operations = append(operations, method+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func (s *specValidator) cover(operation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.covered[operation] = true
+}
+
+func (s *specValidator) uncovered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []string
+	for _, operation := range s.operations() {
+		if !s.covered[operation] {
+			missing = append(missing, operation)
+		}
+	}
+	return missing
+}
+
+// invalidRequest marks a request that deliberately breaks the spec, so only
+// its response is validated. The header is not sent to the server.
+const invalidRequest = "X-Test-Invalid-Request"
+
+// specTransport fails the test when a request or its response does not match
+// the OpenAPI document.
+type specTransport struct {
+	t    *testing.T
+	base http.RoundTripper
+}
+
+func (tr *specTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	req.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+	route, pathParams, err := spec.router.FindRoute(req)
+	if err != nil {
+		tr.t.Errorf("%s %s is not documented in apispec.json: %v", req.Method, req.URL.Path, err)
+		return tr.base.RoundTrip(req)
+	}
+	operation := req.Method + " " + route.Path
+	spec.cover(operation)
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		MultiError:            true,
+		SkipSettingDefaults:   true,
+	}
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+	expectInvalid := req.Header.Get(invalidRequest) != ""
+	req.Header.Del(invalidRequest)
+	if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil && !expectInvalid {
+		tr.t.Errorf("%s: request does not match apispec.json: %v", operation, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	req.ContentLength = int64(len(reqBody))
+
+	resp, err := tr.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	output := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(respBody)),
+		Options:                options,
+	}
+	if err := openapi3filter.ValidateResponse(req.Context(), output); err != nil {
+		tr.t.Errorf("%s: %d response does not match apispec.json: %v\n%s", operation, resp.StatusCode, err, respBody)
+	}
+
+	return resp, nil
+}
+
+// routeParam matches httprouter path parameters such as :id.
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+func TestRoutesMatchSpec(t *testing.T) {
+	documented := map[string]bool{}
+	for _, operation := range spec.operations() {
+		documented[operation] = true
+	}
+
+	routed := map[string]bool{}
+	for _, route := range newTestRouter(t).Routes() {
+		operation := route.Method + " " + routeParam.ReplaceAllString(route.Path, "{$1}")
+		routed[operation] = true
+		if !documented[operation] {
+			t.Errorf("route %s is not documented in apispec.json", operation)
+		}
+	}
+
+	for operation := range documented {
+		if !routed[operation] {
+			t.Errorf("apispec.json documents %s but no route serves it", operation)
+		}
+	}
+}
+
+// specTypes maps each schema in apispec.json to the Go types it describes.
+var specTypes = map[string][]interface{}{
+	"Error":                 {helper.ErrorResponse{}},
+	"ListMeta":              {helper.ListMeta{}},
+	"ListLinks":             {helper.ListLinks{}},
+	"Message":               {httpAdapter.MessageResponse{}},
+	"User":                  {httpAdapter.UserResponse{}},
+	"Book":                  {httpAdapter.BookResponse{}},
+	"Order":                 {httpAdapter.OrderResponse{}},
+	"StockMovement":         {httpAdapter.StockMovementResponse{}},
+	"BookPrice":             {httpAdapter.BookPriceResponse{}},
+	"Shipment":              {httpAdapter.ShipmentResponse{}},
+	"Payment":               {httpAdapter.PaymentResponse{}},
+	"RegisterRequest":       {httpAdapter.RegisterRequest{}},
+	"LoginRequest":          {httpAdapter.LoginRequest{}},
+	"EmailRequest":          {httpAdapter.ResendVerificationRequest{}, httpAdapter.ForgotPasswordRequest{}},
+	"ResetPasswordRequest":  {httpAdapter.ResetPasswordRequest{}},
+	"BookCreateRequest":     {httpAdapter.CreateBookRequest{}},
+	"BookUpdateRequest":     {httpAdapter.UpdateBookRequest{}},
+	"OrderRequest":          {httpAdapter.CreateOrderRequest{}},
+	"StockAdjustRequest":    {httpAdapter.AdjustStockRequest{}},
+	"BookPriceRequest":      {httpAdapter.BookPriceRequest{}},
+	"ShipmentRequest":       {httpAdapter.ShipmentRequest{}},
+	"ShipmentItem":          {httpAdapter.ShipmentItemRequest{}},
+	"PaymentRequest":        {httpAdapter.PayOrderRequest{}},
+	"PaymentWebhookRequest": {httpAdapter.PaymentWebhookRequest{}},
+	"PaymentWebhookData":    {httpAdapter.PaymentWebhookData{}},
+	"Return":                {httpAdapter.ReturnResponse{}},
+	"ReturnRequest":         {httpAdapter.CreateReturnRequest{}},
+	"ReturnApproveRequest":  {httpAdapter.ApproveReturnRequest{}},
+	"ReturnRejectRequest":   {httpAdapter.RejectReturnRequest{}},
+	"Promotion":             {httpAdapter.PromotionResponse{}},
+	"PromotionRequest":      {httpAdapter.PromotionRequest{}},
+	"Address":               {httpAdapter.AddressResponse{}},
+	"AddressRequest":        {httpAdapter.AddressRequest{}},
+	"OrderAddress":          {httpAdapter.OrderAddressResponse{}},
+	"ShippingMethod":        {httpAdapter.ShippingMethodResponse{}},
+	"ShippingRate":          {httpAdapter.ShippingRateResponse{}},
+	"ShipOrderRequest":      {httpAdapter.ShipOrderRequest{}},
+	"Tracking":              {httpAdapter.TrackingResponse{}},
+}
+
+func TestSpecSchemasMatchHandlerTypes(t *testing.T) {
+	schemas := spec.doc.Components.Schemas
+
+	for name, values := range specTypes {
+		ref, ok := schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing from apispec.json", name)
+			continue
+		}
+		for _, value := range values {
+			checkSchemaType(t, name, ref.Value, reflect.TypeOf(value))
+		}
+	}
+
+	// Envelope schemas are helper.Response or helper.ListResponse with a typed data field
+	for name, ref := range schemas {
+		switch {
+		case strings.HasSuffix(name, "ListEnvelope"):
+			checkSchemaType(t, name, withoutData(ref.Value), reflect.TypeOf(helper.ListResponse{}))
+		case strings.HasSuffix(name, "Envelope"):
+			checkSchemaType(t, name, withoutData(ref.Value), reflect.TypeOf(helper.Response{}))
+		}
+	}
+}
+
+// withoutData strips the data property, which helper.Response leaves untyped.
+func withoutData(schema *openapi3.Schema) *openapi3.Schema {
+	stripped := *schema
+	stripped.Properties = openapi3.Schemas{}
+	for name, property := range schema.Properties {
+		if name != "data" {
+			stripped.Properties[name] = property
+		}
+	}
+	return &stripped
+}
+
+// checkSchemaType reports JSON fields of typ that the schema does not describe
+// and schema properties typ does not have.
+func checkSchemaType(t *testing.T, name string, schema *openapi3.Schema, typ reflect.Type) {
+	t.Helper()
+
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		// Envelope data is untyped here and checked through its own schema
+		if jsonName == "" || jsonName == "-" || (jsonName == "data" && typ.PkgPath() == reflect.TypeOf(helper.Response{}).PkgPath()) {
+			continue
+		}
+		fields[jsonName] = field
+	}
+
+	for jsonName, field := range fields {
+		property, ok := schema.Properties[jsonName]
+		if !ok {
+			t.Errorf("%s: field %s.%s (%q) is not documented in apispec.json", name, typ.Name(), field.Name, jsonName)
+			continue
+		}
+		if want := schemaTypeOf(field.Type); property.Value.Type == nil || !property.Value.Type.Is(want) {
+			t.Errorf("%s: property %q should be %s for %s.%s", name, jsonName, want, typ.Name(), field.Name)
+		}
+	}
+
+	for jsonName := range schema.Properties {
+		if _, ok := fields[jsonName]; !ok {
+			t.Errorf("%s: property %q has no field in %s", name, jsonName, typ.Name())
+		}
+	}
+}
+
+// schemaTypeOf returns the OpenAPI type a Go type encodes to.
+func schemaTypeOf(typ reflect.Type) string {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		return openapi3.TypeString
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return openapi3.TypeBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openapi3.TypeInteger
+	case reflect.Float32, reflect.Float64:
+		return openapi3.TypeNumber
+	case reflect.String:
+		return openapi3.TypeString
+	case reflect.Slice, reflect.Array:
+		return openapi3.TypeArray
+	default:
+		return openapi3.TypeObject
+	}
+}
+
+func TestDocsAreServed(t *testing.T) {
+	server := httptest.NewServer(newTestRouter(t).Setup())
+	t.Cleanup(server.Close)
+
+	resp, err := server.Client().Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("get spec: %v", err)
+	}
+	served, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(served, bookshop.APISpec) {
+		t.Fatalf("expected the embedded spec, got %d", resp.StatusCode)
+	}
+
+	// /docs redirects to /docs/, which loads its assets from the server itself
+	resp, err = server.Client().Get(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("get docs: %v", err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), "/openapi.json") {
+		t.Fatalf("expected the docs page, got %d", resp.StatusCode)
+	}
+
+	assets := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(string(page), -1)
+	if len(assets) == 0 {
+		t.Fatal("docs page loads no assets")
+	}
+	for _, asset := range assets {
+		if strings.HasPrefix(asset[1], "http") || strings.HasPrefix(asset[1], "//") {
+			t.Errorf("docs page loads %s from outside the server", asset[1])
+			continue
+		}
+		resp, err := server.Client().Get(server.URL + asset[1])
+		if err != nil {
+			t.Fatalf("get %s: %v", asset[1], err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("get %s: expected 200, got %d", asset[1], resp.StatusCode)
+		}
+	}
+}
+
+func TestAuthFlowsMatchSpec(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/verify-email?token=bogus", nil, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	resp, body = do(t, server, http.MethodPost, "/verify-email/resend", map[string]string{"email": "nobody@example.com"}, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodPost, "/password/reset", map[string]string{"token": "bogus", "password": "newsecret123"}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+}
+
+func TestOrderAndAdminRoutesMatchSpec(t *testing.T) {
+	server := newTestServer(t)
+
+	// Lists are documented even when empty
+	for _, path := range []string{"/books", "/orders", "/admin/books/deleted", "/admin/users/deleted", "/admin/orders/deleted"} {
+		resp, body := do(t, server, http.MethodGet, path, nil, nil)
+		expectStatus(t, resp, body, http.StatusOK)
+	}
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/register", map[string]string{"name": "B", "email": "b@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/users/1/orders", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/books?q=go", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodDelete, "/orders/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/admin/orders/deleted", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/admin/orders/1/restore", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodDelete, "/users/2", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/admin/users/deleted", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/admin/users/2/restore", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/99", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/helper"
 	"kikukafandi/book-shop-api/internal/usecase"
@@ -24,19 +25,55 @@ func NewOrderHandler(orderUsecase *usecase.OrderUsecase) *OrderHandler {
 
 // CreateOrderRequest is the request body for creating an order.
 type CreateOrderRequest struct {
-	UserID   uint `json:"user_id"`
-	BookID   uint `json:"book_id"`
-	Quantity int  `json:"quantity"`
+	UserID        uint   `json:"user_id"`
+	BookID        uint   `json:"book_id"`
+	Quantity      int    `json:"quantity"`
+	PromotionCode string `json:"promotion_code"`
+	// ShippingAddressID and BillingAddressID are entries in the user's
+	// address book; billing defaults to the shipping address.
+	ShippingAddressID *uint `json:"shipping_address_id"`
+	BillingAddressID  *uint `json:"billing_address_id"`
+	// ShippingMethod is the code of a method from GET /shipping-methods.
+	ShippingMethod string `json:"shipping_method"`
 }
 
 // OrderResponse is the response body for order operations.
 type OrderResponse struct {
-	ID       uint    `json:"id"`
-	UserID   uint    `json:"user_id"`
-	BookID   uint    `json:"book_id"`
-	Quantity int     `json:"quantity"`
-	Total    float64 `json:"total"`
-	Status   string  `json:"status"`
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	BookID         uint       `json:"book_id"`
+	Quantity       int        `json:"quantity"`
+	UnitPrice      float64    `json:"unit_price"`
+	Subtotal       float64    `json:"subtotal"`
+	Tax            float64    `json:"tax"`
+	ShippingMethod string     `json:"shipping_method,omitempty"`
+	ShippingCost   float64    `json:"shipping_cost"`
+	Total          float64    `json:"total"`
+	Discount       float64    `json:"discount"`
+	PromotionCode  string     `json:"promotion_code,omitempty"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Status         string     `json:"status"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+
+	ShippingAddress *OrderAddressResponse `json:"shipping_address,omitempty"`
+	BillingAddress  *OrderAddressResponse `json:"billing_address,omitempty"`
+}
+
+// PayOrderRequest is the request body for paying an order.
+type PayOrderRequest struct {
+	PaymentToken string `json:"payment_token"`
+}
+
+// PaymentResponse is the response body for payment operations.
+type PaymentResponse struct {
+	ID             uint      `json:"id"`
+	OrderID        uint      `json:"order_id"`
+	Amount         float64   `json:"amount"`
+	Status         string    `json:"status"`
+	Reference      string    `json:"reference,omitempty"`
+	FailureReason  string    `json:"failure_reason,omitempty"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 // Create handles POST /orders.
@@ -48,9 +85,14 @@ func (h *OrderHandler) Create(w http.ResponseWriter, r *http.Request, _ httprout
 	}
 
 	input := usecase.CreateOrderInput{
-		UserID:   req.UserID,
-		BookID:   req.BookID,
-		Quantity: req.Quantity,
+		UserID:        req.UserID,
+		BookID:        req.BookID,
+		Quantity:      req.Quantity,
+		PromotionCode: req.PromotionCode,
+
+		ShippingAddressID: req.ShippingAddressID,
+		BillingAddressID:  req.BillingAddressID,
+		ShippingMethod:    req.ShippingMethod,
 	}
 
 	output, err := h.orderUsecase.Create(r.Context(), input)
@@ -91,22 +133,23 @@ func (h *OrderHandler) FindByID(w http.ResponseWriter, r *http.Request, ps httpr
 
 // FindAll handles GET /orders.
 func (h *OrderHandler) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	outputs, err := h.orderUsecase.FindAll(r.Context())
+	page, ok := helper.ReadPage(w, r)
+	if !ok {
+		return
+	}
+
+	outputs, total, err := h.orderUsecase.FindAll(r.Context(), page)
 	if err != nil {
 		helper.WriteErrorFromDomain(w, err)
 		return
 	}
 
-	var responses []OrderResponse
-	for _, output := range outputs {
-		responses = append(responses, toOrderResponse(output))
+	responses := make([]OrderResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toOrderResponse(output)
 	}
 
-	helper.WriteJSON(w, http.StatusOK, helper.Response{
-		Code:   http.StatusOK,
-		Status: "success",
-		Data:   responses,
-	})
+	helper.WritePage(w, r, responses, page, total)
 }
 
 // FindByUserID handles GET /users/:userId/orders.
@@ -123,26 +166,225 @@ func (h *OrderHandler) FindByUserID(w http.ResponseWriter, r *http.Request, ps h
 		return
 	}
 
-	var responses []OrderResponse
-	for _, output := range outputs {
-		responses = append(responses, toOrderResponse(output))
+	responses := make([]OrderResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toOrderResponse(output)
 	}
 
+	helper.WriteList(w, r, responses)
+}
+
+// Complete handles POST /orders/:id/complete.
+func (h *OrderHandler) Complete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.orderUsecase.Complete(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toOrderResponse(output)
 	helper.WriteJSON(w, http.StatusOK, helper.Response{
 		Code:   http.StatusOK,
 		Status: "success",
-		Data:   responses,
+		Data:   resp,
+	})
+}
+
+// Pay handles POST /orders/:id/payments.
+func (h *OrderHandler) Pay(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	var req PayOrderRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	output, err := h.orderUsecase.Pay(r.Context(), usecase.PayOrderInput{
+		OrderID:      uint(id),
+		PaymentToken: req.PaymentToken,
+	})
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toPaymentResponse(output)
+	helper.WriteJSON(w, http.StatusCreated, helper.Response{
+		Code:   http.StatusCreated,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// CapturePayment handles POST /orders/:id/payments/capture.
+func (h *OrderHandler) CapturePayment(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.orderUsecase.CapturePayment(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toOrderResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Payments handles GET /orders/:id/payments.
+func (h *OrderHandler) Payments(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	outputs, err := h.orderUsecase.Payments(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]PaymentResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toPaymentResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Cancel handles POST /orders/:id/cancel.
+func (h *OrderHandler) Cancel(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.orderUsecase.Cancel(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toOrderResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Delete handles DELETE /orders/:id.
+func (h *OrderHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	if err := h.orderUsecase.Delete(r.Context(), uint(id)); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   nil,
+	})
+}
+
+// FindDeleted handles GET /admin/orders/deleted.
+func (h *OrderHandler) FindDeleted(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs, err := h.orderUsecase.FindDeleted(r.Context())
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]OrderResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toOrderResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Restore handles POST /admin/orders/:id/restore.
+func (h *OrderHandler) Restore(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	output, err := h.orderUsecase.Restore(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toOrderResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
 	})
 }
 
 // toOrderResponse converts usecase output to HTTP response.
 func toOrderResponse(output usecase.OrderOutput) OrderResponse {
 	return OrderResponse{
-		ID:       output.ID,
-		UserID:   output.UserID,
-		BookID:   output.BookID,
-		Quantity: output.Quantity,
-		Total:    output.Total,
-		Status:   output.Status,
+		ID:             output.ID,
+		UserID:         output.UserID,
+		BookID:         output.BookID,
+		Quantity:       output.Quantity,
+		UnitPrice:      output.UnitPrice,
+		Subtotal:       output.Subtotal,
+		Tax:            output.Tax,
+		ShippingMethod: output.ShippingMethod,
+		ShippingCost:   output.ShippingCost,
+		Total:          output.Total,
+		Discount:       output.Discount,
+		PromotionCode:  output.PromotionCode,
+		RefundedAmount: output.RefundedAmount,
+		Status:         output.Status,
+		DeletedAt:      output.DeletedAt,
+
+		ShippingAddress: toOrderAddressResponse(output.ShippingAddress),
+		BillingAddress:  toOrderAddressResponse(output.BillingAddress),
+	}
+}
+
+// toPaymentResponse converts usecase output to HTTP response.
+func toPaymentResponse(output usecase.PaymentOutput) PaymentResponse {
+	return PaymentResponse{
+		ID:             output.ID,
+		OrderID:        output.OrderID,
+		Amount:         output.Amount,
+		Status:         output.Status,
+		Reference:      output.Reference,
+		FailureReason:  output.FailureReason,
+		RefundedAmount: output.RefundedAmount,
+		CreatedAt:      output.CreatedAt,
 	}
 }
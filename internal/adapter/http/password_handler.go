@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// PasswordHandler handles HTTP requests for password resets.
+type PasswordHandler struct {
+	passwordUsecase *usecase.PasswordUsecase
+}
+
+// NewPasswordHandler creates a new PasswordHandler.
+func NewPasswordHandler(passwordUsecase *usecase.PasswordUsecase) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUsecase: passwordUsecase,
+	}
+}
+
+// ForgotPasswordRequest is the request body for requesting a password reset.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest is the request body for resetting a password.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// MessageResponse is the response body for operations that only return a message.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// Forgot handles POST /password/forgot.
+func (h *PasswordHandler) Forgot(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req ForgotPasswordRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.ForgotPasswordInput{
+		Email: req.Email,
+	}
+
+	if err := h.passwordUsecase.Forgot(r.Context(), input); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	// Same response whether or not the email exists
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data: MessageResponse{
+			Message: "if the email is registered, a reset link has been sent",
+		},
+	})
+}
+
+// Reset handles POST /password/reset.
+func (h *PasswordHandler) Reset(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req ResetPasswordRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.ResetPasswordInput{
+		Token:    req.Token,
+		Password: req.Password,
+	}
+
+	if err := h.passwordUsecase.Reset(r.Context(), input); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data: MessageResponse{
+			Message: "password has been reset",
+		},
+	})
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// PromotionHandler handles HTTP requests for promotions.
+type PromotionHandler struct {
+	promotionUsecase *usecase.PromotionUsecase
+}
+
+// NewPromotionHandler creates a new PromotionHandler.
+func NewPromotionHandler(promotionUsecase *usecase.PromotionUsecase) *PromotionHandler {
+	return &PromotionHandler{
+		promotionUsecase: promotionUsecase,
+	}
+}
+
+// PromotionRequest is the request body for creating or updating a promotion.
+type PromotionRequest struct {
+	Code         string     `json:"code"`
+	Description  string     `json:"description"`
+	Type         string     `json:"type"`
+	Value        float64    `json:"value"`
+	MinSpend     float64    `json:"min_spend"`
+	UsageLimit   int        `json:"usage_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	BookID       *uint      `json:"book_id"`
+	Category     string     `json:"category"`
+}
+
+// PromotionResponse is the response body for promotion operations.
+type PromotionResponse struct {
+	ID           uint       `json:"id"`
+	Code         string     `json:"code"`
+	Description  string     `json:"description,omitempty"`
+	Type         string     `json:"type"`
+	Value        float64    `json:"value"`
+	MinSpend     float64    `json:"min_spend"`
+	UsageLimit   int        `json:"usage_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	UsedCount    int        `json:"used_count"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	BookID       *uint      `json:"book_id,omitempty"`
+	Category     string     `json:"category,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Create handles POST /admin/promotions.
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req PromotionRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.CreatePromotionInput{
+		Code:         req.Code,
+		Description:  req.Description,
+		Type:         req.Type,
+		Value:        req.Value,
+		MinSpend:     req.MinSpend,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		BookID:       req.BookID,
+		Category:     req.Category,
+	}
+
+	output, err := h.promotionUsecase.Create(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toPromotionResponse(output)
+	helper.WriteJSON(w, http.StatusCreated, helper.Response{
+		Code:   http.StatusCreated,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// FindAll handles GET /admin/promotions.
+func (h *PromotionHandler) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs, err := h.promotionUsecase.FindAll(r.Context())
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]PromotionResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toPromotionResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// FindByID handles GET /admin/promotions/:id.
+func (h *PromotionHandler) FindByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	output, err := h.promotionUsecase.FindByID(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toPromotionResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Update handles PUT /admin/promotions/:id.
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	var req PromotionRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.UpdatePromotionInput{
+		ID:           uint(id),
+		Code:         req.Code,
+		Description:  req.Description,
+		Type:         req.Type,
+		Value:        req.Value,
+		MinSpend:     req.MinSpend,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		BookID:       req.BookID,
+		Category:     req.Category,
+	}
+
+	output, err := h.promotionUsecase.Update(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toPromotionResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Delete handles DELETE /admin/promotions/:id.
+func (h *PromotionHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	if err := h.promotionUsecase.Delete(r.Context(), uint(id)); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   nil,
+	})
+}
+
+// toPromotionResponse converts usecase output to HTTP response.
+func toPromotionResponse(output usecase.PromotionOutput) PromotionResponse {
+	return PromotionResponse{
+		ID:           output.ID,
+		Code:         output.Code,
+		Description:  output.Description,
+		Type:         output.Type,
+		Value:        output.Value,
+		MinSpend:     output.MinSpend,
+		UsageLimit:   output.UsageLimit,
+		PerUserLimit: output.PerUserLimit,
+		UsedCount:    output.UsedCount,
+		StartsAt:     output.StartsAt,
+		EndsAt:       output.EndsAt,
+		BookID:       output.BookID,
+		Category:     output.Category,
+		CreatedAt:    output.CreatedAt,
+	}
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ReturnHandler handles HTTP requests for order returns.
+type ReturnHandler struct {
+	returnUsecase *usecase.ReturnUsecase
+}
+
+// NewReturnHandler creates a new ReturnHandler.
+func NewReturnHandler(returnUsecase *usecase.ReturnUsecase) *ReturnHandler {
+	return &ReturnHandler{
+		returnUsecase: returnUsecase,
+	}
+}
+
+// CreateReturnRequest is the request body for requesting a return.
+type CreateReturnRequest struct {
+	Quantity int    `json:"quantity"`
+	Reason   string `json:"reason"`
+}
+
+// ApproveReturnRequest is the request body for approving a return.
+type ApproveReturnRequest struct {
+	Condition string  `json:"condition"`
+	Amount    float64 `json:"amount"`
+}
+
+// RejectReturnRequest is the request body for rejecting a return.
+type RejectReturnRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ReturnResponse is the response body for a return request.
+type ReturnResponse struct {
+	ID              uint      `json:"id"`
+	OrderID         uint      `json:"order_id"`
+	Quantity        int       `json:"quantity"`
+	Reason          string    `json:"reason,omitempty"`
+	Status          string    `json:"status"`
+	Condition       string    `json:"condition,omitempty"`
+	RefundAmount    float64   `json:"refund_amount"`
+	RejectionReason string    `json:"rejection_reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Request handles POST /orders/:id/returns.
+func (h *ReturnHandler) Request(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	var req CreateReturnRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.RequestReturnInput{
+		OrderID:  uint(id),
+		Quantity: req.Quantity,
+		Reason:   req.Reason,
+	}
+
+	output, err := h.returnUsecase.Request(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toReturnResponse(output)
+	helper.WriteJSON(w, http.StatusCreated, helper.Response{
+		Code:   http.StatusCreated,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// FindByOrderID handles GET /orders/:id/returns.
+func (h *ReturnHandler) FindByOrderID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+
+	outputs, err := h.returnUsecase.FindByOrderID(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteList(w, r, toReturnResponses(outputs))
+}
+
+// FindAll handles GET /admin/returns.
+// An optional status query parameter filters the returns, e.g. status=requested.
+func (h *ReturnHandler) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	page, ok := helper.ReadPage(w, r)
+	if !ok {
+		return
+	}
+
+	outputs, total, err := h.returnUsecase.FindByStatus(r.Context(), r.URL.Query().Get("status"), page)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WritePage(w, r, toReturnResponses(outputs), page, total)
+}
+
+// Approve handles POST /admin/returns/:id/approve.
+func (h *ReturnHandler) Approve(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid return id")
+		return
+	}
+
+	var req ApproveReturnRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.ApproveReturnInput{
+		ReturnID:  uint(id),
+		Condition: req.Condition,
+		Amount:    req.Amount,
+	}
+
+	output, err := h.returnUsecase.Approve(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toReturnResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// Reject handles POST /admin/returns/:id/reject.
+func (h *ReturnHandler) Reject(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid return id")
+		return
+	}
+
+	var req RejectReturnRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.RejectReturnInput{
+		ReturnID: uint(id),
+		Reason:   req.Reason,
+	}
+
+	output, err := h.returnUsecase.Reject(r.Context(), input)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toReturnResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// toReturnResponses converts usecase outputs to HTTP responses.
+func toReturnResponses(outputs []usecase.ReturnOutput) []ReturnResponse {
+	responses := make([]ReturnResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toReturnResponse(output)
+	}
+	return responses
+}
+
+// toReturnResponse converts usecase output to HTTP response.
+func toReturnResponse(output usecase.ReturnOutput) ReturnResponse {
+	return ReturnResponse{
+		ID:              output.ID,
+		OrderID:         output.OrderID,
+		Quantity:        output.Quantity,
+		Reason:          output.Reason,
+		Status:          output.Status,
+		Condition:       output.Condition,
+		RefundAmount:    output.RefundAmount,
+		RejectionReason: output.RejectionReason,
+		CreatedAt:       output.CreatedAt,
+	}
+}
@@ -6,9 +6,25 @@ import (
 
 // Router holds all HTTP handlers and creates routes.
 type Router struct {
-	bookHandler  *BookHandler
-	userHandler  *UserHandler
-	orderHandler *OrderHandler
+	bookHandler       *BookHandler
+	userHandler       *UserHandler
+	orderHandler      *OrderHandler
+	passwordHandler   *PasswordHandler
+	inventoryHandler  *InventoryHandler
+	webhookHandler    *WebhookHandler
+	returnHandler     *ReturnHandler
+	promotionHandler  *PromotionHandler
+	addressHandler    *AddressHandler
+	fulfilmentHandler *FulfilmentHandler
+	docsHandler       *DocsHandler
+	idempotency       *IdempotencyMiddleware
+}
+
+// Route is a single method and path served by the router.
+type Route struct {
+	Method string
+	Path   string
+	Handle httprouter.Handle
 }
 
 // NewRouter creates a new Router with all handlers.
@@ -16,11 +32,110 @@ func NewRouter(
 	bookHandler *BookHandler,
 	userHandler *UserHandler,
 	orderHandler *OrderHandler,
+	passwordHandler *PasswordHandler,
+	inventoryHandler *InventoryHandler,
+	webhookHandler *WebhookHandler,
+	returnHandler *ReturnHandler,
+	promotionHandler *PromotionHandler,
+	addressHandler *AddressHandler,
+	fulfilmentHandler *FulfilmentHandler,
+	docsHandler *DocsHandler,
+	idempotency *IdempotencyMiddleware,
 ) *Router {
 	return &Router{
-		bookHandler:  bookHandler,
-		userHandler:  userHandler,
-		orderHandler: orderHandler,
+		bookHandler:       bookHandler,
+		userHandler:       userHandler,
+		orderHandler:      orderHandler,
+		passwordHandler:   passwordHandler,
+		inventoryHandler:  inventoryHandler,
+		webhookHandler:    webhookHandler,
+		returnHandler:     returnHandler,
+		promotionHandler:  promotionHandler,
+		addressHandler:    addressHandler,
+		fulfilmentHandler: fulfilmentHandler,
+		docsHandler:       docsHandler,
+		idempotency:       idempotency,
+	}
+}
+
+// Routes returns every route served by the API.
+// Each one must be documented in apispec.json.
+func (r *Router) Routes() []Route {
+	return []Route{
+		// Auth routes
+		{"POST", "/register", r.userHandler.Register},
+		{"POST", "/login", r.userHandler.Login},
+		{"GET", "/verify-email", r.userHandler.VerifyEmail},
+		{"POST", "/verify-email/resend", r.userHandler.ResendVerification},
+		{"POST", "/password/forgot", r.passwordHandler.Forgot},
+		{"POST", "/password/reset", r.passwordHandler.Reset},
+
+		// Book routes
+		{"POST", "/books", r.bookHandler.Create},
+		{"GET", "/books", r.bookHandler.FindAll},
+		{"GET", "/books/:id", r.bookHandler.FindByID},
+		{"PUT", "/books/:id", r.bookHandler.Update},
+		{"DELETE", "/books/:id", r.bookHandler.Delete},
+		{"GET", "/books/:id/price-history", r.bookHandler.PriceHistory},
+		{"POST", "/books/:id/prices", r.bookHandler.SchedulePrice},
+
+		// Inventory routes
+		{"GET", "/books/:id/stock-history", r.inventoryHandler.StockHistory},
+		{"POST", "/books/:id/stock/adjust", r.inventoryHandler.Adjust},
+		{"POST", "/inventory/shipments", r.inventoryHandler.ReceiveShipment},
+		{"GET", "/inventory/low-stock", r.inventoryHandler.LowStock},
+
+		// Order routes, creation and payment are retried by clients on timeouts
+		{"POST", "/orders", r.idempotency.Wrap(r.orderHandler.Create)},
+		{"GET", "/orders", r.orderHandler.FindAll},
+		{"GET", "/orders/:id", r.orderHandler.FindByID},
+		{"DELETE", "/orders/:id", r.orderHandler.Delete},
+		{"POST", "/orders/:id/complete", r.orderHandler.Complete},
+		{"POST", "/orders/:id/cancel", r.orderHandler.Cancel},
+		{"GET", "/orders/:id/payments", r.orderHandler.Payments},
+		{"POST", "/orders/:id/payments", r.idempotency.Wrap(r.orderHandler.Pay)},
+		{"POST", "/orders/:id/payments/capture", r.orderHandler.CapturePayment},
+		{"GET", "/users/:userId/orders", r.orderHandler.FindByUserID},
+
+		// Fulfilment routes, for orders placed with a shipping method
+		{"GET", "/shipping-methods", r.fulfilmentHandler.Methods},
+		{"POST", "/orders/:id/pack", r.fulfilmentHandler.Pack},
+		{"POST", "/orders/:id/ship", r.fulfilmentHandler.Ship},
+		{"POST", "/orders/:id/deliver", r.fulfilmentHandler.Deliver},
+		{"GET", "/orders/:id/tracking", r.fulfilmentHandler.Tracking},
+
+		// Return routes
+		{"POST", "/orders/:id/returns", r.returnHandler.Request},
+		{"GET", "/orders/:id/returns", r.returnHandler.FindByOrderID},
+
+		// Webhook routes, signed by the payment provider
+		{"POST", "/webhooks/payments", r.webhookHandler.Payments},
+
+		// User routes
+		{"DELETE", "/users/:id", r.userHandler.Delete},
+
+		// Address book routes
+		{"POST", "/users/:userId/addresses", r.addressHandler.Create},
+		{"GET", "/users/:userId/addresses", r.addressHandler.FindByUserID},
+		{"GET", "/addresses/:id", r.addressHandler.FindByID},
+		{"PUT", "/addresses/:id", r.addressHandler.Update},
+		{"DELETE", "/addresses/:id", r.addressHandler.Delete},
+
+		// Admin routes
+		{"GET", "/admin/books/deleted", r.bookHandler.FindDeleted},
+		{"POST", "/admin/books/:id/restore", r.bookHandler.Restore},
+		{"GET", "/admin/users/deleted", r.userHandler.FindDeleted},
+		{"POST", "/admin/users/:id/restore", r.userHandler.Restore},
+		{"GET", "/admin/orders/deleted", r.orderHandler.FindDeleted},
+		{"POST", "/admin/orders/:id/restore", r.orderHandler.Restore},
+		{"GET", "/admin/returns", r.returnHandler.FindAll},
+		{"POST", "/admin/returns/:id/approve", r.returnHandler.Approve},
+		{"POST", "/admin/returns/:id/reject", r.returnHandler.Reject},
+		{"POST", "/admin/promotions", r.promotionHandler.Create},
+		{"GET", "/admin/promotions", r.promotionHandler.FindAll},
+		{"GET", "/admin/promotions/:id", r.promotionHandler.FindByID},
+		{"PUT", "/admin/promotions/:id", r.promotionHandler.Update},
+		{"DELETE", "/admin/promotions/:id", r.promotionHandler.Delete},
 	}
 }
 
@@ -28,22 +143,13 @@ func NewRouter(
 func (r *Router) Setup() *httprouter.Router {
 	router := httprouter.New()
 
-	// Auth routes
-	router.POST("/register", r.userHandler.Register)
-	router.POST("/login", r.userHandler.Login)
-
-	// Book routes
-	router.POST("/books", r.bookHandler.Create)
-	router.GET("/books", r.bookHandler.FindAll)
-	router.GET("/books/:id", r.bookHandler.FindByID)
-	router.PUT("/books/:id", r.bookHandler.Update)
-	router.DELETE("/books/:id", r.bookHandler.Delete)
-
-	// Order routes
-	router.POST("/orders", r.orderHandler.Create)
-	router.GET("/orders", r.orderHandler.FindAll)
-	router.GET("/orders/:id", r.orderHandler.FindByID)
-	router.GET("/users/:userId/orders", r.orderHandler.FindByUserID)
+	for _, route := range r.Routes() {
+		router.Handle(route.Method, route.Path, withUser(route.Handle))
+	}
+
+	// Documentation routes, /docs redirects to /docs/
+	router.GET("/openapi.json", r.docsHandler.OpenAPI)
+	router.GET("/docs/*filepath", r.docsHandler.UI)
 
 	return router
 }
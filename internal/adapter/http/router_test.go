@@ -0,0 +1,1091 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	bookshop "kikukafandi/book-shop-api"
+	httpAdapter "kikukafandi/book-shop-api/internal/adapter/http"
+	"kikukafandi/book-shop-api/internal/adapter/mail"
+	"kikukafandi/book-shop-api/internal/adapter/notify"
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/adapter/shipping"
+	"kikukafandi/book-shop-api/internal/adapter/tax"
+	"kikukafandi/book-shop-api/internal/config"
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// webhookSecret signs payment webhooks sent to the test router.
+const webhookSecret = "test-webhook-secret"
+
+// newTestRouter wires the full Router against a fresh in-memory SQLite database.
+func newTestRouter(t *testing.T) *httpAdapter.Router {
+	t.Helper()
+
+	database, err := config.NewDatabase(config.DatabaseConfig{
+		Driver:   config.DriverSQLite,
+		Path:     ":memory:",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := config.AutoMigrate(database); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	mailer := mail.NewLogMailer(t.TempDir() + "/mail.log")
+
+	repos := config.NewRepositories(config.DriverSQLite, database)
+	bookRepo := repos.Books
+	userRepo := repos.Users
+	orderRepo := repos.Orders
+	passwordResetRepo := repos.PasswordResets
+
+	pricingService := usecase.NewPricingService(repos.BookPrices, bookRepo)
+	bookUsecase := usecase.NewBookUsecase(bookRepo, orderRepo, pricingService)
+	userUsecase := usecase.NewUserUsecase(userRepo, orderRepo, mailer, usecase.VerificationConfig{
+		Secret:         "test-secret",
+		AppURL:         "http://localhost",
+		TokenTTL:       time.Hour,
+		ResendInterval: time.Minute,
+	})
+	gateway := payment.NewFakeGateway()
+	// Stationery is taxed on top of its price; books are not taxed
+	taxes, err := tax.NewRuleTable([]domain.TaxRule{{Rate: 0}, {Category: "stationery", Rate: 10}})
+	if err != nil {
+		t.Fatalf("tax rules: %v", err)
+	}
+	// Standard shipping is rated by weight, express by the number of copies
+	rates, err := shipping.NewRateTable([]domain.ShippingMethod{
+		{Code: "standard", Basis: domain.ShippingBasisWeight, Rates: []domain.ShippingRate{{From: 0, Cost: 5}, {From: 1000, Cost: 8}}},
+		{Code: "express", Basis: domain.ShippingBasisQuantity, Rates: []domain.ShippingRate{{From: 1, Cost: 15}, {From: 3, Cost: 25}}},
+	})
+	if err != nil {
+		t.Fatalf("shipping methods: %v", err)
+	}
+	orderUsecase := usecase.NewOrderUsecase(orderRepo, bookRepo, userRepo, repos.Reservations, repos.Payments, repos.Promotions, repos.Addresses, pricingService, taxes, rates, gateway, notify.NewLogNotifier(), mailer, usecase.OrderConfig{
+		ReservationTTL:          time.Hour,
+		BackorderReservationTTL: 72 * time.Hour,
+		TaxRegion:               "ID",
+	})
+	passwordUsecase := usecase.NewPasswordUsecase(userRepo, passwordResetRepo, mailer, "http://localhost", time.Hour)
+	inventoryUsecase := usecase.NewInventoryUsecase(bookRepo, repos.StockMovements, orderUsecase, pricingService)
+
+	return httpAdapter.NewRouter(
+		httpAdapter.NewBookHandler(bookUsecase, pricingService),
+		httpAdapter.NewUserHandler(userUsecase),
+		httpAdapter.NewOrderHandler(orderUsecase),
+		httpAdapter.NewPasswordHandler(passwordUsecase),
+		httpAdapter.NewInventoryHandler(inventoryUsecase),
+		httpAdapter.NewWebhookHandler(usecase.NewWebhookUsecase(repos.WebhookEvents, orderUsecase, usecase.WebhookConfig{
+			Secret:    webhookSecret,
+			Tolerance: 5 * time.Minute,
+		})),
+		httpAdapter.NewReturnHandler(usecase.NewReturnUsecase(repos.ReturnRequests, orderRepo, bookRepo, repos.Payments, gateway, orderUsecase)),
+		httpAdapter.NewPromotionHandler(usecase.NewPromotionUsecase(repos.Promotions, bookRepo)),
+		httpAdapter.NewAddressHandler(usecase.NewAddressUsecase(repos.Addresses, userRepo)),
+		httpAdapter.NewFulfilmentHandler(usecase.NewFulfilmentUsecase(orderRepo, repos.OrderShipments, rates)),
+		httpAdapter.NewDocsHandler(bookshop.APISpec),
+		httpAdapter.NewIdempotencyMiddleware(usecase.NewIdempotencyUsecase(repos.IdempotencyKeys, time.Hour)),
+	)
+}
+
+// newTestServer serves the full Router over httptest. Its client checks
+// every request and response against apispec.json.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(newTestRouter(t).Setup())
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	client.Transport = &specTransport{t: t, base: client.Transport}
+	return server
+}
+
+// apiResponse is a decoded helper.Response, helper.ListResponse or helper.ErrorResponse.
+type apiResponse struct {
+	Code    int              `json:"code"`
+	Status  string           `json:"status"`
+	Message string           `json:"message"`
+	Data    json.RawMessage  `json:"data"`
+	Meta    helper.ListMeta  `json:"meta"`
+	Links   helper.ListLinks `json:"links"`
+}
+
+// do sends a JSON request and decodes the response envelope.
+func do(t *testing.T, server *httptest.Server, method, path string, body interface{}, headers map[string]string) (*http.Response, apiResponse) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("decode %s %s: %v", method, path, err)
+	}
+
+	return resp, decoded
+}
+
+func expectStatus(t *testing.T, resp *http.Response, decoded apiResponse, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		t.Fatalf("%s %s: expected %d, got %d (%s)", resp.Request.Method, resp.Request.URL.Path, want, resp.StatusCode, decoded.Message)
+	}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	server := newTestServer(t)
+
+	register := map[string]string{"name": "Kikuk", "email": "kikuk@example.com", "password": "secret123", "role": "customer"}
+	resp, body := do(t, server, http.MethodPost, "/register", register, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/register", register, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	login := map[string]string{"email": "kikuk@example.com", "password": "secret123"}
+	resp, body = do(t, server, http.MethodPost, "/login", login, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	login["password"] = "wrong"
+	resp, body = do(t, server, http.MethodPost, "/login", login, nil)
+	expectStatus(t, resp, body, http.StatusUnauthorized)
+}
+
+func TestBookLifecycleWithETags(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("expected ETag \"1\", got %q", etag)
+	}
+
+	update := map[string]interface{}{"title": "Go 2", "price": 12}
+	resp, body = do(t, server, http.MethodPut, "/books/1", update, map[string]string{"If-Match": etag})
+	expectStatus(t, resp, body, http.StatusOK)
+	if resp.Header.Get("ETag") != `"2"` {
+		t.Fatalf("expected ETag \"2\", got %q", resp.Header.Get("ETag"))
+	}
+
+	// The old ETag is now stale
+	resp, body = do(t, server, http.MethodPut, "/books/1", update, map[string]string{"If-Match": etag})
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+
+	resp, body = do(t, server, http.MethodDelete, "/books/1", nil, map[string]string{"If-Match": etag})
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+
+	resp, body = do(t, server, http.MethodDelete, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	resp, body = do(t, server, http.MethodPost, "/admin/books/1/restore", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+}
+
+func TestOrderReservesStockAndProtectsBook(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123", "role": "customer"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	order := map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}
+	resp, body = do(t, server, http.MethodPost, "/orders", order, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	var created httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &created)
+	if created.Total != 20 || created.Status != "pending" {
+		t.Fatalf("unexpected order: %+v", created)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders", order, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	// Pending orders hold stock without taking it
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 2 || book.Reserved != 2 || book.Available != 0 {
+		t.Fatalf("expected 2 on hand, all reserved, got %+v", book)
+	}
+
+	// Books and users with unfinished orders cannot be deleted
+	resp, body = do(t, server, http.MethodDelete, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodDelete, "/users/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	// Unpaid orders cannot be completed
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusPaymentRequired)
+
+	payOrder(t, server, 1)
+	resp, body = do(t, server, http.MethodDelete, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/cancel", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 0 || book.Reserved != 0 || book.Available != 0 {
+		t.Fatalf("expected completed order to take the stock, got %+v", book)
+	}
+
+	// A book that has sold can be retired
+	resp, body = do(t, server, http.MethodDelete, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+}
+
+func TestOrderPaymentIsAuthorizedThenCaptured(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": ""}, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments/capture", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	// The fake provider declines and times out on request
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": "tok_decline"}, nil)
+	expectStatus(t, resp, body, http.StatusPaymentRequired)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": "tok_timeout"}, nil)
+	expectStatus(t, resp, body, http.StatusGatewayTimeout)
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": "tok_visa"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var authorized httpAdapter.PaymentResponse
+	_ = json.Unmarshal(body.Data, &authorized)
+	if authorized.Status != "authorized" || authorized.Amount != 20 {
+		t.Fatalf("unexpected payment: %+v", authorized)
+	}
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": "tok_visa"}, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1/payments", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var payments []httpAdapter.PaymentResponse
+	_ = json.Unmarshal(body.Data, &payments)
+	if len(payments) != 3 || payments[0].Status != "declined" || payments[1].Status != "failed" || payments[2].ID != authorized.ID {
+		t.Fatalf("expected every attempt to be recorded, got %+v", payments)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments/capture", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var paid httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &paid)
+	if paid.Status != "paid" {
+		t.Fatalf("expected paid order, got %+v", paid)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/orders/99/payments", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+}
+
+func TestPaymentWebhookCapturesOrder(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/payments", map[string]string{"payment_token": "tok_visa"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	var payment httpAdapter.PaymentResponse
+	_ = json.Unmarshal(body.Data, &payment)
+	event := httpAdapter.PaymentWebhookRequest{
+		ID:   "evt_1",
+		Type: "payment.captured",
+		Data: httpAdapter.PaymentWebhookData{PaymentID: payment.ID, Reference: payment.Reference},
+	}
+
+	// Unsigned, forged and stale webhooks are rejected
+	resp, body = sendWebhook(t, server, event, "t=1,v1=0")
+	expectStatus(t, resp, body, http.StatusUnauthorized)
+	resp, body = sendWebhook(t, server, event, signWebhook(t, "wrong-secret", time.Now(), event))
+	expectStatus(t, resp, body, http.StatusUnauthorized)
+	resp, body = sendWebhook(t, server, event, signWebhook(t, webhookSecret, time.Now().Add(-time.Hour), event))
+	expectStatus(t, resp, body, http.StatusUnauthorized)
+
+	signature := signWebhook(t, webhookSecret, time.Now(), event)
+	resp, body = sendWebhook(t, server, event, signature)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	_, body = do(t, server, http.MethodGet, "/orders/1", nil, nil)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Status != "paid" {
+		t.Fatalf("expected the captured order to be paid, got %+v", order)
+	}
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 3 || book.Reserved != 0 {
+		t.Fatalf("expected the paid order to take its stock, got %+v", book)
+	}
+
+	// Deliveries of a processed event change nothing
+	resp, body = sendWebhook(t, server, event, signature)
+	expectStatus(t, resp, body, http.StatusOK)
+	var message httpAdapter.MessageResponse
+	_ = json.Unmarshal(body.Data, &message)
+	if message.Message != "event already processed" {
+		t.Fatalf("expected duplicate to be acknowledged, got %q", message.Message)
+	}
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 3 {
+		t.Fatalf("expected stock to be taken once, got %+v", book)
+	}
+}
+
+// sendWebhook posts a payment webhook with a signature header.
+func sendWebhook(t *testing.T, server *httptest.Server, event httpAdapter.PaymentWebhookRequest, signature string) (*http.Response, apiResponse) {
+	t.Helper()
+	return do(t, server, http.MethodPost, "/webhooks/payments", event, map[string]string{httpAdapter.PaymentSignatureHeader: signature})
+}
+
+// signWebhook signs a webhook the way do will send it.
+func signWebhook(t *testing.T, secret string, timestamp time.Time, event httpAdapter.PaymentWebhookRequest) string {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return usecase.SignWebhook(secret, timestamp, payload)
+}
+
+func TestOrderReturnIsApprovedAndRefunded(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	// Only completed orders can be returned
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 1}, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	payOrder(t, server, 1)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 0}, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 1, "reason": "gift duplicate"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1/returns", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/admin/returns?status=requested", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var requested []httpAdapter.ReturnResponse
+	_ = json.Unmarshal(body.Data, &requested)
+	if len(requested) != 1 || requested[0].Reason != "gift duplicate" {
+		t.Fatalf("expected one requested return, got %+v", requested)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/admin/returns/1/approve", map[string]string{"condition": "sellable"}, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var approved httpAdapter.ReturnResponse
+	_ = json.Unmarshal(body.Data, &approved)
+	if approved.Status != "approved" || approved.RefundAmount != 10 {
+		t.Fatalf("expected the return to be refunded, got %+v", approved)
+	}
+
+	_, body = do(t, server, http.MethodGet, "/orders/1", nil, nil)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Status != "partially_refunded" || order.RefundedAmount != 10 {
+		t.Fatalf("expected a partially refunded order, got %+v", order)
+	}
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 4 {
+		t.Fatalf("expected the returned copy to be restocked, got %+v", book)
+	}
+
+	// The last copy cannot be returned twice, and a return is resolved once
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 1}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/admin/returns/2/reject", map[string]string{"reason": "outside the return window"}, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/admin/returns/2/reject", map[string]string{}, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/admin/returns/2/approve", map[string]string{"condition": "sellable"}, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+}
+
+func TestPromotionDiscountsOrders(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "category": "programming", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	promotion := map[string]interface{}{"code": "spring10", "type": "percentage", "value": 10, "min_spend": 20, "per_user_limit": 1, "category": "programming"}
+	resp, body = do(t, server, http.MethodPost, "/admin/promotions", promotion, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/admin/promotions", promotion, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/admin/promotions", map[string]interface{}{"code": "HALF", "type": "percentage", "value": 150}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	// The minimum spend is checked against the total before the discount
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1, "promotion_code": "SPRING10"}, nil)
+	expectStatus(t, resp, body, http.StatusUnprocessableEntity)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1, "promotion_code": "UNKNOWN"}, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 3, "promotion_code": "Spring10"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Total != 27 || order.Discount != 3 || order.PromotionCode != "SPRING10" {
+		t.Fatalf("expected a discounted order, got %+v", order)
+	}
+
+	// Each customer can use the promotion once, until the order is cancelled
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2, "promotion_code": "SPRING10"}, nil)
+	expectStatus(t, resp, body, http.StatusUnprocessableEntity)
+	resp, body = do(t, server, http.MethodGet, "/admin/promotions/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var used httpAdapter.PromotionResponse
+	_ = json.Unmarshal(body.Data, &used)
+	if used.Code != "SPRING10" || used.UsedCount != 1 {
+		t.Fatalf("expected the promotion to be used once, got %+v", used)
+	}
+	resp, body = do(t, server, http.MethodPost, "/orders/1/cancel", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2, "promotion_code": "SPRING10"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	// Promotions for other categories do not apply
+	promotion["category"] = "cooking"
+	resp, body = do(t, server, http.MethodPut, "/admin/promotions/1", promotion, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2, "promotion_code": "SPRING10"}, nil)
+	expectStatus(t, resp, body, http.StatusUnprocessableEntity)
+
+	resp, body = do(t, server, http.MethodGet, "/admin/promotions", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodDelete, "/admin/promotions/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/admin/promotions/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	// Orders keep the discount of a deleted promotion; order 2 went over
+	// the per-user limit and was discarded
+	_, body = do(t, server, http.MethodGet, "/orders/3", nil, nil)
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Discount != 2 || order.PromotionCode != "SPRING10" {
+		t.Fatalf("expected the order to keep its discount, got %+v", order)
+	}
+}
+
+func TestSalePricesOrdersAndPriceHistory(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	now := time.Now().UTC()
+	sale := map[string]interface{}{"type": "sale", "price": 7.5, "effective_to": now.Add(time.Hour)}
+	resp, body = do(t, server, http.MethodPost, "/books/1/prices", sale, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books/1/prices", sale, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/books/1/prices", map[string]interface{}{"type": "sale", "price": 5}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/books/1/prices", map[string]interface{}{"type": "regular", "price": 12, "effective_from": now.Add(-time.Hour)}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/books/9/prices", map[string]interface{}{"type": "regular", "price": 12}, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	// A price change scheduled for tomorrow leaves today's price alone
+	resp, body = do(t, server, http.MethodPost, "/books/1/prices", map[string]interface{}{"type": "regular", "price": 12, "effective_from": now.Add(24 * time.Hour)}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Price != 7.5 || book.RegularPrice != 10 || book.SaleEndsAt == nil {
+		t.Fatalf("expected the sale price, got %+v", book)
+	}
+
+	// Orders are charged the price customers see
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.UnitPrice != 7.5 || order.Total != 15 {
+		t.Fatalf("expected the order at the sale price, got %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/books/1/price-history", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var history []httpAdapter.BookPriceResponse
+	_ = json.Unmarshal(body.Data, &history)
+	if len(history) != 3 || history[0].Price != 10 || history[1].Price != 7.5 || history[2].Price != 12 {
+		t.Fatalf("expected the list price, the sale and the scheduled price, got %+v", history)
+	}
+	if history[0].EffectiveTo == nil || !history[0].EffectiveTo.Equal(history[2].EffectiveFrom) {
+		t.Fatalf("expected the list price to end when the scheduled price starts, got %+v", history[0])
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/books/9/price-history", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+}
+
+func TestOrdersAreTaxedByCategory(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Notebook", "category": "stationery", "price": 4.99, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Subtotal != 10 || order.Tax != 0 || order.Total != 10 {
+		t.Fatalf("expected an untaxed book, got %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 2, "quantity": 3}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodGet, "/orders/2", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Subtotal != 14.97 || order.Tax != 1.5 || order.Total != 16.47 {
+		t.Fatalf("expected tax on top of the stationery, got %+v", order)
+	}
+}
+
+// payOrder pays a pending order through the API so it can be completed.
+func TestAddressBookAndOrderAddresses(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	home := map[string]interface{}{"label": "home", "name": "Budi", "line1": "Jl. Sudirman 1", "city": "Jakarta", "region": "JK", "postal_code": "10210", "country": "id"}
+	resp, body = do(t, server, http.MethodPost, "/users/1/addresses", home, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var address httpAdapter.AddressResponse
+	_ = json.Unmarshal(body.Data, &address)
+	if address.ID != 1 || address.Country != "ID" {
+		t.Fatalf("expected a normalized address, got %+v", address)
+	}
+
+	// US addresses need a state and a ZIP code
+	office := map[string]interface{}{"label": "office", "name": "Budi", "line1": "1 Market St", "city": "San Francisco", "postal_code": "94105", "country": "US"}
+	resp, body = do(t, server, http.MethodPost, "/users/1/addresses", office, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	office["region"] = "CA"
+	resp, body = do(t, server, http.MethodPost, "/users/1/addresses", office, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/users/9/addresses", home, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	resp, body = do(t, server, http.MethodGet, "/users/1/addresses", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var addresses []httpAdapter.AddressResponse
+	_ = json.Unmarshal(body.Data, &addresses)
+	if len(addresses) != 2 || addresses[0].Label != "home" || addresses[1].Region != "CA" {
+		t.Fatalf("expected both addresses oldest first, got %+v", addresses)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1, "shipping_address_id": 1, "billing_address_id": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	// Editing and deleting addresses does not change the order
+	home["line1"] = "Jl. Thamrin 2"
+	resp, body = do(t, server, http.MethodPut, "/addresses/1", home, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/addresses/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	_ = json.Unmarshal(body.Data, &address)
+	if address.Line1 != "Jl. Thamrin 2" {
+		t.Fatalf("expected the address to be updated, got %+v", address)
+	}
+	resp, body = do(t, server, http.MethodDelete, "/addresses/2", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodGet, "/addresses/2", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.ShippingAddress == nil || order.ShippingAddress.Line1 != "Jl. Sudirman 1" {
+		t.Fatalf("expected the shipping address as it was, got %+v", order.ShippingAddress)
+	}
+	if order.BillingAddress == nil || order.BillingAddress.City != "San Francisco" {
+		t.Fatalf("expected the billing address as it was, got %+v", order.BillingAddress)
+	}
+}
+
+func TestOrderIsShippedAndTracked(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5, "weight": 600}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/users/1/addresses", map[string]interface{}{"name": "Budi", "line1": "Jl. Sudirman 1", "city": "Jakarta", "region": "JK", "postal_code": "10210", "country": "ID"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/shipping-methods", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var methods []httpAdapter.ShippingMethodResponse
+	_ = json.Unmarshal(body.Data, &methods)
+	if len(methods) != 2 || methods[0].Code != "standard" || methods[0].Basis != "weight" || len(methods[0].Rates) != 2 {
+		t.Fatalf("expected the configured methods, got %+v", methods)
+	}
+
+	// Shipping needs somewhere to ship to
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1, "shipping_method": "standard"}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1, "shipping_address_id": 1, "shipping_method": "pigeon"}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	// Two copies weigh 1200g, which reaches the second step of the standard rates
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2, "shipping_address_id": 1, "shipping_method": "Standard"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.ShippingMethod != "standard" || order.ShippingCost != 8 || order.Subtotal != 20 || order.Total != 28 {
+		t.Fatalf("expected shipping on top of the subtotal, got %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/pack", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	payOrder(t, server, order.ID)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1/tracking", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var tracking httpAdapter.TrackingResponse
+	_ = json.Unmarshal(body.Data, &tracking)
+	if tracking.Status != "paid" || tracking.PackedAt != nil || tracking.ShippingAddress == nil {
+		t.Fatalf("expected a paid order waiting to be packed, got %+v", tracking)
+	}
+
+	// Shipped orders are completed once delivered
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/pack", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/deliver", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/ship", map[string]string{"carrier": "JNE", "tracking_number": " "}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/ship", map[string]string{"carrier": "JNE", "tracking_number": "JNE0012345678"}, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/deliver", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodGet, "/orders/1/tracking", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	_ = json.Unmarshal(body.Data, &tracking)
+	if tracking.Status != "delivered" || tracking.Carrier != "JNE" || tracking.TrackingNumber != "JNE0012345678" || tracking.ShippedAt == nil || tracking.DeliveredAt == nil {
+		t.Fatalf("expected a delivered order with its carrier, got %+v", tracking)
+	}
+
+	// Delivered orders can be returned
+	resp, body = do(t, server, http.MethodPost, "/orders/1/returns", map[string]interface{}{"quantity": 1, "reason": "damaged"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	// Orders without a shipping method are not tracked
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 1}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	_ = json.Unmarshal(body.Data, &order)
+	resp, body = do(t, server, http.MethodGet, fmt.Sprintf("/orders/%d/tracking", order.ID), nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+}
+
+func payOrder(t *testing.T, server *httptest.Server, orderID uint) {
+	t.Helper()
+
+	path := fmt.Sprintf("/orders/%d/payments", orderID)
+	resp, body := do(t, server, http.MethodPost, path, map[string]string{"payment_token": "tok_visa"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, path+"/capture", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+}
+
+func TestForgotPasswordDoesNotRevealAccounts(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	_, known := do(t, server, http.MethodPost, "/password/forgot", map[string]string{"email": "a@example.com"}, nil)
+	_, unknown := do(t, server, http.MethodPost, "/password/forgot", map[string]string{"email": "nobody@example.com"}, nil)
+
+	if known.Code != http.StatusOK || string(known.Data) != string(unknown.Data) {
+		t.Fatalf("responses differ: %s vs %s", known.Data, unknown.Data)
+	}
+}
+
+func TestListsAreArraysWithPagination(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodGet, "/books", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	if string(body.Data) != "[]" {
+		t.Fatalf("expected an empty array, got %s", body.Data)
+	}
+
+	for _, title := range []string{"A", "B", "C"} {
+		resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": title, "price": 10, "stock": 1}, nil)
+		expectStatus(t, resp, body, http.StatusCreated)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/books?page=2&per_page=2", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var books []httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &books)
+	if len(books) != 1 || books[0].Title != "C" {
+		t.Fatalf("expected only book C on page 2, got %+v", books)
+	}
+	if body.Meta.Total != 3 || body.Meta.TotalPages != 2 || body.Meta.Count != 1 {
+		t.Fatalf("unexpected meta: %+v", body.Meta)
+	}
+	if body.Links.Prev != "/books?page=1&per_page=2" || body.Links.Next != "" {
+		t.Fatalf("unexpected links: %+v", body.Links)
+	}
+
+	// Pages past the end are empty and link back to the last page
+	resp, body = do(t, server, http.MethodGet, "/books?page=5&per_page=2", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	if string(body.Data) != "[]" || body.Meta.Total != 3 || body.Links.Prev != "/books?page=2&per_page=2" {
+		t.Fatalf("expected an empty page after the last, got %s, %+v, %+v", body.Data, body.Meta, body.Links)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/books?page=0", nil, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	resp, body = do(t, server, http.MethodGet, "/books?page=100000000000000000&per_page=100", nil, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+}
+
+func TestStockHistoryExplainsStock(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	payOrder(t, server, 1)
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1/stock-history", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var history []httpAdapter.StockMovementResponse
+	_ = json.Unmarshal(body.Data, &history)
+	if len(history) != 2 {
+		t.Fatalf("expected 2 movements, got %+v", history)
+	}
+	if order := history[1]; order.Reason != "order" || order.Delta != -2 || order.Balance != 3 || order.Reference != "order:1" {
+		t.Fatalf("unexpected order movement: %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/books/99/stock-history", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+}
+
+func TestStockAdjustmentsAreRelative(t *testing.T) {
+	server := newTestServer(t)
+
+	// Stock changes are attributed to the user the gateway signed in
+	staff := map[string]string{httpAdapter.UserIDHeader: "3"}
+	resp, body := do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, staff)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Rust", "price": 12, "stock": 0}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	// PUT can no longer set stock
+	update := map[string]interface{}{"title": "Go", "price": 10, "stock": 50}
+	resp, body = do(t, server, http.MethodPut, "/books/1", update, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	adjust := map[string]interface{}{"delta": -2, "reason": "damaged", "reference": "water damage"}
+	resp, body = do(t, server, http.MethodPost, "/books/1/stock/adjust", adjust, staff)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 3 || resp.Header.Get("ETag") != helper.ETag(book.Version) {
+		t.Fatalf("unexpected book %+v with ETag %q", book, resp.Header.Get("ETag"))
+	}
+
+	adjust = map[string]interface{}{"delta": 2, "reason": "lost"}
+	resp, body = do(t, server, http.MethodPost, "/books/1/stock/adjust", adjust, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	adjust = map[string]interface{}{"delta": -5, "reason": "adjustment"}
+	resp, body = do(t, server, http.MethodPost, "/books/1/stock/adjust", adjust, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+
+	resp, body = do(t, server, http.MethodPost, "/books/99/stock/adjust", map[string]interface{}{"delta": 1, "reason": "restock"}, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	shipment := map[string]interface{}{
+		"reference": "PO-1",
+		"items": []map[string]interface{}{
+			{"book_id": 1, "quantity": 10},
+			{"book_id": 2, "quantity": 4},
+		},
+	}
+	resp, body = do(t, server, http.MethodPost, "/inventory/shipments", shipment, staff)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var received httpAdapter.ShipmentResponse
+	_ = json.Unmarshal(body.Data, &received)
+	if len(received.Books) != 2 || received.Books[0].Stock != 13 || received.Books[1].Stock != 4 {
+		t.Fatalf("unexpected shipment: %+v", received)
+	}
+
+	shipment["items"] = []map[string]interface{}{{"book_id": 99, "quantity": 1}}
+	resp, body = do(t, server, http.MethodPost, "/inventory/shipments", shipment, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	resp, body = do(t, server, http.MethodGet, "/books/1/stock-history", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var history []httpAdapter.StockMovementResponse
+	_ = json.Unmarshal(body.Data, &history)
+	if len(history) != 3 || history[0].Reference != "initial stock" || history[1].Reason != "damaged" || history[2].Reference != "PO-1" ||
+		history[0].Actor != "user:3" || history[1].Actor != "user:3" || history[2].Actor != "user:3" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestCancelledOrderReleasesStock(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 3}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 3}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/cancel", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Status != "cancelled" {
+		t.Fatalf("expected cancelled order, got %+v", order)
+	}
+
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Stock != 3 || book.Available != 3 {
+		t.Fatalf("expected all stock available again, got %+v", book)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+	resp, body = do(t, server, http.MethodPost, "/orders/99/cancel", nil, nil)
+	expectStatus(t, resp, body, http.StatusNotFound)
+}
+
+func TestLowStockListsBooksBelowThreshold(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 6, "reorder_threshold": 5, "reorder_quantity": 20}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Rust", "price": 10, "stock": 1}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/inventory/low-stock", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	if body.Meta.Total != 0 {
+		t.Fatalf("expected no low-stock books yet, got %s", body.Data)
+	}
+
+	// Reserving stock for an order drops availability below the threshold
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodGet, "/inventory/low-stock", nil, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+	var books []httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &books)
+	if len(books) != 1 || books[0].ID != 1 || books[0].Available != 4 || books[0].ReorderQuantity != 20 {
+		t.Fatalf("expected only the reserved book, got %+v", books)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "C", "price": 10, "stock": 1, "reorder_threshold": -1}, map[string]string{invalidRequest: "1"})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+}
+
+func TestBackorderedOrderIsAllocatedOnShipment(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 1, "allow_backorder": true}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	resp, body = do(t, server, http.MethodPost, "/orders", map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 3}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var order httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Status != "backordered" {
+		t.Fatalf("expected backordered order, got %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders/1/complete", nil, nil)
+	expectStatus(t, resp, body, http.StatusConflict)
+
+	shipment := map[string]interface{}{"reference": "PO-1", "items": []map[string]int{{"book_id": 1, "quantity": 2}}}
+	resp, body = do(t, server, http.MethodPost, "/inventory/shipments", shipment, nil)
+	expectStatus(t, resp, body, http.StatusOK)
+
+	var received httpAdapter.ShipmentResponse
+	_ = json.Unmarshal(body.Data, &received)
+	if book := received.Books[0]; book.Stock != 3 || book.Reserved != 3 || book.Available != 0 {
+		t.Fatalf("expected the shipment to be reserved for the backorder, got %+v", book)
+	}
+
+	_, body = do(t, server, http.MethodGet, "/orders/1", nil, nil)
+	_ = json.Unmarshal(body.Data, &order)
+	if order.Status != "pending" {
+		t.Fatalf("expected allocated order to be pending, got %+v", order)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Rust", "price": 10, "stock": 0, "allow_preorder": true}, nil)
+	expectStatus(t, resp, body, http.StatusBadRequest)
+}
+
+func TestOrderCreationIsIdempotent(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, body := do(t, server, http.MethodPost, "/register", map[string]string{"name": "A", "email": "a@example.com", "password": "secret123"}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	resp, body = do(t, server, http.MethodPost, "/books", map[string]interface{}{"title": "Go", "price": 10, "stock": 5}, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+
+	order := map[string]interface{}{"user_id": 1, "book_id": 1, "quantity": 2}
+	key := map[string]string{httpAdapter.IdempotencyKeyHeader: "order-attempt-1"}
+
+	resp, body = do(t, server, http.MethodPost, "/orders", order, key)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var first httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &first)
+
+	// The retry gets the same order back without placing another one
+	resp, body = do(t, server, http.MethodPost, "/orders", order, key)
+	expectStatus(t, resp, body, http.StatusCreated)
+	var retried httpAdapter.OrderResponse
+	_ = json.Unmarshal(body.Data, &retried)
+	if retried.ID != first.ID || resp.Header.Get(httpAdapter.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected order %d to be replayed, got %+v", first.ID, retried)
+	}
+
+	_, body = do(t, server, http.MethodGet, "/books/1", nil, nil)
+	var book httpAdapter.BookResponse
+	_ = json.Unmarshal(body.Data, &book)
+	if book.Reserved != 2 {
+		t.Fatalf("expected stock to be reserved once, got %+v", book)
+	}
+
+	order["quantity"] = 3
+	resp, body = do(t, server, http.MethodPost, "/orders", order, key)
+	expectStatus(t, resp, body, http.StatusUnprocessableEntity)
+
+	// Without a key every request places an order
+	resp, body = do(t, server, http.MethodPost, "/orders", order, nil)
+	expectStatus(t, resp, body, http.StatusCreated)
+	if resp.Header.Get(httpAdapter.IdempotentReplayedHeader) != "" {
+		t.Fatal("expected a fresh response")
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/orders", order, map[string]string{
+		httpAdapter.IdempotencyKeyHeader: strings.Repeat("k", 256),
+		invalidRequest:                   "1",
+	})
+	expectStatus(t, resp, body, http.StatusBadRequest)
+}
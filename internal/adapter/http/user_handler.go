@@ -2,6 +2,8 @@ package http
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/helper"
 	"kikukafandi/book-shop-api/internal/usecase"
@@ -35,12 +37,19 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// ResendVerificationRequest is the request body for resending a verification email.
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
 // UserResponse is the response body for user operations.
 type UserResponse struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Verified  bool       `json:"verified"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Register handles POST /register.
@@ -99,12 +108,121 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request, _ httprouter
 	})
 }
 
+// VerifyEmail handles GET /verify-email.
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "missing token")
+		return
+	}
+
+	output, err := h.userUsecase.VerifyEmail(r.Context(), token)
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toUserResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
+// ResendVerification handles POST /verify-email/resend.
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req ResendVerificationRequest
+	if err := helper.ReadJSON(r, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := usecase.ResendVerificationInput{
+		Email: req.Email,
+	}
+
+	if err := h.userUsecase.ResendVerification(r.Context(), input); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	// Same response whether or not the email exists
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data: MessageResponse{
+			Message: "if the email is registered and unverified, a verification link has been sent",
+		},
+	})
+}
+
+// Delete handles DELETE /users/:id.
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	if err := h.userUsecase.Delete(r.Context(), uint(id)); err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   nil,
+	})
+}
+
+// FindDeleted handles GET /admin/users/deleted.
+func (h *UserHandler) FindDeleted(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	outputs, err := h.userUsecase.FindDeleted(r.Context())
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	responses := make([]UserResponse, len(outputs))
+	for i, output := range outputs {
+		responses[i] = toUserResponse(output)
+	}
+
+	helper.WriteList(w, r, responses)
+}
+
+// Restore handles POST /admin/users/:id/restore.
+func (h *UserHandler) Restore(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 32)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	output, err := h.userUsecase.Restore(r.Context(), uint(id))
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	resp := toUserResponse(output)
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   resp,
+	})
+}
+
 // toUserResponse converts usecase output to HTTP response.
 func toUserResponse(output usecase.UserOutput) UserResponse {
 	return UserResponse{
-		ID:    output.ID,
-		Name:  output.Name,
-		Email: output.Email,
-		Role:  output.Role,
+		ID:        output.ID,
+		Name:      output.Name,
+		Email:     output.Email,
+		Role:      output.Role,
+		Verified:  output.Verified,
+		DeletedAt: output.DeletedAt,
 	}
 }
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// UserIDHeader carries the ID of the user the authenticating gateway in
+// front of the API signed in. The API does not authenticate requests itself.
+const UserIDHeader = "X-User-ID"
+
+// withUser attributes the changes a request makes to the user named by
+// UserIDHeader. Requests without a valid user ID are passed through unchanged.
+func withUser(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		id, err := strconv.ParseUint(r.Header.Get(UserIDHeader), 10, 32)
+		if err == nil && id > 0 {
+			r = r.WithContext(usecase.WithUser(r.Context(), uint(id)))
+		}
+
+		next(w, r, ps)
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"kikukafandi/book-shop-api/internal/helper"
+	"kikukafandi/book-shop-api/internal/usecase"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// PaymentSignatureHeader carries the payment provider's signature of a webhook.
+const PaymentSignatureHeader = "Payment-Signature"
+
+// WebhookHandler handles webhooks sent by the payment provider.
+type WebhookHandler struct {
+	webhookUsecase *usecase.WebhookUsecase
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhookUsecase *usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// PaymentWebhookRequest is the body of a payment provider webhook.
+type PaymentWebhookRequest struct {
+	ID   string             `json:"id"`
+	Type string             `json:"type"`
+	Data PaymentWebhookData `json:"data"`
+}
+
+// PaymentWebhookData is the payment a PaymentWebhookRequest is about.
+type PaymentWebhookData struct {
+	PaymentID uint    `json:"payment_id"`
+	Reference string  `json:"reference"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+}
+
+// Payments handles POST /webhooks/payments.
+func (h *WebhookHandler) Payments(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	// The signature covers the body exactly as it was sent
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var req PaymentWebhookRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	output, err := h.webhookUsecase.Receive(r.Context(), usecase.ReceiveWebhookInput{
+		Payload:   payload,
+		Signature: r.Header.Get(PaymentSignatureHeader),
+		EventID:   req.ID,
+		Type:      req.Type,
+		PaymentID: req.Data.PaymentID,
+		Reference: req.Data.Reference,
+		Amount:    req.Data.Amount,
+		Reason:    req.Data.Reason,
+	})
+	if err != nil {
+		helper.WriteErrorFromDomain(w, err)
+		return
+	}
+
+	message := "event processed"
+	if output.Duplicate {
+		message = "event already processed"
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.Response{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data: MessageResponse{
+			Message: message,
+		},
+	})
+}
@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// LogMailer implements domain.Mailer by writing emails to a file or the log.
+// It is meant for local development and tests - nothing leaves the machine.
+type LogMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogMailer creates a new LogMailer.
+// If path is empty, emails are written to the standard logger.
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+// Send writes the email to the configured file or the log.
+func (m *LogMailer) Send(ctx context.Context, mail domain.Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf("--- %s\nTo: %s\nSubject: %s\n\n%s\n",
+		time.Now().Format(time.RFC3339),
+		mail.To,
+		mail.Subject,
+		mail.Body,
+	)
+
+	if m.path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// AddressRepositoryMemory implements domain.AddressRepository in memory.
+type AddressRepositoryMemory struct {
+	store *Store
+}
+
+// NewAddressRepositoryMemory creates a new AddressRepositoryMemory.
+func NewAddressRepositoryMemory(store *Store) *AddressRepositoryMemory {
+	return &AddressRepositoryMemory{store: store}
+}
+
+// Save saves an address.
+func (r *AddressRepositoryMemory) Save(_ context.Context, address domain.Address) (domain.Address, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	address.ID = r.store.nextID("addresses")
+	r.store.addresses[address.ID] = address
+
+	return address, nil
+}
+
+// Update updates an address.
+func (r *AddressRepositoryMemory) Update(_ context.Context, address domain.Address) (domain.Address, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.addresses[address.ID]; !ok {
+		return domain.Address{}, domain.ErrAddressNotFound
+	}
+	r.store.addresses[address.ID] = address
+
+	return address, nil
+}
+
+// FindByID finds an address by ID.
+func (r *AddressRepositoryMemory) FindByID(_ context.Context, id uint) (domain.Address, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	address, ok := r.store.addresses[id]
+	if !ok {
+		return domain.Address{}, domain.ErrAddressNotFound
+	}
+
+	return address, nil
+}
+
+// FindByUserID returns a user's addresses, oldest first.
+func (r *AddressRepositoryMemory) FindByUserID(_ context.Context, userID uint) ([]domain.Address, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	addresses := make([]domain.Address, 0)
+	for _, id := range sortedKeys(r.store.addresses) {
+		if address := r.store.addresses[id]; address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// Delete deletes an address.
+func (r *AddressRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.addresses[id]; !ok {
+		return domain.ErrAddressNotFound
+	}
+	delete(r.store.addresses, id)
+
+	return nil
+}
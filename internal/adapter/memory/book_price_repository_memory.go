@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// BookPriceRepositoryMemory implements domain.BookPriceRepository in memory.
+type BookPriceRepositoryMemory struct {
+	store *Store
+}
+
+// NewBookPriceRepositoryMemory creates a new BookPriceRepositoryMemory.
+func NewBookPriceRepositoryMemory(store *Store) *BookPriceRepositoryMemory {
+	return &BookPriceRepositoryMemory{store: store}
+}
+
+// Save saves a price.
+func (r *BookPriceRepositoryMemory) Save(_ context.Context, price domain.BookPrice) (domain.BookPrice, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	price.ID = r.store.nextID("book_prices")
+	r.store.bookPrices[price.ID] = price
+
+	return price, nil
+}
+
+// Update updates a price.
+func (r *BookPriceRepositoryMemory) Update(_ context.Context, price domain.BookPrice) (domain.BookPrice, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.bookPrices[price.ID] = price
+
+	return price, nil
+}
+
+// FindByBookID returns a book's price history ordered by EffectiveFrom, then ID.
+func (r *BookPriceRepositoryMemory) FindByBookID(_ context.Context, bookID uint) ([]domain.BookPrice, error) {
+	return r.filter(func(price domain.BookPrice) bool {
+		return price.BookID == bookID
+	}), nil
+}
+
+// FindEffective returns the prices of the given books in effect at a point in time.
+func (r *BookPriceRepositoryMemory) FindEffective(_ context.Context, bookIDs []uint, at time.Time) ([]domain.BookPrice, error) {
+	wanted := make(map[uint]bool, len(bookIDs))
+	for _, id := range bookIDs {
+		wanted[id] = true
+	}
+
+	return r.filter(func(price domain.BookPrice) bool {
+		return wanted[price.BookID] && price.IsEffective(at)
+	}), nil
+}
+
+// filter returns prices matching the predicate, ordered by EffectiveFrom, then ID.
+func (r *BookPriceRepositoryMemory) filter(match func(domain.BookPrice) bool) []domain.BookPrice {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	prices := make([]domain.BookPrice, 0)
+	for _, id := range sortedKeys(r.store.bookPrices) {
+		if price := r.store.bookPrices[id]; match(price) {
+			prices = append(prices, price)
+		}
+	}
+
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].EffectiveFrom.Before(prices[j].EffectiveFrom)
+	})
+
+	return prices
+}
@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// BookRepositoryMemory implements domain.BookRepository in memory.
+type BookRepositoryMemory struct {
+	store *Store
+}
+
+// NewBookRepositoryMemory creates a new BookRepositoryMemory.
+func NewBookRepositoryMemory(store *Store) *BookRepositoryMemory {
+	return &BookRepositoryMemory{store: store}
+}
+
+// Save saves a book and records its initial stock in the ledger.
+func (r *BookRepositoryMemory) Save(_ context.Context, book domain.Book, movement domain.StockMovement) (domain.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book.ID = r.store.nextID("books")
+	book.Version = 1
+	r.store.books[book.ID] = book
+
+	movement.BookID = book.ID
+	movement.Delta = book.Stock
+	movement.Balance = book.Stock
+	r.store.recordMovement(movement)
+
+	return book, nil
+}
+
+// FindByID finds a book by ID.
+func (r *BookRepositoryMemory) FindByID(_ context.Context, id uint) (domain.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	book, ok := r.store.books[id]
+	if !ok || book.DeletedAt != nil {
+		return domain.Book{}, domain.ErrBookNotFound
+	}
+
+	return book, nil
+}
+
+// FindAll returns a page of books, oldest first.
+func (r *BookRepositoryMemory) FindAll(_ context.Context, page domain.Page) ([]domain.Book, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := make([]domain.Book, 0, len(r.store.books))
+	for _, id := range sortedKeys(r.store.books) {
+		if book := r.store.books[id]; book.DeletedAt == nil {
+			books = append(books, book)
+		}
+	}
+
+	books, total := pageOf(books, page)
+	return books, total, nil
+}
+
+// Search returns books whose title contains the query, ignoring case.
+func (r *BookRepositoryMemory) Search(_ context.Context, query string, page domain.Page) ([]domain.Book, int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	books := make([]domain.Book, 0)
+	for _, id := range sortedKeys(r.store.books) {
+		book := r.store.books[id]
+		if book.DeletedAt == nil && strings.Contains(strings.ToLower(book.Title), query) {
+			books = append(books, book)
+		}
+	}
+
+	books, total := pageOf(books, page)
+	return books, total, nil
+}
+
+// FindLowStock returns books whose available stock is below their reorder threshold.
+func (r *BookRepositoryMemory) FindLowStock(_ context.Context) ([]domain.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := make([]domain.Book, 0)
+	for _, id := range sortedKeys(r.store.books) {
+		if book := r.store.books[id]; book.DeletedAt == nil && book.IsLowStock() {
+			books = append(books, book)
+		}
+	}
+
+	return books, nil
+}
+
+// UpdateStock changes a book's stock and reservations while holding the store lock
+// and appends the change to the ledger.
+func (r *BookRepositoryMemory) UpdateStock(_ context.Context, id uint, movement domain.StockMovement, apply func(book *domain.Book) error) (domain.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.books[id]
+	if !ok || current.DeletedAt != nil {
+		return domain.Book{}, domain.ErrBookNotFound
+	}
+
+	book := current
+	if err := apply(&book); err != nil {
+		return domain.Book{}, err
+	}
+
+	movement.BookID = id
+	movement.Delta = book.Stock - current.Stock
+	movement.Balance = book.Stock
+	r.store.recordMovement(movement)
+
+	// Only stock and reservations are persisted, like the database adapters
+	current.Stock = book.Stock
+	current.Reserved = book.Reserved
+	current.Version++
+	r.store.books[id] = current
+
+	return current, nil
+}
+
+// Update updates a book if its version has not changed.
+func (r *BookRepositoryMemory) Update(_ context.Context, book domain.Book) (domain.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.books[book.ID]
+	if !ok || current.DeletedAt != nil {
+		return domain.Book{}, domain.ErrBookNotFound
+	}
+	if current.Version != book.Version {
+		return domain.Book{}, domain.ErrBookConflict
+	}
+
+	// Stock only changes through UpdateStock
+	book.Stock = current.Stock
+	book.Reserved = current.Reserved
+	book.Version++
+	r.store.books[book.ID] = book
+
+	return book, nil
+}
+
+// ReconcileStock resets a book's stock to its ledger.
+func (r *BookRepositoryMemory) ReconcileStock(_ context.Context, id uint, movement domain.StockMovement) (domain.Book, int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[id]
+	if !ok {
+		return domain.Book{}, 0, domain.ErrBookNotFound
+	}
+
+	var ledger int
+	for _, recorded := range r.store.stockMovements {
+		if recorded.BookID == id {
+			ledger += recorded.Delta
+		}
+	}
+	if book.Stock == ledger {
+		return book, ledger, nil
+	}
+
+	movement.ID = r.store.nextID("stock_movements")
+	movement.BookID = id
+	movement.Balance = ledger
+	movement.Reference = fmt.Sprintf("stock was %d", book.Stock)
+	movement.CreatedAt = time.Now()
+	r.store.stockMovements[movement.ID] = movement
+
+	reconciled := book
+	reconciled.Stock = ledger
+	reconciled.Version++
+	r.store.books[id] = reconciled
+
+	return book, ledger, nil
+}
+
+// SetStock overwrites a book's stock without recording a movement, like a
+// write that bypassed the ledger. It is not part of domain.BookRepository;
+// tests use it to make stock drift from the ledger.
+func (r *BookRepositoryMemory) SetStock(id uint, stock int) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[id]; ok {
+		book.Stock = stock
+		r.store.books[id] = book
+	}
+}
+
+// Delete soft deletes a book.
+func (r *BookRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if book, ok := r.store.books[id]; ok && book.DeletedAt == nil {
+		now := time.Now()
+		book.DeletedAt = &now
+		r.store.books[id] = book
+	}
+
+	return nil
+}
+
+// FindDeleted returns all soft deleted books.
+func (r *BookRepositoryMemory) FindDeleted(_ context.Context) ([]domain.Book, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	books := make([]domain.Book, 0)
+	for _, id := range sortedKeys(r.store.books) {
+		if book := r.store.books[id]; book.DeletedAt != nil {
+			books = append(books, book)
+		}
+	}
+
+	return books, nil
+}
+
+// Restore restores a soft deleted book.
+func (r *BookRepositoryMemory) Restore(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	book, ok := r.store.books[id]
+	if !ok || book.DeletedAt == nil {
+		return domain.ErrBookNotFound
+	}
+
+	book.DeletedAt = nil
+	r.store.books[id] = book
+
+	return nil
+}
+
+// Purge permanently deletes books soft deleted before the given time
+// that are not referenced by any order.
+func (r *BookRepositoryMemory) Purge(_ context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	referenced := make(map[uint]bool)
+	for _, order := range r.store.orders {
+		referenced[order.BookID] = true
+	}
+
+	var purged int64
+	for id, book := range r.store.books {
+		if book.DeletedAt != nil && book.DeletedAt.Before(before) && !referenced[id] {
+			delete(r.store.books, id)
+			purged++
+		}
+	}
+
+	// The ledger and price history go with their book, like the cascading
+	// foreign keys
+	for id, movement := range r.store.stockMovements {
+		if _, ok := r.store.books[movement.BookID]; !ok {
+			delete(r.store.stockMovements, id)
+		}
+	}
+	for id, price := range r.store.bookPrices {
+		if _, ok := r.store.books[price.BookID]; !ok {
+			delete(r.store.bookPrices, id)
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// IdempotencyKeyRepositoryMemory implements domain.IdempotencyKeyRepository in memory.
+type IdempotencyKeyRepositoryMemory struct {
+	store *Store
+}
+
+// NewIdempotencyKeyRepositoryMemory creates a new IdempotencyKeyRepositoryMemory.
+func NewIdempotencyKeyRepositoryMemory(store *Store) *IdempotencyKeyRepositoryMemory {
+	return &IdempotencyKeyRepositoryMemory{store: store}
+}
+
+// Create saves a new idempotency key unless the key is already stored.
+func (r *IdempotencyKeyRepositoryMemory) Create(_ context.Context, key domain.IdempotencyKey) (domain.IdempotencyKey, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.idempotencyKeys {
+		if existing.Key == key.Key {
+			return domain.IdempotencyKey{}, domain.ErrIdempotencyKeyExists
+		}
+	}
+
+	key.ID = r.store.nextID("idempotency_keys")
+	r.store.idempotencyKeys[key.ID] = key
+
+	return key, nil
+}
+
+// FindByKey finds an idempotency key.
+func (r *IdempotencyKeyRepositoryMemory) FindByKey(_ context.Context, key string) (domain.IdempotencyKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, existing := range r.store.idempotencyKeys {
+		if existing.Key == key {
+			return existing, nil
+		}
+	}
+
+	return domain.IdempotencyKey{}, domain.ErrIdempotencyKeyNotFound
+}
+
+// Complete stores the response of the request that claimed the key.
+func (r *IdempotencyKeyRepositoryMemory) Complete(_ context.Context, id uint, statusCode int, body []byte) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.idempotencyKeys[id]
+	if !ok {
+		return domain.ErrIdempotencyKeyNotFound
+	}
+
+	key.StatusCode = statusCode
+	key.ResponseBody = append([]byte(nil), body...)
+	r.store.idempotencyKeys[id] = key
+
+	return nil
+}
+
+// Delete deletes an idempotency key.
+func (r *IdempotencyKeyRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotencyKeys, id)
+
+	return nil
+}
+
+// DeleteExpired deletes idempotency keys that expired at or before now.
+func (r *IdempotencyKeyRepositoryMemory) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for id, key := range r.store.idempotencyKeys {
+		if key.IsExpired(now) {
+			delete(r.store.idempotencyKeys, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
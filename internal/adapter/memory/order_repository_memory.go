@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// OrderRepositoryMemory implements domain.OrderRepository in memory.
+type OrderRepositoryMemory struct {
+	store *Store
+}
+
+// NewOrderRepositoryMemory creates a new OrderRepositoryMemory.
+func NewOrderRepositoryMemory(store *Store) *OrderRepositoryMemory {
+	return &OrderRepositoryMemory{store: store}
+}
+
+// Save saves an order.
+func (r *OrderRepositoryMemory) Save(_ context.Context, order domain.Order) (domain.Order, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order.ID = r.store.nextID("orders")
+	r.store.orders[order.ID] = order
+
+	return order, nil
+}
+
+// FindByID finds an order by ID.
+func (r *OrderRepositoryMemory) FindByID(_ context.Context, id uint) (domain.Order, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	order, ok := r.store.orders[id]
+	if !ok || order.DeletedAt != nil {
+		return domain.Order{}, domain.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// FindByUserID finds all orders by user ID.
+func (r *OrderRepositoryMemory) FindByUserID(_ context.Context, userID uint) ([]domain.Order, error) {
+	return r.filter(func(order domain.Order) bool {
+		return order.DeletedAt == nil && order.UserID == userID
+	}), nil
+}
+
+// FindAll returns a page of orders, oldest first.
+func (r *OrderRepositoryMemory) FindAll(_ context.Context, page domain.Page) ([]domain.Order, int64, error) {
+	orders, total := pageOf(r.filter(func(order domain.Order) bool {
+		return order.DeletedAt == nil
+	}), page)
+	return orders, total, nil
+}
+
+// Update updates an order.
+func (r *OrderRepositoryMemory) Update(_ context.Context, order domain.Order) (domain.Order, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.orders[order.ID] = order
+
+	return order, nil
+}
+
+// UpdateStatus moves an order from one status to another.
+func (r *OrderRepositoryMemory) UpdateStatus(_ context.Context, id uint, from, to string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[id]
+	if !ok || order.DeletedAt != nil {
+		return domain.ErrOrderNotFound
+	}
+	if order.Status != from {
+		return domain.ErrOrderStatusChanged
+	}
+
+	order.Status = to
+	r.store.orders[id] = order
+
+	return nil
+}
+
+// UpdateRefund records a refund on an order read with the given refunded
+// amount and status.
+func (r *OrderRepositoryMemory) UpdateRefund(_ context.Context, before, after domain.Order) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[before.ID]
+	if !ok || order.DeletedAt != nil {
+		return domain.ErrOrderNotFound
+	}
+	if order.Status != before.Status || order.RefundedAmount != before.RefundedAmount {
+		return domain.ErrOrderStatusChanged
+	}
+
+	order.RefundedAmount = after.RefundedAmount
+	order.Status = after.Status
+	r.store.orders[before.ID] = order
+
+	return nil
+}
+
+// FindBackordered returns a book's backordered orders, oldest first.
+func (r *OrderRepositoryMemory) FindBackordered(_ context.Context, bookID uint) ([]domain.Order, error) {
+	return r.filter(func(order domain.Order) bool {
+		return order.DeletedAt == nil && order.BookID == bookID && order.IsBackordered()
+	}), nil
+}
+
+// Delete soft deletes an order.
+func (r *OrderRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if order, ok := r.store.orders[id]; ok && order.DeletedAt == nil {
+		now := time.Now()
+		order.DeletedAt = &now
+		r.store.orders[id] = order
+	}
+
+	return nil
+}
+
+// FindDeleted returns all soft deleted orders.
+func (r *OrderRepositoryMemory) FindDeleted(_ context.Context) ([]domain.Order, error) {
+	return r.filter(func(order domain.Order) bool {
+		return order.DeletedAt != nil
+	}), nil
+}
+
+// Restore restores a soft deleted order.
+func (r *OrderRepositoryMemory) Restore(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	order, ok := r.store.orders[id]
+	if !ok || order.DeletedAt == nil {
+		return domain.ErrOrderNotFound
+	}
+
+	order.DeletedAt = nil
+	r.store.orders[id] = order
+
+	return nil
+}
+
+// Purge permanently deletes orders soft deleted before the given time.
+func (r *OrderRepositoryMemory) Purge(_ context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, order := range r.store.orders {
+		if order.DeletedAt != nil && order.DeletedAt.Before(before) {
+			delete(r.store.orders, id)
+			purged++
+		}
+	}
+
+	// Reservations, payments, returns, redemptions and shipments go with their order, like the cascading foreign keys
+	for id, reservation := range r.store.reservations {
+		if _, ok := r.store.orders[reservation.OrderID]; !ok {
+			delete(r.store.reservations, id)
+		}
+	}
+	for id, payment := range r.store.payments {
+		if _, ok := r.store.orders[payment.OrderID]; !ok {
+			delete(r.store.payments, id)
+		}
+	}
+	for id, request := range r.store.returnRequests {
+		if _, ok := r.store.orders[request.OrderID]; !ok {
+			delete(r.store.returnRequests, id)
+		}
+	}
+	for id, redemption := range r.store.promotionRedemptions {
+		if _, ok := r.store.orders[redemption.OrderID]; !ok {
+			delete(r.store.promotionRedemptions, id)
+		}
+	}
+	for id, shipment := range r.store.orderShipments {
+		if _, ok := r.store.orders[shipment.OrderID]; !ok {
+			delete(r.store.orderShipments, id)
+		}
+	}
+
+	return purged, nil
+}
+
+// CountOpenByBookID counts unfinished orders for a book.
+func (r *OrderRepositoryMemory) CountOpenByBookID(_ context.Context, bookID uint) (int64, error) {
+	orders := r.filter(func(order domain.Order) bool {
+		return order.DeletedAt == nil && order.BookID == bookID && order.IsUnfinished()
+	})
+	return int64(len(orders)), nil
+}
+
+// CountOpenByUserID counts unfinished orders of a user.
+func (r *OrderRepositoryMemory) CountOpenByUserID(_ context.Context, userID uint) (int64, error) {
+	orders := r.filter(func(order domain.Order) bool {
+		return order.DeletedAt == nil && order.UserID == userID && order.IsUnfinished()
+	})
+	return int64(len(orders)), nil
+}
+
+// FindOrphaned returns orders, including soft deleted ones, whose user or book no longer exists.
+func (r *OrderRepositoryMemory) FindOrphaned(_ context.Context) ([]domain.OrphanedOrder, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	orphans := make([]domain.OrphanedOrder, 0)
+	for _, id := range sortedKeys(r.store.orders) {
+		order := r.store.orders[id]
+		_, hasUser := r.store.users[order.UserID]
+		_, hasBook := r.store.books[order.BookID]
+		if !hasUser || !hasBook {
+			orphans = append(orphans, domain.OrphanedOrder{
+				Order:       order,
+				MissingUser: !hasUser,
+				MissingBook: !hasBook,
+			})
+		}
+	}
+
+	return orphans, nil
+}
+
+// filter returns orders matching the predicate, ordered by ID.
+func (r *OrderRepositoryMemory) filter(match func(domain.Order) bool) []domain.Order {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	orders := make([]domain.Order, 0)
+	for _, id := range sortedKeys(r.store.orders) {
+		if order := r.store.orders[id]; match(order) {
+			orders = append(orders, order)
+		}
+	}
+
+	return orders
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// OrderShipmentRepositoryMemory implements domain.OrderShipmentRepository in memory.
+type OrderShipmentRepositoryMemory struct {
+	store *Store
+}
+
+// NewOrderShipmentRepositoryMemory creates a new OrderShipmentRepositoryMemory.
+func NewOrderShipmentRepositoryMemory(store *Store) *OrderShipmentRepositoryMemory {
+	return &OrderShipmentRepositoryMemory{store: store}
+}
+
+// Save saves an order shipment.
+func (r *OrderShipmentRepositoryMemory) Save(_ context.Context, shipment domain.OrderShipment) (domain.OrderShipment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	shipment.ID = r.store.nextID("order_shipments")
+	r.store.orderShipments[shipment.ID] = shipment
+
+	return shipment, nil
+}
+
+// Update updates an order shipment.
+func (r *OrderShipmentRepositoryMemory) Update(_ context.Context, shipment domain.OrderShipment) (domain.OrderShipment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.orderShipments[shipment.ID]; !ok {
+		return domain.OrderShipment{}, domain.ErrOrderShipmentNotFound
+	}
+	r.store.orderShipments[shipment.ID] = shipment
+
+	return shipment, nil
+}
+
+// FindByOrderID finds the shipment of an order.
+func (r *OrderShipmentRepositoryMemory) FindByOrderID(_ context.Context, orderID uint) (domain.OrderShipment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, shipment := range r.store.orderShipments {
+		if shipment.OrderID == orderID {
+			return shipment, nil
+		}
+	}
+
+	return domain.OrderShipment{}, domain.ErrOrderShipmentNotFound
+}
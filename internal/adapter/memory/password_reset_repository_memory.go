@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PasswordResetRepositoryMemory implements domain.PasswordResetRepository in memory.
+type PasswordResetRepositoryMemory struct {
+	store *Store
+}
+
+// NewPasswordResetRepositoryMemory creates a new PasswordResetRepositoryMemory.
+func NewPasswordResetRepositoryMemory(store *Store) *PasswordResetRepositoryMemory {
+	return &PasswordResetRepositoryMemory{store: store}
+}
+
+// Save saves a password reset token.
+func (r *PasswordResetRepositoryMemory) Save(_ context.Context, reset domain.PasswordReset) (domain.PasswordReset, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reset.ID = r.store.nextID("password_resets")
+	r.store.passwordResets[reset.ID] = reset
+
+	return reset, nil
+}
+
+// FindByTokenHash finds a password reset token by its hash.
+func (r *PasswordResetRepositoryMemory) FindByTokenHash(_ context.Context, tokenHash string) (domain.PasswordReset, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, reset := range r.store.passwordResets {
+		if reset.TokenHash == tokenHash {
+			return reset, nil
+		}
+	}
+
+	return domain.PasswordReset{}, domain.ErrInvalidResetToken
+}
+
+// MarkUsed marks a token as used only if it has not been used yet.
+func (r *PasswordResetRepositoryMemory) MarkUsed(_ context.Context, id uint, usedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reset, ok := r.store.passwordResets[id]
+	if !ok || reset.UsedAt != nil {
+		return domain.ErrInvalidResetToken
+	}
+
+	reset.UsedAt = &usedAt
+	r.store.passwordResets[id] = reset
+
+	return nil
+}
+
+// DeleteByUserID deletes all password reset tokens of a user.
+func (r *PasswordResetRepositoryMemory) DeleteByUserID(_ context.Context, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, reset := range r.store.passwordResets {
+		if reset.UserID == userID {
+			delete(r.store.passwordResets, id)
+		}
+	}
+
+	return nil
+}
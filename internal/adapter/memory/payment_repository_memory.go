@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PaymentRepositoryMemory implements domain.PaymentRepository in memory.
+type PaymentRepositoryMemory struct {
+	store *Store
+}
+
+// NewPaymentRepositoryMemory creates a new PaymentRepositoryMemory.
+func NewPaymentRepositoryMemory(store *Store) *PaymentRepositoryMemory {
+	return &PaymentRepositoryMemory{store: store}
+}
+
+// Save saves a payment.
+func (r *PaymentRepositoryMemory) Save(_ context.Context, payment domain.Payment) (domain.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.heldByOther(payment) {
+		return domain.Payment{}, domain.ErrPaymentExists
+	}
+	payment.ID = r.store.nextID("payments")
+	r.store.payments[payment.ID] = payment
+
+	return payment, nil
+}
+
+// Update updates a payment.
+func (r *PaymentRepositoryMemory) Update(_ context.Context, payment domain.Payment) (domain.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.payments[payment.ID]; !ok {
+		return domain.Payment{}, domain.ErrPaymentNotFound
+	}
+	if r.heldByOther(payment) {
+		return domain.Payment{}, domain.ErrPaymentExists
+	}
+	payment.UpdatedAt = time.Now()
+	r.store.payments[payment.ID] = payment
+
+	return payment, nil
+}
+
+// UpdateRefund records a refund on a payment read with the given refunded
+// amount and status.
+func (r *PaymentRepositoryMemory) UpdateRefund(_ context.Context, before, after domain.Payment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payment, ok := r.store.payments[before.ID]
+	if !ok {
+		return domain.ErrPaymentNotFound
+	}
+	if payment.Status != before.Status || payment.RefundedAmount != before.RefundedAmount {
+		return domain.ErrPaymentChanged
+	}
+
+	payment.RefundedAmount = after.RefundedAmount
+	payment.Status = after.Status
+	payment.UpdatedAt = time.Now()
+	r.store.payments[before.ID] = payment
+
+	return nil
+}
+
+// FindByID finds a payment by ID.
+func (r *PaymentRepositoryMemory) FindByID(_ context.Context, id uint) (domain.Payment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	payment, ok := r.store.payments[id]
+	if !ok {
+		return domain.Payment{}, domain.ErrPaymentNotFound
+	}
+
+	return payment, nil
+}
+
+// FindByOrderID returns an order's payments, oldest first.
+func (r *PaymentRepositoryMemory) FindByOrderID(_ context.Context, orderID uint) ([]domain.Payment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	payments := make([]domain.Payment, 0)
+	for _, id := range sortedKeys(r.store.payments) {
+		if payment := r.store.payments[id]; payment.OrderID == orderID {
+			payments = append(payments, payment)
+		}
+	}
+
+	return payments, nil
+}
+
+// heldByOther reports whether payment would hold an order another payment
+// already holds. The caller must hold the store lock.
+func (r *PaymentRepositoryMemory) heldByOther(payment domain.Payment) bool {
+	if !payment.HoldsOrder() {
+		return false
+	}
+	for id, other := range r.store.payments {
+		if id != payment.ID && other.OrderID == payment.OrderID && other.HoldsOrder() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PromotionRepositoryMemory implements domain.PromotionRepository in memory.
+type PromotionRepositoryMemory struct {
+	store *Store
+}
+
+// NewPromotionRepositoryMemory creates a new PromotionRepositoryMemory.
+func NewPromotionRepositoryMemory(store *Store) *PromotionRepositoryMemory {
+	return &PromotionRepositoryMemory{store: store}
+}
+
+// Save saves a promotion.
+func (r *PromotionRepositoryMemory) Save(_ context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.codeTaken(promotion.Code, 0) {
+		return domain.Promotion{}, domain.ErrPromotionCodeExists
+	}
+
+	promotion.ID = r.store.nextID("promotions")
+	promotion.UsedCount = 0
+	r.store.promotions[promotion.ID] = promotion
+
+	return promotion, nil
+}
+
+// Update updates a promotion.
+func (r *PromotionRepositoryMemory) Update(_ context.Context, promotion domain.Promotion) (domain.Promotion, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promotions[promotion.ID]; !ok {
+		return domain.Promotion{}, domain.ErrPromotionNotFound
+	}
+	if r.codeTaken(promotion.Code, promotion.ID) {
+		return domain.Promotion{}, domain.ErrPromotionCodeExists
+	}
+	promotion.UpdatedAt = time.Now()
+	r.store.promotions[promotion.ID] = promotion
+
+	return r.withUsage(promotion), nil
+}
+
+// FindByID finds a promotion by ID.
+func (r *PromotionRepositoryMemory) FindByID(_ context.Context, id uint) (domain.Promotion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	promotion, ok := r.store.promotions[id]
+	if !ok {
+		return domain.Promotion{}, domain.ErrPromotionNotFound
+	}
+
+	return r.withUsage(promotion), nil
+}
+
+// FindByCode finds a promotion by its normalized code.
+func (r *PromotionRepositoryMemory) FindByCode(_ context.Context, code string) (domain.Promotion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, promotion := range r.store.promotions {
+		if promotion.Code == code {
+			return r.withUsage(promotion), nil
+		}
+	}
+
+	return domain.Promotion{}, domain.ErrPromotionNotFound
+}
+
+// FindAll returns all promotions, oldest first.
+func (r *PromotionRepositoryMemory) FindAll(_ context.Context) ([]domain.Promotion, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	promotions := make([]domain.Promotion, 0, len(r.store.promotions))
+	for _, id := range sortedKeys(r.store.promotions) {
+		promotions = append(promotions, r.withUsage(r.store.promotions[id]))
+	}
+
+	return promotions, nil
+}
+
+// Delete deletes a promotion and its redemptions.
+func (r *PromotionRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.promotions[id]; !ok {
+		return domain.ErrPromotionNotFound
+	}
+	delete(r.store.promotions, id)
+
+	for redemptionID, redemption := range r.store.promotionRedemptions {
+		if redemption.PromotionID == id {
+			delete(r.store.promotionRedemptions, redemptionID)
+		}
+	}
+
+	return nil
+}
+
+// Redeem records a redemption unless the promotion's limits are reached.
+// The store lock makes the check and the insert atomic.
+func (r *PromotionRepositoryMemory) Redeem(_ context.Context, redemption domain.PromotionRedemption) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	promotion, ok := r.store.promotions[redemption.PromotionID]
+	if !ok {
+		return domain.ErrPromotionNotFound
+	}
+
+	var used, usedByUser int
+	for _, existing := range r.store.promotionRedemptions {
+		if existing.PromotionID != promotion.ID {
+			continue
+		}
+		used++
+		if existing.UserID == redemption.UserID {
+			usedByUser++
+		}
+	}
+	if promotion.UsageLimit > 0 && used >= promotion.UsageLimit {
+		return domain.ErrPromotionUsedUp
+	}
+	if promotion.PerUserLimit > 0 && usedByUser >= promotion.PerUserLimit {
+		return domain.ErrPromotionUserLimit
+	}
+
+	redemption.ID = r.store.nextID("promotion_redemptions")
+	r.store.promotionRedemptions[redemption.ID] = redemption
+
+	return nil
+}
+
+// Release deletes an order's redemption.
+func (r *PromotionRepositoryMemory) Release(_ context.Context, orderID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, redemption := range r.store.promotionRedemptions {
+		if redemption.OrderID == orderID {
+			delete(r.store.promotionRedemptions, id)
+		}
+	}
+
+	return nil
+}
+
+// codeTaken checks if another promotion uses the code.
+// Callers must hold the lock.
+func (r *PromotionRepositoryMemory) codeTaken(code string, exceptID uint) bool {
+	for id, promotion := range r.store.promotions {
+		if id != exceptID && promotion.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// withUsage sets a promotion's redemption count.
+// Callers must hold the lock.
+func (r *PromotionRepositoryMemory) withUsage(promotion domain.Promotion) domain.Promotion {
+	promotion.UsedCount = 0
+	for _, redemption := range r.store.promotionRedemptions {
+		if redemption.PromotionID == promotion.ID {
+			promotion.UsedCount++
+		}
+	}
+	return promotion
+}
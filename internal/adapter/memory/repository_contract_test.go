@@ -0,0 +1,77 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/adapter/memory"
+	"kikukafandi/book-shop-api/internal/adapter/repotest"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+func newRepositories(t *testing.T) repotest.Repositories {
+	store := memory.NewStore()
+	return repotest.Repositories{
+		Books:           memory.NewBookRepositoryMemory(store),
+		Users:           memory.NewUserRepositoryMemory(store),
+		Orders:          memory.NewOrderRepositoryMemory(store),
+		StockMovements:  memory.NewStockMovementRepositoryMemory(store),
+		Reservations:    memory.NewReservationRepositoryMemory(store),
+		IdempotencyKeys: memory.NewIdempotencyKeyRepositoryMemory(store),
+		Payments:        memory.NewPaymentRepositoryMemory(store),
+		WebhookEvents:   memory.NewWebhookEventRepositoryMemory(store),
+		ReturnRequests:  memory.NewReturnRequestRepositoryMemory(store),
+		Promotions:      memory.NewPromotionRepositoryMemory(store),
+		BookPrices:      memory.NewBookPriceRepositoryMemory(store),
+		Addresses:       memory.NewAddressRepositoryMemory(store),
+		OrderShipments:  memory.NewOrderShipmentRepositoryMemory(store),
+	}
+}
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, newRepositories)
+}
+
+func TestFindOrphanedReportsMissingReferences(t *testing.T) {
+	ctx := context.Background()
+	repos := newRepositories(t)
+
+	// The memory store has no foreign keys, so orphans can be created directly
+	order, _ := repos.Orders.Save(ctx, domain.NewOrder(42, 7, 1, 10))
+
+	orphans, err := repos.Orders.FindOrphaned(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphaned: %v", err)
+	}
+	if len(orphans) != 1 {
+		t.Fatalf("expected 1 orphan, got %d", len(orphans))
+	}
+	if orphans[0].Order.ID != order.ID || !orphans[0].MissingUser || !orphans[0].MissingBook {
+		t.Fatalf("unexpected orphan: %+v", orphans[0])
+	}
+}
+
+func TestBookUpdateIsSafeForConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	repos := newRepositories(t)
+	book, _ := repos.Books.Save(ctx, domain.NewBook("A", 10, 100), domain.NewInitialStock(""))
+
+	const workers = 20
+	results := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			_, err := repos.Books.Update(ctx, book)
+			results <- err
+		}()
+	}
+
+	var succeeded int
+	for i := 0; i < workers; i++ {
+		if err := <-results; err == nil {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one update at version 1 to win, got %d", succeeded)
+	}
+}
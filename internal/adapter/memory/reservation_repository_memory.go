@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// ReservationRepositoryMemory implements domain.ReservationRepository in memory.
+type ReservationRepositoryMemory struct {
+	store *Store
+}
+
+// NewReservationRepositoryMemory creates a new ReservationRepositoryMemory.
+func NewReservationRepositoryMemory(store *Store) *ReservationRepositoryMemory {
+	return &ReservationRepositoryMemory{store: store}
+}
+
+// Save saves a reservation.
+func (r *ReservationRepositoryMemory) Save(_ context.Context, reservation domain.Reservation) (domain.Reservation, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	reservation.ID = r.store.nextID("stock_reservations")
+	r.store.reservations[reservation.ID] = reservation
+
+	return reservation, nil
+}
+
+// FindByOrderID finds the reservation of an order.
+func (r *ReservationRepositoryMemory) FindByOrderID(_ context.Context, orderID uint) (domain.Reservation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, reservation := range r.store.reservations {
+		if reservation.OrderID == orderID {
+			return reservation, nil
+		}
+	}
+
+	return domain.Reservation{}, domain.ErrReservationNotFound
+}
+
+// FindExpired returns reservations that expired at or before now, oldest first.
+func (r *ReservationRepositoryMemory) FindExpired(_ context.Context, now time.Time) ([]domain.Reservation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reservations := make([]domain.Reservation, 0)
+	for _, id := range sortedKeys(r.store.reservations) {
+		if reservation := r.store.reservations[id]; reservation.IsExpired(now) {
+			reservations = append(reservations, reservation)
+		}
+	}
+	sort.SliceStable(reservations, func(i, j int) bool {
+		return reservations[i].ExpiresAt.Before(reservations[j].ExpiresAt)
+	})
+
+	return reservations, nil
+}
+
+// Delete deletes a reservation only if it still exists.
+func (r *ReservationRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.reservations[id]; !ok {
+		return domain.ErrReservationNotFound
+	}
+	delete(r.store.reservations, id)
+
+	return nil
+}
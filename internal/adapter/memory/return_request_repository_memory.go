@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// ReturnRequestRepositoryMemory implements domain.ReturnRequestRepository in memory.
+type ReturnRequestRepositoryMemory struct {
+	store *Store
+}
+
+// NewReturnRequestRepositoryMemory creates a new ReturnRequestRepositoryMemory.
+func NewReturnRequestRepositoryMemory(store *Store) *ReturnRequestRepositoryMemory {
+	return &ReturnRequestRepositoryMemory{store: store}
+}
+
+// Save saves a return request.
+func (r *ReturnRequestRepositoryMemory) Save(_ context.Context, request domain.ReturnRequest) (domain.ReturnRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	request.ID = r.store.nextID("return_requests")
+	r.store.returnRequests[request.ID] = request
+
+	return request, nil
+}
+
+// Update updates a return request.
+func (r *ReturnRequestRepositoryMemory) Update(_ context.Context, request domain.ReturnRequest) (domain.ReturnRequest, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.returnRequests[request.ID]; !ok {
+		return domain.ReturnRequest{}, domain.ErrReturnNotFound
+	}
+	request.UpdatedAt = time.Now()
+	r.store.returnRequests[request.ID] = request
+
+	return request, nil
+}
+
+// UpdateStatus moves a return request from one status to another.
+func (r *ReturnRequestRepositoryMemory) UpdateStatus(_ context.Context, id uint, from, to string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	request, ok := r.store.returnRequests[id]
+	if !ok {
+		return domain.ErrReturnNotFound
+	}
+	if request.Status != from {
+		return domain.ErrReturnNotRequested
+	}
+
+	request.Status = to
+	request.UpdatedAt = time.Now()
+	r.store.returnRequests[id] = request
+
+	return nil
+}
+
+// FindByID finds a return request by ID.
+func (r *ReturnRequestRepositoryMemory) FindByID(_ context.Context, id uint) (domain.ReturnRequest, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	request, ok := r.store.returnRequests[id]
+	if !ok {
+		return domain.ReturnRequest{}, domain.ErrReturnNotFound
+	}
+
+	return request, nil
+}
+
+// FindByOrderID returns an order's return requests, oldest first.
+func (r *ReturnRequestRepositoryMemory) FindByOrderID(_ context.Context, orderID uint) ([]domain.ReturnRequest, error) {
+	return r.filter(func(request domain.ReturnRequest) bool {
+		return request.OrderID == orderID
+	}), nil
+}
+
+// FindByStatus returns a page of the return requests in a status, oldest first.
+func (r *ReturnRequestRepositoryMemory) FindByStatus(_ context.Context, status string, page domain.Page) ([]domain.ReturnRequest, int64, error) {
+	requests, total := pageOf(r.filter(func(request domain.ReturnRequest) bool {
+		return status == "" || request.Status == status
+	}), page)
+	return requests, total, nil
+}
+
+// filter returns return requests matching the predicate, ordered by ID.
+func (r *ReturnRequestRepositoryMemory) filter(match func(domain.ReturnRequest) bool) []domain.ReturnRequest {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	requests := make([]domain.ReturnRequest, 0)
+	for _, id := range sortedKeys(r.store.returnRequests) {
+		if request := r.store.returnRequests[id]; match(request) {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
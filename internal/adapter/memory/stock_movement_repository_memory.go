@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// StockMovementRepositoryMemory implements domain.StockMovementRepository in memory.
+type StockMovementRepositoryMemory struct {
+	store *Store
+}
+
+// NewStockMovementRepositoryMemory creates a new StockMovementRepositoryMemory.
+func NewStockMovementRepositoryMemory(store *Store) *StockMovementRepositoryMemory {
+	return &StockMovementRepositoryMemory{store: store}
+}
+
+// FindByBookID returns a book's movements, oldest first.
+func (r *StockMovementRepositoryMemory) FindByBookID(_ context.Context, bookID uint) ([]domain.StockMovement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	movements := make([]domain.StockMovement, 0)
+	for _, id := range sortedKeys(r.store.stockMovements) {
+		if movement := r.store.stockMovements[id]; movement.BookID == bookID {
+			movements = append(movements, movement)
+		}
+	}
+
+	return movements, nil
+}
+
+// Balances returns the sum of movement deltas per book.
+func (r *StockMovementRepositoryMemory) Balances(_ context.Context) (map[uint]int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	balances := make(map[uint]int)
+	for _, movement := range r.store.stockMovements {
+		balances[movement.BookID] += movement.Delta
+	}
+
+	return balances, nil
+}
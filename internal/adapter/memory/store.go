@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// Store holds all in-memory data behind a single lock.
+// Repositories share a Store so cross-entity queries (e.g. orphaned orders)
+// behave like they do against a real database.
+type Store struct {
+	mu                   sync.RWMutex
+	books                map[uint]domain.Book
+	users                map[uint]domain.User
+	orders               map[uint]domain.Order
+	passwordResets       map[uint]domain.PasswordReset
+	stockMovements       map[uint]domain.StockMovement
+	reservations         map[uint]domain.Reservation
+	idempotencyKeys      map[uint]domain.IdempotencyKey
+	payments             map[uint]domain.Payment
+	webhookEvents        map[uint]domain.WebhookEvent
+	returnRequests       map[uint]domain.ReturnRequest
+	promotions           map[uint]domain.Promotion
+	bookPrices           map[uint]domain.BookPrice
+	addresses            map[uint]domain.Address
+	orderShipments       map[uint]domain.OrderShipment
+	promotionRedemptions map[uint]domain.PromotionRedemption
+	lastID               map[string]uint
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		books:                make(map[uint]domain.Book),
+		users:                make(map[uint]domain.User),
+		orders:               make(map[uint]domain.Order),
+		passwordResets:       make(map[uint]domain.PasswordReset),
+		stockMovements:       make(map[uint]domain.StockMovement),
+		reservations:         make(map[uint]domain.Reservation),
+		idempotencyKeys:      make(map[uint]domain.IdempotencyKey),
+		payments:             make(map[uint]domain.Payment),
+		webhookEvents:        make(map[uint]domain.WebhookEvent),
+		returnRequests:       make(map[uint]domain.ReturnRequest),
+		promotions:           make(map[uint]domain.Promotion),
+		bookPrices:           make(map[uint]domain.BookPrice),
+		addresses:            make(map[uint]domain.Address),
+		orderShipments:       make(map[uint]domain.OrderShipment),
+		promotionRedemptions: make(map[uint]domain.PromotionRedemption),
+		lastID:               make(map[string]uint),
+	}
+}
+
+// nextID returns the next auto-increment ID for a table.
+// Callers must hold the write lock.
+func (s *Store) nextID(table string) uint {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// recordMovement appends a movement to the ledger unless it changes nothing.
+// Callers must hold the write lock.
+func (s *Store) recordMovement(movement domain.StockMovement) {
+	if movement.Delta == 0 {
+		return
+	}
+
+	movement.ID = s.nextID("stock_movements")
+	movement.CreatedAt = time.Now()
+	s.stockMovements[movement.ID] = movement
+}
+
+// sortedKeys returns map keys in ascending order so results are deterministic.
+func sortedKeys[T any](m map[uint]T) []uint {
+	keys := make([]uint, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// pageOf returns the page of items and how many items there are in all.
+func pageOf[T any](items []T, page domain.Page) ([]T, int64) {
+	total := len(items)
+	if page.Limit == 0 {
+		return items, int64(total)
+	}
+
+	start := min(page.Offset, total)
+	end := min(start+page.Limit, total)
+	return items[start:end], int64(total)
+}
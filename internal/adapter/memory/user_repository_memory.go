@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// UserRepositoryMemory implements domain.UserRepository in memory.
+type UserRepositoryMemory struct {
+	store *Store
+}
+
+// NewUserRepositoryMemory creates a new UserRepositoryMemory.
+func NewUserRepositoryMemory(store *Store) *UserRepositoryMemory {
+	return &UserRepositoryMemory{store: store}
+}
+
+// Save saves a user.
+func (r *UserRepositoryMemory) Save(_ context.Context, user domain.User) (domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// Mirror the unique index on email
+	for _, existing := range r.store.users {
+		if existing.Email == user.Email {
+			return domain.User{}, domain.ErrEmailExists
+		}
+	}
+
+	user.ID = r.store.nextID("users")
+	r.store.users[user.ID] = user
+
+	return user, nil
+}
+
+// FindByID finds a user by ID.
+func (r *UserRepositoryMemory) FindByID(_ context.Context, id uint) (domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// FindByEmail finds a user by email.
+func (r *UserRepositoryMemory) FindByEmail(_ context.Context, email string) (domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email && user.DeletedAt == nil {
+			return user, nil
+		}
+	}
+
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+// FindAll returns all users.
+func (r *UserRepositoryMemory) FindAll(_ context.Context) ([]domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]domain.User, 0, len(r.store.users))
+	for _, id := range sortedKeys(r.store.users) {
+		if user := r.store.users[id]; user.DeletedAt == nil {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// Update updates a user.
+func (r *UserRepositoryMemory) Update(_ context.Context, user domain.User) (domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.users[user.ID] = user
+
+	return user, nil
+}
+
+// Delete soft deletes a user.
+func (r *UserRepositoryMemory) Delete(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if user, ok := r.store.users[id]; ok && user.DeletedAt == nil {
+		now := time.Now()
+		user.DeletedAt = &now
+		r.store.users[id] = user
+	}
+
+	return nil
+}
+
+// FindDeleted returns all soft deleted users.
+func (r *UserRepositoryMemory) FindDeleted(_ context.Context) ([]domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]domain.User, 0)
+	for _, id := range sortedKeys(r.store.users) {
+		if user := r.store.users[id]; user.DeletedAt != nil {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// Restore restores a soft deleted user.
+func (r *UserRepositoryMemory) Restore(_ context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt == nil {
+		return domain.ErrUserNotFound
+	}
+
+	user.DeletedAt = nil
+	r.store.users[id] = user
+
+	return nil
+}
+
+// Purge permanently deletes users soft deleted before the given time
+// that are not referenced by any order.
+func (r *UserRepositoryMemory) Purge(_ context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	referenced := make(map[uint]bool)
+	for _, order := range r.store.orders {
+		referenced[order.UserID] = true
+	}
+
+	var purged int64
+	for id, user := range r.store.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(before) && !referenced[id] {
+			delete(r.store.users, id)
+			purged++
+		}
+	}
+
+	// The address book goes with its user, like the cascading foreign key
+	for id, address := range r.store.addresses {
+		if _, ok := r.store.users[address.UserID]; !ok {
+			delete(r.store.addresses, id)
+		}
+	}
+
+	return purged, nil
+}
+
+// ExistsByEmail checks if a user with given email exists, including soft deleted users.
+func (r *UserRepositoryMemory) ExistsByEmail(_ context.Context, email string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// WebhookEventRepositoryMemory implements domain.WebhookEventRepository in memory.
+type WebhookEventRepositoryMemory struct {
+	store *Store
+}
+
+// NewWebhookEventRepositoryMemory creates a new WebhookEventRepositoryMemory.
+func NewWebhookEventRepositoryMemory(store *Store) *WebhookEventRepositoryMemory {
+	return &WebhookEventRepositoryMemory{store: store}
+}
+
+// Create saves a new webhook event unless its event ID is already stored.
+func (r *WebhookEventRepositoryMemory) Create(_ context.Context, event domain.WebhookEvent) (domain.WebhookEvent, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.webhookEvents {
+		if existing.EventID == event.EventID {
+			return domain.WebhookEvent{}, domain.ErrWebhookEventExists
+		}
+	}
+
+	event.ID = r.store.nextID("webhook_events")
+	r.store.webhookEvents[event.ID] = event
+
+	return event, nil
+}
+
+// FindByEventID finds a webhook event by the provider's event ID.
+func (r *WebhookEventRepositoryMemory) FindByEventID(_ context.Context, eventID string) (domain.WebhookEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, existing := range r.store.webhookEvents {
+		if existing.EventID == eventID {
+			return existing, nil
+		}
+	}
+
+	return domain.WebhookEvent{}, domain.ErrWebhookEventNotFound
+}
+
+// FindAll returns all webhook events, oldest first.
+func (r *WebhookEventRepositoryMemory) FindAll(_ context.Context) ([]domain.WebhookEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	events := make([]domain.WebhookEvent, 0, len(r.store.webhookEvents))
+	for _, id := range sortedKeys(r.store.webhookEvents) {
+		events = append(events, r.store.webhookEvents[id])
+	}
+
+	return events, nil
+}
+
+// Claim marks an unprocessed webhook event as being processed.
+func (r *WebhookEventRepositoryMemory) Claim(_ context.Context, id uint, claimedAt, staleBefore time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.webhookEvents[id]
+	if !ok {
+		return domain.ErrWebhookEventNotFound
+	}
+	if event.IsProcessed() || event.ClaimedAt != nil && !event.ClaimedAt.Before(staleBefore) {
+		return domain.ErrWebhookEventInProgress
+	}
+	event.ClaimedAt = &claimedAt
+	r.store.webhookEvents[id] = event
+
+	return nil
+}
+
+// MarkProcessed records that a webhook event was processed.
+func (r *WebhookEventRepositoryMemory) MarkProcessed(_ context.Context, id uint, processedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.webhookEvents[id]
+	if !ok {
+		return domain.ErrWebhookEventNotFound
+	}
+	event.ProcessedAt = &processedAt
+	event.Error = ""
+	r.store.webhookEvents[id] = event
+
+	return nil
+}
+
+// MarkFailed records why processing a webhook event failed.
+func (r *WebhookEventRepositoryMemory) MarkFailed(_ context.Context, id uint, reason string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	event, ok := r.store.webhookEvents[id]
+	if !ok {
+		return domain.ErrWebhookEventNotFound
+	}
+	event.Error = reason
+	event.ClaimedAt = nil
+	r.store.webhookEvents[id] = event
+
+	return nil
+}
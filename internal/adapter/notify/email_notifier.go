@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// EmailNotifier implements domain.StockNotifier by emailing alerts to purchasing.
+type EmailNotifier struct {
+	mailer domain.Mailer
+	to     string
+}
+
+// NewEmailNotifier creates a new EmailNotifier sending to the given address.
+func NewEmailNotifier(mailer domain.Mailer, to string) *EmailNotifier {
+	return &EmailNotifier{
+		mailer: mailer,
+		to:     to,
+	}
+}
+
+// NotifyLowStock emails the alert.
+func (n *EmailNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	body := fmt.Sprintf(
+		"%q (book %d) is running low.\n\nAvailable: %d\nReorder threshold: %d\nSuggested reorder quantity: %d\n",
+		alert.Title,
+		alert.BookID,
+		alert.Available,
+		alert.ReorderThreshold,
+		alert.ReorderQuantity,
+	)
+
+	return n.mailer.Send(ctx, domain.Mail{
+		To:      n.to,
+		Subject: "Low stock: " + alert.Title,
+		Body:    body,
+	})
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// LogNotifier implements domain.StockNotifier by writing alerts to the log.
+// It is meant for local development - nothing leaves the machine.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new LogNotifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// NotifyLowStock writes the alert to the standard logger.
+func (n *LogNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("Low stock: book %d %q has %d available (threshold %d, reorder %d)",
+		alert.BookID,
+		alert.Title,
+		alert.Available,
+		alert.ReorderThreshold,
+		alert.ReorderQuantity,
+	)
+	return nil
+}
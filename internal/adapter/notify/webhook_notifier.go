@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// webhookTimeout bounds how long an order waits for the webhook.
+const webhookTimeout = 5 * time.Second
+
+// WebhookNotifier implements domain.StockNotifier by posting alerts as JSON to a URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// webhookPayload is the JSON body posted for a low-stock alert.
+type webhookPayload struct {
+	Event            string    `json:"event"`
+	BookID           uint      `json:"book_id"`
+	Title            string    `json:"title"`
+	Available        int       `json:"available"`
+	ReorderThreshold int       `json:"reorder_threshold"`
+	ReorderQuantity  int       `json:"reorder_quantity"`
+	OrderID          uint      `json:"order_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// NotifyLowStock posts the alert to the webhook URL.
+// Any response other than 2xx is an error.
+func (n *WebhookNotifier) NotifyLowStock(ctx context.Context, alert domain.LowStockAlert) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:            "book.low_stock",
+		BookID:           alert.BookID,
+		Title:            alert.Title,
+		Available:        alert.Available,
+		ReorderThreshold: alert.ReorderThreshold,
+		ReorderQuantity:  alert.ReorderQuantity,
+		OrderID:          alert.OrderID,
+		CreatedAt:        alert.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
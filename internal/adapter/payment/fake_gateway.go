@@ -0,0 +1,199 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// FakeOutcome is a scripted answer of FakeGateway.
+type FakeOutcome int
+
+// FakeOutcome constants.
+const (
+	FakeApprove FakeOutcome = iota
+	FakeDecline
+	FakeTimeout
+)
+
+// Payment tokens that make FakeGateway decline or time out an authorization
+// without scripting it, e.g. from HTTP clients in local development.
+const (
+	DeclineToken = "tok_decline"
+	TimeoutToken = "tok_timeout"
+)
+
+// FakeCall records a request made to FakeGateway.
+type FakeCall struct {
+	Operation string
+	Reference string
+	Amount    float64
+}
+
+// FakeGateway implements domain.PaymentGateway in process. It approves
+// everything unless told otherwise by Script or a magic token, and keeps
+// track of authorizations so it refuses to capture more than was held.
+// Nothing leaves the machine.
+type FakeGateway struct {
+	mu             sync.Mutex
+	script         []FakeOutcome
+	authorizations map[string]*fakeAuthorization
+	calls          []FakeCall
+	lastRef        int
+}
+
+// fakeAuthorization is the provider side state of an authorization.
+type fakeAuthorization struct {
+	amount   float64
+	captured bool
+	voided   bool
+	refunded float64
+}
+
+// NewFakeGateway creates a new FakeGateway.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{
+		authorizations: make(map[string]*fakeAuthorization),
+	}
+}
+
+// Script queues the outcomes of the next requests, one per request.
+func (g *FakeGateway) Script(outcomes ...FakeOutcome) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.script = append(g.script, outcomes...)
+}
+
+// Calls returns the requests made so far.
+func (g *FakeGateway) Calls() []FakeCall {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]FakeCall(nil), g.calls...)
+}
+
+// Authorize holds the amount; DeclineToken and TimeoutToken force a decline or timeout.
+func (g *FakeGateway) Authorize(ctx context.Context, request domain.PaymentRequest) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	outcome := g.next()
+	switch request.Token {
+	case DeclineToken:
+		outcome = FakeDecline
+	case TimeoutToken:
+		outcome = FakeTimeout
+	}
+
+	g.lastRef++
+	reference := fmt.Sprintf("fake_auth_%d", g.lastRef)
+	g.calls = append(g.calls, FakeCall{Operation: "authorize", Reference: reference, Amount: request.Amount})
+
+	if result, done, err := answer(ctx, outcome); done {
+		return result, err
+	}
+
+	g.authorizations[reference] = &fakeAuthorization{amount: request.Amount}
+	return domain.PaymentResult{Approved: true, Reference: reference}, nil
+}
+
+// Capture collects an authorization in full.
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount float64) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = append(g.calls, FakeCall{Operation: "capture", Reference: reference, Amount: amount})
+	if result, done, err := answer(ctx, g.next()); done {
+		return result, err
+	}
+
+	auth, ok := g.authorizations[reference]
+	switch {
+	case !ok:
+		return declined("unknown authorization"), nil
+	case auth.captured || auth.voided:
+		return declined("authorization already used"), nil
+	case amount > auth.amount:
+		return declined("amount exceeds authorization"), nil
+	}
+
+	auth.captured = true
+	return domain.PaymentResult{Approved: true, Reference: reference}, nil
+}
+
+// Refund returns part or all of a captured amount.
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount float64) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = append(g.calls, FakeCall{Operation: "refund", Reference: reference, Amount: amount})
+	if result, done, err := answer(ctx, g.next()); done {
+		return result, err
+	}
+
+	auth, ok := g.authorizations[reference]
+	switch {
+	case !ok || !auth.captured:
+		return declined("nothing captured to refund"), nil
+	case auth.refunded+amount > auth.amount:
+		return declined("amount exceeds captured amount"), nil
+	}
+
+	auth.refunded += amount
+	return domain.PaymentResult{Approved: true, Reference: reference}, nil
+}
+
+// Void releases an authorization that was not captured.
+func (g *FakeGateway) Void(ctx context.Context, reference string) (domain.PaymentResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = append(g.calls, FakeCall{Operation: "void", Reference: reference})
+	if result, done, err := answer(ctx, g.next()); done {
+		return result, err
+	}
+
+	auth, ok := g.authorizations[reference]
+	if !ok || auth.captured {
+		return declined("authorization cannot be voided"), nil
+	}
+
+	auth.voided = true
+	return domain.PaymentResult{Approved: true, Reference: reference}, nil
+}
+
+// next pops the next scripted outcome. Callers must hold the lock.
+func (g *FakeGateway) next() FakeOutcome {
+	if len(g.script) == 0 {
+		return FakeApprove
+	}
+
+	outcome := g.script[0]
+	g.script = g.script[1:]
+	return outcome
+}
+
+// answer returns the response for a decline or timeout outcome, and
+// whether the request is answered by it.
+func answer(ctx context.Context, outcome FakeOutcome) (domain.PaymentResult, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.PaymentResult{}, true, err
+	}
+
+	switch outcome {
+	case FakeDecline:
+		return declined("card declined"), true, nil
+	case FakeTimeout:
+		return domain.PaymentResult{}, true, domain.ErrPaymentTimeout
+	default:
+		return domain.PaymentResult{}, false, nil
+	}
+}
+
+// declined builds a declined result.
+func declined(reason string) domain.PaymentResult {
+	return domain.PaymentResult{DeclineReason: reason}
+}
@@ -0,0 +1,1596 @@
+// Package repotest provides a contract test suite for implementations of the
+// domain repository ports. Every adapter must pass the same suite so usecases
+// behave identically whichever adapter they run against.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// Repositories groups the repository ports under test.
+type Repositories struct {
+	Books           domain.BookRepository
+	Users           domain.UserRepository
+	Orders          domain.OrderRepository
+	StockMovements  domain.StockMovementRepository
+	Reservations    domain.ReservationRepository
+	IdempotencyKeys domain.IdempotencyKeyRepository
+	Payments        domain.PaymentRepository
+	WebhookEvents   domain.WebhookEventRepository
+	ReturnRequests  domain.ReturnRequestRepository
+	Promotions      domain.PromotionRepository
+	BookPrices      domain.BookPriceRepository
+	Addresses       domain.AddressRepository
+	OrderShipments  domain.OrderShipmentRepository
+}
+
+// Factory returns repositories backed by an empty store.
+// It is called once per subtest.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the full repository contract suite.
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Book", func(t *testing.T) { RunBook(t, newRepos) })
+	t.Run("User", func(t *testing.T) { RunUser(t, newRepos) })
+	t.Run("Order", func(t *testing.T) { RunOrder(t, newRepos) })
+	t.Run("Reservation", func(t *testing.T) { RunReservation(t, newRepos) })
+	t.Run("IdempotencyKey", func(t *testing.T) { RunIdempotencyKey(t, newRepos) })
+	t.Run("Payment", func(t *testing.T) { RunPayment(t, newRepos) })
+	t.Run("WebhookEvent", func(t *testing.T) { RunWebhookEvent(t, newRepos) })
+	t.Run("ReturnRequest", func(t *testing.T) { RunReturnRequest(t, newRepos) })
+	t.Run("Promotion", func(t *testing.T) { RunPromotion(t, newRepos) })
+	t.Run("BookPrice", func(t *testing.T) { RunBookPrice(t, newRepos) })
+	t.Run("Address", func(t *testing.T) { RunAddress(t, newRepos) })
+	t.Run("OrderShipment", func(t *testing.T) { RunOrderShipment(t, newRepos) })
+}
+
+// RunBook runs the BookRepository contract.
+func RunBook(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveAssignsIDAndVersion", func(t *testing.T) {
+		repos := newRepos(t)
+
+		saved := mustSaveBook(t, repos, "Clean Architecture", 10)
+		if saved.ID == 0 {
+			t.Fatal("expected ID to be assigned")
+		}
+		if saved.Version != 1 {
+			t.Fatalf("expected version 1, got %d", saved.Version)
+		}
+
+		found, err := repos.Books.FindByID(ctx, saved.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if found.Title != saved.Title || found.Stock != saved.Stock {
+			t.Fatalf("expected %+v, got %+v", saved, found)
+		}
+	})
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		if _, err := repos.Books.FindByID(ctx, 999); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindAll", func(t *testing.T) {
+		repos := newRepos(t)
+		mustSaveBook(t, repos, "A", 1)
+		mustSaveBook(t, repos, "B", 2)
+		mustSaveBook(t, repos, "C", 3)
+
+		books, total, err := repos.Books.FindAll(ctx, domain.Page{})
+		if err != nil {
+			t.Fatalf("FindAll: %v", err)
+		}
+		if len(books) != 3 || total != 3 {
+			t.Fatalf("expected 3 books, got %d of %d", len(books), total)
+		}
+
+		// A page holds the books after the offset, oldest first
+		books, total, err = repos.Books.FindAll(ctx, domain.Page{Limit: 2, Offset: 1})
+		if err != nil || total != 3 || len(books) != 2 || books[0].Title != "B" || books[1].Title != "C" {
+			t.Fatalf("expected books B and C of 3, got %+v of %d, %v", books, total, err)
+		}
+		if books, total, _ := repos.Books.FindAll(ctx, domain.Page{Limit: 2, Offset: 4}); len(books) != 0 || total != 3 {
+			t.Fatalf("expected no books past the end, got %+v of %d", books, total)
+		}
+	})
+
+	t.Run("UpdateIncrementsVersion", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+
+		book.Title = "B"
+		book.Stock = 5
+		updated, err := repos.Books.Update(ctx, book)
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if updated.Version != 2 {
+			t.Fatalf("expected version 2, got %d", updated.Version)
+		}
+
+		// Stock only changes through UpdateStock
+		found, _ := repos.Books.FindByID(ctx, book.ID)
+		if found.Title != "B" || found.Stock != 1 || found.Version != 2 {
+			t.Fatalf("unexpected book after update: %+v", found)
+		}
+	})
+
+	t.Run("UpdateStaleVersionConflicts", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+
+		if _, err := repos.Books.Update(ctx, book); err != nil {
+			t.Fatalf("first Update: %v", err)
+		}
+		if _, err := repos.Books.Update(ctx, book); !errors.Is(err, domain.ErrBookConflict) {
+			t.Fatalf("expected ErrBookConflict, got %v", err)
+		}
+	})
+
+	t.Run("UpdateMissingNotFound", func(t *testing.T) {
+		repos := newRepos(t)
+
+		_, err := repos.Books.Update(ctx, domain.Book{ID: 999, Title: "X", Price: 1, Version: 1})
+		if !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateStock", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 5)
+
+		updated, err := repos.Books.UpdateStock(ctx, book.ID, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error {
+			return b.DecreaseStock(2)
+		})
+		if err != nil {
+			t.Fatalf("UpdateStock: %v", err)
+		}
+		if updated.Stock != 3 || updated.Version != 2 {
+			t.Fatalf("unexpected book: %+v", updated)
+		}
+
+		_, err = repos.Books.UpdateStock(ctx, book.ID, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error {
+			return b.DecreaseStock(10)
+		})
+		if !errors.Is(err, domain.ErrInsufficientStock) {
+			t.Fatalf("expected ErrInsufficientStock, got %v", err)
+		}
+
+		found, _ := repos.Books.FindByID(ctx, book.ID)
+		if found.Stock != 3 {
+			t.Fatalf("expected failed change to be rolled back, got stock %d", found.Stock)
+		}
+
+		_, err = repos.Books.UpdateStock(ctx, 999, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error { return nil })
+		if !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateStockIsSerialized", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 5)
+
+		const workers = 10
+		errs := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			go func() {
+				_, err := repos.Books.UpdateStock(ctx, book.ID, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error {
+					return b.DecreaseStock(1)
+				})
+				errs <- err
+			}()
+		}
+
+		var succeeded int
+		for i := 0; i < workers; i++ {
+			if err := <-errs; err == nil {
+				succeeded++
+			} else if !errors.Is(err, domain.ErrInsufficientStock) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		found, _ := repos.Books.FindByID(ctx, book.ID)
+		if succeeded != 5 || found.Stock != 0 {
+			t.Fatalf("expected 5 successful decrements down to 0, got %d and stock %d", succeeded, found.Stock)
+		}
+	})
+
+	t.Run("StockChangesAreRecorded", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 5)
+
+		movement := domain.NewStockMovement(domain.StockReasonOrder, "user:1", "order:1")
+		if _, err := repos.Books.UpdateStock(ctx, book.ID, movement, func(b *domain.Book) error {
+			return b.DecreaseStock(2)
+		}); err != nil {
+			t.Fatalf("UpdateStock: %v", err)
+		}
+
+		// Failed and no-op changes leave no trace
+		_, _ = repos.Books.UpdateStock(ctx, book.ID, movement, func(b *domain.Book) error {
+			return b.DecreaseStock(10)
+		})
+		_, _ = repos.Books.UpdateStock(ctx, book.ID, movement, func(b *domain.Book) error { return nil })
+
+		movements, err := repos.StockMovements.FindByBookID(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindByBookID: %v", err)
+		}
+		if len(movements) != 2 {
+			t.Fatalf("expected initial stock and order movements, got %+v", movements)
+		}
+		if initial := movements[0]; initial.Reason != domain.StockReasonRestock || initial.Delta != 5 || initial.Balance != 5 {
+			t.Fatalf("unexpected initial movement: %+v", initial)
+		}
+		order := movements[1]
+		if order.Reason != domain.StockReasonOrder || order.Delta != -2 || order.Balance != 3 ||
+			order.Actor != "user:1" || order.Reference != "order:1" || order.CreatedAt.IsZero() {
+			t.Fatalf("unexpected order movement: %+v", order)
+		}
+
+		balances, err := repos.StockMovements.Balances(ctx)
+		if err != nil {
+			t.Fatalf("Balances: %v", err)
+		}
+		if balances[book.ID] != 3 {
+			t.Fatalf("expected ledger balance 3, got %d", balances[book.ID])
+		}
+	})
+
+	t.Run("ReservationsArePersistedOutsideLedger", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 5)
+
+		reserved, err := repos.Books.UpdateStock(ctx, book.ID, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error {
+			return b.Reserve(3)
+		})
+		if err != nil {
+			t.Fatalf("UpdateStock: %v", err)
+		}
+		if reserved.Stock != 5 || reserved.Reserved != 3 || reserved.Available() != 2 {
+			t.Fatalf("unexpected book: %+v", reserved)
+		}
+
+		// Update keeps reservations like it keeps stock
+		reserved.Title = "B"
+		reserved.Reserved = 0
+		if _, err := repos.Books.Update(ctx, reserved); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, _ := repos.Books.FindByID(ctx, book.ID)
+		if found.Title != "B" || found.Reserved != 3 {
+			t.Fatalf("unexpected book after update: %+v", found)
+		}
+
+		if movements, _ := repos.StockMovements.FindByBookID(ctx, book.ID); len(movements) != 1 {
+			t.Fatalf("expected only the initial movement, got %+v", movements)
+		}
+	})
+
+	t.Run("ReconcileStockWithoutDrift", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 5)
+
+		movement := domain.NewStockMovement(domain.StockReasonReconcile, "system", "")
+		before, ledger, err := repos.Books.ReconcileStock(ctx, book.ID, movement)
+		if err != nil {
+			t.Fatalf("ReconcileStock: %v", err)
+		}
+		if before.Stock != 5 || ledger != 5 {
+			t.Fatalf("unexpected reconcile: stock %d, ledger %d", before.Stock, ledger)
+		}
+
+		found, _ := repos.Books.FindByID(ctx, book.ID)
+		if found.Stock != 5 || found.Version != book.Version {
+			t.Fatalf("unexpected book: %+v", found)
+		}
+		if movements, _ := repos.StockMovements.FindByBookID(ctx, book.ID); len(movements) != 1 {
+			t.Fatalf("expected only the initial movement, got %+v", movements)
+		}
+
+		if _, _, err := repos.Books.ReconcileStock(ctx, 999, movement); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected ErrBookNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		repos := newRepos(t)
+		mustSaveBook(t, repos, "Clean Architecture", 1)
+		mustSaveBook(t, repos, "Clean Code", 1)
+		mustSaveBook(t, repos, "Domain Driven Design", 1)
+
+		tests := []struct {
+			query string
+			want  int
+		}{
+			{query: "clean", want: 2},
+			{query: "Architecture", want: 1},
+			{query: "golang", want: 0},
+		}
+		for _, tt := range tests {
+			books, total, err := repos.Books.Search(ctx, tt.query, domain.Page{})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", tt.query, err)
+			}
+			if len(books) != tt.want || total != int64(tt.want) {
+				t.Fatalf("Search(%q): expected %d books, got %d of %d", tt.query, tt.want, len(books), total)
+			}
+		}
+
+		// Matches are counted across pages
+		books, total, err := repos.Books.Search(ctx, "clean", domain.Page{Limit: 1})
+		if err != nil || len(books) != 1 || total != 2 {
+			t.Fatalf("expected one book of 2 matches, got %+v of %d, %v", books, total, err)
+		}
+	})
+
+	t.Run("FindLowStock", func(t *testing.T) {
+		repos := newRepos(t)
+		mustSaveBook(t, repos, "No threshold", 0)
+
+		plenty := domain.NewBook("Plenty", 10, 10)
+		plenty.ReorderThreshold = 5
+		if _, err := repos.Books.Save(ctx, plenty, domain.NewInitialStock("")); err != nil {
+			t.Fatalf("save book: %v", err)
+		}
+
+		// Reserved stock does not count as available
+		low := domain.NewBook("Low", 10, 6)
+		low.ReorderThreshold = 5
+		low.ReorderQuantity = 20
+		low, err := repos.Books.Save(ctx, low, domain.NewInitialStock(""))
+		if err != nil {
+			t.Fatalf("save book: %v", err)
+		}
+		if _, err := repos.Books.UpdateStock(ctx, low.ID, domain.StockMovement{Reason: domain.StockReasonOrder}, func(b *domain.Book) error {
+			return b.Reserve(2)
+		}); err != nil {
+			t.Fatalf("UpdateStock: %v", err)
+		}
+
+		books, err := repos.Books.FindLowStock(ctx)
+		if err != nil {
+			t.Fatalf("FindLowStock: %v", err)
+		}
+		if len(books) != 1 || books[0].ID != low.ID || books[0].ReorderQuantity != 20 {
+			t.Fatalf("expected only the low book, got %+v", books)
+		}
+	})
+
+	t.Run("BackorderSettingsArePersisted", func(t *testing.T) {
+		repos := newRepos(t)
+
+		release := time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)
+		book := domain.NewBook("A", 10, 0)
+		book.AllowPreorder = true
+		book.ReleaseDate = &release
+		saved, err := repos.Books.Save(ctx, book, domain.NewInitialStock(""))
+		if err != nil {
+			t.Fatalf("save book: %v", err)
+		}
+
+		found, _ := repos.Books.FindByID(ctx, saved.ID)
+		if !found.AllowPreorder || found.ReleaseDate == nil || !found.ReleaseDate.Equal(release) {
+			t.Fatalf("unexpected book after save: %+v", found)
+		}
+
+		found.AllowPreorder = false
+		found.AllowBackorder = true
+		found.ReleaseDate = nil
+		if _, err := repos.Books.Update(ctx, found); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, _ = repos.Books.FindByID(ctx, saved.ID)
+		if found.AllowPreorder || !found.AllowBackorder || found.ReleaseDate != nil {
+			t.Fatalf("unexpected book after update: %+v", found)
+		}
+	})
+
+	t.Run("SoftDeleteAndRestore", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+
+		if err := repos.Books.Delete(ctx, book.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Books.FindByID(ctx, book.ID); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected deleted book to be hidden, got %v", err)
+		}
+		if books, total, _ := repos.Books.FindAll(ctx, domain.Page{}); len(books) != 0 || total != 0 {
+			t.Fatalf("expected FindAll to exclude deleted book, got %d", len(books))
+		}
+
+		deleted, err := repos.Books.FindDeleted(ctx)
+		if err != nil {
+			t.Fatalf("FindDeleted: %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].DeletedAt == nil {
+			t.Fatalf("expected one deleted book, got %+v", deleted)
+		}
+
+		if err := repos.Books.Restore(ctx, book.ID); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if _, err := repos.Books.FindByID(ctx, book.ID); err != nil {
+			t.Fatalf("expected restored book, got %v", err)
+		}
+		if err := repos.Books.Restore(ctx, book.ID); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected restoring live book to fail, got %v", err)
+		}
+	})
+
+	t.Run("PurgeKeepsReferencedAndRecent", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		ordered := mustSaveBook(t, repos, "Ordered", 1)
+		unused := mustSaveBook(t, repos, "Unused", 1)
+		mustSaveOrder(t, repos, user.ID, ordered.ID)
+
+		_ = repos.Books.Delete(ctx, ordered.ID)
+		_ = repos.Books.Delete(ctx, unused.ID)
+
+		purged, err := repos.Books.Purge(ctx, time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if purged != 0 {
+			t.Fatalf("expected recent deletions to be kept, purged %d", purged)
+		}
+
+		purged, err = repos.Books.Purge(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if purged != 1 {
+			t.Fatalf("expected only the unreferenced book to be purged, purged %d", purged)
+		}
+		if err := repos.Books.Restore(ctx, ordered.ID); err != nil {
+			t.Fatalf("expected referenced book to survive purge, got %v", err)
+		}
+	})
+}
+
+// RunUser runs the UserRepository contract.
+func RunUser(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		saved := mustSaveUser(t, repos, "a@example.com")
+
+		byID, err := repos.Users.FindByID(ctx, saved.ID)
+		if err != nil || byID.Email != "a@example.com" {
+			t.Fatalf("FindByID: %+v, %v", byID, err)
+		}
+
+		byEmail, err := repos.Users.FindByEmail(ctx, "a@example.com")
+		if err != nil || byEmail.ID != saved.ID {
+			t.Fatalf("FindByEmail: %+v, %v", byEmail, err)
+		}
+
+		if _, err := repos.Users.FindByEmail(ctx, "missing@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+
+		user.MarkVerified()
+		if _, err := repos.Users.Update(ctx, user); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, _ := repos.Users.FindByID(ctx, user.ID)
+		if !found.Verified {
+			t.Fatal("expected verified flag to be persisted")
+		}
+	})
+
+	t.Run("ExistsByEmailIncludesDeleted", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+
+		if exists, _ := repos.Users.ExistsByEmail(ctx, "a@example.com"); !exists {
+			t.Fatal("expected email to exist")
+		}
+
+		_ = repos.Users.Delete(ctx, user.ID)
+		if exists, _ := repos.Users.ExistsByEmail(ctx, "a@example.com"); !exists {
+			t.Fatal("expected email of deleted user to stay reserved")
+		}
+		if exists, _ := repos.Users.ExistsByEmail(ctx, "b@example.com"); exists {
+			t.Fatal("expected unknown email not to exist")
+		}
+	})
+
+	t.Run("SoftDeleteAndRestore", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+
+		_ = repos.Users.Delete(ctx, user.ID)
+		if _, err := repos.Users.FindByEmail(ctx, "a@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("expected deleted user to be hidden, got %v", err)
+		}
+		if deleted, _ := repos.Users.FindDeleted(ctx); len(deleted) != 1 {
+			t.Fatalf("expected one deleted user, got %d", len(deleted))
+		}
+
+		if err := repos.Users.Restore(ctx, user.ID); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if users, _ := repos.Users.FindAll(ctx); len(users) != 1 {
+			t.Fatalf("expected restored user in FindAll, got %d", len(users))
+		}
+	})
+}
+
+// RunOrder runs the OrderRepository contract.
+func RunOrder(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := mustSaveUser(t, repos, "alice@example.com")
+		bob := mustSaveUser(t, repos, "bob@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+
+		order := mustSaveOrder(t, repos, alice.ID, book.ID)
+		mustSaveOrder(t, repos, bob.ID, book.ID)
+
+		found, err := repos.Orders.FindByID(ctx, order.ID)
+		if err != nil || found.UserID != alice.ID || found.Status != domain.OrderStatusPending {
+			t.Fatalf("FindByID: %+v, %v", found, err)
+		}
+
+		if _, err := repos.Orders.FindByID(ctx, 999); !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+
+		if orders, _ := repos.Orders.FindByUserID(ctx, alice.ID); len(orders) != 1 {
+			t.Fatalf("expected 1 order for alice, got %d", len(orders))
+		}
+		if orders, total, _ := repos.Orders.FindAll(ctx, domain.Page{}); len(orders) != 2 || total != 2 {
+			t.Fatalf("expected 2 orders, got %d of %d", len(orders), total)
+		}
+		orders, total, err := repos.Orders.FindAll(ctx, domain.Page{Limit: 1, Offset: 1})
+		if err != nil || len(orders) != 1 || total != 2 || orders[0].UserID != bob.ID {
+			t.Fatalf("expected bob's order on the second page, got %+v of %d, %v", orders, total, err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		order.Complete()
+		if _, err := repos.Orders.Update(ctx, order); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, _ := repos.Orders.FindByID(ctx, order.ID)
+		if found.Status != domain.OrderStatusCompleted {
+			t.Fatalf("expected completed, got %s", found.Status)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		if err := repos.Orders.UpdateStatus(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusBackordered); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if found, _ := repos.Orders.FindByID(ctx, order.ID); !found.IsBackordered() {
+			t.Fatalf("expected backordered, got %s", found.Status)
+		}
+
+		// Only one caller can move the order out of a status
+		err := repos.Orders.UpdateStatus(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusCancelled)
+		if !errors.Is(err, domain.ErrOrderStatusChanged) {
+			t.Fatalf("expected ErrOrderStatusChanged, got %v", err)
+		}
+
+		err = repos.Orders.UpdateStatus(ctx, 999, domain.OrderStatusPending, domain.OrderStatusCancelled)
+		if !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
+
+	t.Run("UpdateRefund", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		_ = repos.Orders.UpdateStatus(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusCompleted)
+		order, _ = repos.Orders.FindByID(ctx, order.ID)
+
+		refunded := order
+		refunded.RefundedAmount = 5
+		refunded.Status = domain.OrderStatusPartiallyRefunded
+		if err := repos.Orders.UpdateRefund(ctx, order, refunded); err != nil {
+			t.Fatalf("UpdateRefund: %v", err)
+		}
+		found, _ := repos.Orders.FindByID(ctx, order.ID)
+		if found.RefundedAmount != 5 || found.Status != domain.OrderStatusPartiallyRefunded {
+			t.Fatalf("expected the refund to be recorded, got %+v", found)
+		}
+
+		// A refund computed from a stale read does not overwrite the first
+		stale := order
+		stale.RefundedAmount = 3
+		stale.Status = domain.OrderStatusPartiallyRefunded
+		if err := repos.Orders.UpdateRefund(ctx, order, stale); !errors.Is(err, domain.ErrOrderStatusChanged) {
+			t.Fatalf("expected ErrOrderStatusChanged, got %v", err)
+		}
+		if found, _ := repos.Orders.FindByID(ctx, order.ID); found.RefundedAmount != 5 {
+			t.Fatalf("expected the first refund to stand, got %+v", found)
+		}
+
+		order.ID = 999
+		if err := repos.Orders.UpdateRefund(ctx, order, stale); !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindBackordered", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 0)
+		other := mustSaveBook(t, repos, "B", 0)
+
+		var queued []uint
+		for _, bookID := range []uint{book.ID, other.ID, book.ID, book.ID} {
+			order := domain.NewOrder(user.ID, bookID, 1, 10)
+			order.Backorder()
+			saved, err := repos.Orders.Save(ctx, order)
+			if err != nil {
+				t.Fatalf("save order: %v", err)
+			}
+			if bookID == book.ID {
+				queued = append(queued, saved.ID)
+			}
+		}
+		mustSaveOrder(t, repos, user.ID, book.ID)
+		if err := repos.Orders.Delete(ctx, queued[2]); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		orders, err := repos.Orders.FindBackordered(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("FindBackordered: %v", err)
+		}
+		if len(orders) != 2 || orders[0].ID != queued[0] || orders[1].ID != queued[1] {
+			t.Fatalf("expected orders %v oldest first, got %+v", queued[:2], orders)
+		}
+	})
+
+	t.Run("CountOpen", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		other := mustSaveBook(t, repos, "B", 10)
+		mustSaveOrder(t, repos, user.ID, book.ID)
+		backordered := mustSaveOrder(t, repos, user.ID, book.ID)
+		shipped := mustSaveOrder(t, repos, user.ID, book.ID)
+		completed := mustSaveOrder(t, repos, user.ID, book.ID)
+		cancelled := mustSaveOrder(t, repos, user.ID, book.ID)
+		_ = repos.Orders.UpdateStatus(ctx, backordered.ID, domain.OrderStatusPending, domain.OrderStatusBackordered)
+		_ = repos.Orders.UpdateStatus(ctx, shipped.ID, domain.OrderStatusPending, domain.OrderStatusShipped)
+		_ = repos.Orders.UpdateStatus(ctx, completed.ID, domain.OrderStatusPending, domain.OrderStatusCompleted)
+		_ = repos.Orders.UpdateStatus(ctx, cancelled.ID, domain.OrderStatusPending, domain.OrderStatusCancelled)
+
+		if count, _ := repos.Orders.CountOpenByBookID(ctx, book.ID); count != 3 {
+			t.Fatalf("expected 3 open orders for book, got %d", count)
+		}
+		if count, _ := repos.Orders.CountOpenByBookID(ctx, other.ID); count != 0 {
+			t.Fatalf("expected 0 open orders for other book, got %d", count)
+		}
+		if count, _ := repos.Orders.CountOpenByUserID(ctx, user.ID); count != 3 {
+			t.Fatalf("expected 3 open orders for user, got %d", count)
+		}
+	})
+
+	t.Run("SoftDeleteRestoreAndPurge", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		if orders, _, _ := repos.Orders.FindAll(ctx, domain.Page{}); len(orders) != 0 {
+			t.Fatalf("expected deleted order to be hidden, got %d", len(orders))
+		}
+		if err := repos.Orders.Restore(ctx, order.ID); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		purged, err := repos.Orders.Purge(ctx, time.Now().Add(time.Hour))
+		if err != nil || purged != 1 {
+			t.Fatalf("expected 1 purged order, got %d, %v", purged, err)
+		}
+		if err := repos.Orders.Restore(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("expected purged order to be gone, got %v", err)
+		}
+	})
+
+	t.Run("FindOrphanedEmpty", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		mustSaveOrder(t, repos, user.ID, book.ID)
+
+		orphans, err := repos.Orders.FindOrphaned(ctx)
+		if err != nil {
+			t.Fatalf("FindOrphaned: %v", err)
+		}
+		if len(orphans) != 0 {
+			t.Fatalf("expected no orphans, got %+v", orphans)
+		}
+	})
+}
+
+// RunReservation runs the ReservationRepository contract.
+func RunReservation(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveFindAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		saved, err := repos.Reservations.Save(ctx, domain.NewReservation(order, time.Hour))
+		if err != nil || saved.ID == 0 {
+			t.Fatalf("Save: %+v, %v", saved, err)
+		}
+
+		found, err := repos.Reservations.FindByOrderID(ctx, order.ID)
+		if err != nil || found.ID != saved.ID || found.BookID != book.ID || found.Quantity != order.Quantity {
+			t.Fatalf("FindByOrderID: %+v, %v", found, err)
+		}
+
+		if err := repos.Reservations.Delete(ctx, saved.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		// A reservation can only be consumed once
+		if err := repos.Reservations.Delete(ctx, saved.ID); !errors.Is(err, domain.ErrReservationNotFound) {
+			t.Fatalf("expected ErrReservationNotFound, got %v", err)
+		}
+		if _, err := repos.Reservations.FindByOrderID(ctx, order.ID); !errors.Is(err, domain.ErrReservationNotFound) {
+			t.Fatalf("expected ErrReservationNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FindExpired", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+
+		expired := domain.NewReservation(mustSaveOrder(t, repos, user.ID, book.ID), -time.Minute)
+		if _, err := repos.Reservations.Save(ctx, expired); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		active := domain.NewReservation(mustSaveOrder(t, repos, user.ID, book.ID), time.Hour)
+		if _, err := repos.Reservations.Save(ctx, active); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		found, err := repos.Reservations.FindExpired(ctx, time.Now())
+		if err != nil {
+			t.Fatalf("FindExpired: %v", err)
+		}
+		if len(found) != 1 || found[0].OrderID != expired.OrderID {
+			t.Fatalf("expected only the expired reservation, got %+v", found)
+		}
+	})
+
+	t.Run("PurgedOrderDropsReservation", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 10)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		if _, err := repos.Reservations.Save(ctx, domain.NewReservation(order, time.Hour)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		if _, err := repos.Orders.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if _, err := repos.Reservations.FindByOrderID(ctx, order.ID); !errors.Is(err, domain.ErrReservationNotFound) {
+			t.Fatalf("expected reservation to be purged with its order, got %v", err)
+		}
+	})
+}
+
+// RunIdempotencyKey runs the IdempotencyKeyRepository contract.
+func RunIdempotencyKey(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateClaimsKeyOnce", func(t *testing.T) {
+		repos := newRepos(t)
+
+		claimed, err := repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("key-1", "a", time.Hour))
+		if err != nil || claimed.ID == 0 {
+			t.Fatalf("Create: %+v, %v", claimed, err)
+		}
+		if _, err := repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("key-1", "b", time.Hour)); !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+			t.Fatalf("expected ErrIdempotencyKeyExists, got %v", err)
+		}
+
+		found, err := repos.IdempotencyKeys.FindByKey(ctx, "key-1")
+		if err != nil || found.ID != claimed.ID || found.Fingerprint != "a" || found.IsCompleted() {
+			t.Fatalf("FindByKey: %+v, %v", found, err)
+		}
+		if _, err := repos.IdempotencyKeys.FindByKey(ctx, "key-2"); !errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			t.Fatalf("expected ErrIdempotencyKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("CompleteAndDelete", func(t *testing.T) {
+		repos := newRepos(t)
+		claimed, _ := repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("key-1", "a", time.Hour))
+
+		if err := repos.IdempotencyKeys.Complete(ctx, claimed.ID, 201, []byte(`{"id":1}`)); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		found, _ := repos.IdempotencyKeys.FindByKey(ctx, "key-1")
+		if found.StatusCode != 201 || string(found.ResponseBody) != `{"id":1}` {
+			t.Fatalf("unexpected key: %+v", found)
+		}
+
+		if err := repos.IdempotencyKeys.Delete(ctx, claimed.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("key-1", "b", time.Hour)); err != nil {
+			t.Fatalf("expected deleted key to be claimable again, got %v", err)
+		}
+		if err := repos.IdempotencyKeys.Complete(ctx, claimed.ID, 201, nil); !errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			t.Fatalf("expected ErrIdempotencyKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repos := newRepos(t)
+		now := time.Now()
+		_, _ = repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("old", "a", time.Minute))
+		_, _ = repos.IdempotencyKeys.Create(ctx, domain.NewIdempotencyKey("new", "a", time.Hour))
+
+		deleted, err := repos.IdempotencyKeys.DeleteExpired(ctx, now.Add(2*time.Minute))
+		if err != nil || deleted != 1 {
+			t.Fatalf("expected 1 deleted key, got %d, %v", deleted, err)
+		}
+		if _, err := repos.IdempotencyKeys.FindByKey(ctx, "old"); !errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			t.Fatalf("expected old key to be gone, got %v", err)
+		}
+		if _, err := repos.IdempotencyKeys.FindByKey(ctx, "new"); err != nil {
+			t.Fatalf("expected new key to be kept, got %v", err)
+		}
+	})
+}
+
+// RunPayment runs the PaymentRepository contract.
+func RunPayment(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveUpdateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		declined, err := repos.Payments.Save(ctx, domain.NewPayment(order))
+		if err != nil || declined.ID == 0 || declined.Status != domain.PaymentStatusPending {
+			t.Fatalf("Save: %+v, %v", declined, err)
+		}
+		declined.Decline("card declined")
+		if _, err := repos.Payments.Update(ctx, declined); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		captured, _ := repos.Payments.Save(ctx, domain.NewPayment(order))
+		captured.Authorize("auth_1")
+		captured.Capture()
+		if _, err := repos.Payments.Update(ctx, captured); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, err := repos.Payments.FindByID(ctx, captured.ID)
+		if err != nil || found.Status != domain.PaymentStatusCaptured || found.Reference != "auth_1" || found.Amount != order.Total {
+			t.Fatalf("FindByID: %+v, %v", found, err)
+		}
+		if _, err := repos.Payments.FindByID(ctx, 999); !errors.Is(err, domain.ErrPaymentNotFound) {
+			t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+		}
+
+		payments, err := repos.Payments.FindByOrderID(ctx, order.ID)
+		if err != nil || len(payments) != 2 {
+			t.Fatalf("FindByOrderID: %+v, %v", payments, err)
+		}
+		if payments[0].ID != declined.ID || payments[0].FailureReason != "card declined" || payments[1].ID != captured.ID {
+			t.Fatalf("expected payments oldest first, got %+v", payments)
+		}
+	})
+
+	t.Run("OnePaymentHoldsAnOrder", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		other := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		timedOut, _ := repos.Payments.Save(ctx, domain.NewPayment(order))
+		if _, err := repos.Payments.Save(ctx, domain.NewPayment(order)); !errors.Is(err, domain.ErrPaymentExists) {
+			t.Fatalf("expected ErrPaymentExists while a payment is pending, got %v", err)
+		}
+		if _, err := repos.Payments.Save(ctx, domain.NewPayment(other)); err != nil {
+			t.Fatalf("expected another order to be paid independently, got %v", err)
+		}
+
+		timedOut.Fail("gateway timeout")
+		if _, err := repos.Payments.Update(ctx, timedOut); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		retry, err := repos.Payments.Save(ctx, domain.NewPayment(order))
+		if err != nil {
+			t.Fatalf("expected a retry once the payment failed, got %v", err)
+		}
+		retry.Authorize("auth_2")
+		if _, err := repos.Payments.Update(ctx, retry); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		timedOut.Authorize("auth_1")
+		if _, err := repos.Payments.Update(ctx, timedOut); !errors.Is(err, domain.ErrPaymentExists) {
+			t.Fatalf("expected ErrPaymentExists for a late authorization, got %v", err)
+		}
+		retry.Void()
+		if _, err := repos.Payments.Update(ctx, retry); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if _, err := repos.Payments.Update(ctx, timedOut); err != nil {
+			t.Fatalf("expected the late authorization once the order was released, got %v", err)
+		}
+	})
+
+	t.Run("UpdateRefund", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		captured, _ := repos.Payments.Save(ctx, domain.NewPayment(order))
+		captured.Authorize("auth_1")
+		captured.Capture()
+		captured, _ = repos.Payments.Update(ctx, captured)
+
+		partial := captured
+		partial.RecordRefunded(1)
+		if err := repos.Payments.UpdateRefund(ctx, captured, partial); err != nil {
+			t.Fatalf("UpdateRefund: %v", err)
+		}
+		found, _ := repos.Payments.FindByID(ctx, captured.ID)
+		if found.RefundedAmount != 1 || found.Status != domain.PaymentStatusCaptured {
+			t.Fatalf("expected the refund to be recorded, got %+v", found)
+		}
+
+		// A refund computed from a stale read does not overwrite the first
+		if err := repos.Payments.UpdateRefund(ctx, captured, partial); !errors.Is(err, domain.ErrPaymentChanged) {
+			t.Fatalf("expected ErrPaymentChanged, got %v", err)
+		}
+
+		// A refunded payment no longer holds its order
+		refunded := found
+		refunded.RecordRefunded(order.Total)
+		if err := repos.Payments.UpdateRefund(ctx, found, refunded); err != nil {
+			t.Fatalf("UpdateRefund: %v", err)
+		}
+		if found, _ := repos.Payments.FindByID(ctx, captured.ID); found.Status != domain.PaymentStatusRefunded {
+			t.Fatalf("expected a refunded payment, got %+v", found)
+		}
+		if _, err := repos.Payments.Save(ctx, domain.NewPayment(order)); err != nil {
+			t.Fatalf("expected the refunded payment to release the order, got %v", err)
+		}
+	})
+
+	t.Run("PurgedOrderTakesItsPayments", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		payment, _ := repos.Payments.Save(ctx, domain.NewPayment(order))
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		if _, err := repos.Orders.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if _, err := repos.Payments.FindByID(ctx, payment.ID); !errors.Is(err, domain.ErrPaymentNotFound) {
+			t.Fatalf("expected payment to be purged with its order, got %v", err)
+		}
+	})
+}
+
+// RunWebhookEvent runs the WebhookEventRepository contract.
+func RunWebhookEvent(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("CreateStoresEventOnce", func(t *testing.T) {
+		repos := newRepos(t)
+
+		created, err := repos.WebhookEvents.Create(ctx, domain.NewWebhookEvent("evt_1", domain.PaymentEventCaptured, []byte(`{"id":"evt_1"}`)))
+		if err != nil || created.ID == 0 || created.IsProcessed() {
+			t.Fatalf("Create: %+v, %v", created, err)
+		}
+		if _, err := repos.WebhookEvents.Create(ctx, domain.NewWebhookEvent("evt_1", domain.PaymentEventCaptured, []byte(`{}`))); !errors.Is(err, domain.ErrWebhookEventExists) {
+			t.Fatalf("expected ErrWebhookEventExists, got %v", err)
+		}
+
+		found, err := repos.WebhookEvents.FindByEventID(ctx, "evt_1")
+		if err != nil || found.ID != created.ID || string(found.Payload) != `{"id":"evt_1"}` {
+			t.Fatalf("FindByEventID: %+v, %v", found, err)
+		}
+		if _, err := repos.WebhookEvents.FindByEventID(ctx, "evt_2"); !errors.Is(err, domain.ErrWebhookEventNotFound) {
+			t.Fatalf("expected ErrWebhookEventNotFound, got %v", err)
+		}
+	})
+
+	t.Run("MarkFailedThenProcessed", func(t *testing.T) {
+		repos := newRepos(t)
+		first, _ := repos.WebhookEvents.Create(ctx, domain.NewWebhookEvent("evt_1", domain.PaymentEventCaptured, []byte(`{}`)))
+		second, _ := repos.WebhookEvents.Create(ctx, domain.NewWebhookEvent("evt_2", domain.PaymentEventVoided, []byte(`{}`)))
+
+		if err := repos.WebhookEvents.MarkFailed(ctx, first.ID, "order is locked"); err != nil {
+			t.Fatalf("MarkFailed: %v", err)
+		}
+		found, _ := repos.WebhookEvents.FindByEventID(ctx, "evt_1")
+		if found.Error != "order is locked" || found.IsProcessed() {
+			t.Fatalf("unexpected failed event: %+v", found)
+		}
+
+		if err := repos.WebhookEvents.MarkProcessed(ctx, first.ID, time.Now()); err != nil {
+			t.Fatalf("MarkProcessed: %v", err)
+		}
+		found, _ = repos.WebhookEvents.FindByEventID(ctx, "evt_1")
+		if found.Error != "" || !found.IsProcessed() {
+			t.Fatalf("unexpected processed event: %+v", found)
+		}
+		if err := repos.WebhookEvents.MarkProcessed(ctx, 999, time.Now()); !errors.Is(err, domain.ErrWebhookEventNotFound) {
+			t.Fatalf("expected ErrWebhookEventNotFound, got %v", err)
+		}
+
+		events, err := repos.WebhookEvents.FindAll(ctx)
+		if err != nil || len(events) != 2 || events[0].ID != first.ID || events[1].ID != second.ID {
+			t.Fatalf("expected events oldest first, got %+v, %v", events, err)
+		}
+	})
+
+	t.Run("ClaimHoldsEventForOneDelivery", func(t *testing.T) {
+		repos := newRepos(t)
+		event, _ := repos.WebhookEvents.Create(ctx, domain.NewWebhookEvent("evt_1", domain.PaymentEventRefunded, []byte(`{}`)))
+		now := time.Now()
+
+		// The delivery that stored the event holds it
+		if err := repos.WebhookEvents.Claim(ctx, event.ID, now, now.Add(-time.Minute)); !errors.Is(err, domain.ErrWebhookEventInProgress) {
+			t.Fatalf("expected ErrWebhookEventInProgress, got %v", err)
+		}
+		// until its claim goes stale
+		if err := repos.WebhookEvents.Claim(ctx, event.ID, now, now.Add(time.Minute)); err != nil {
+			t.Fatalf("expected a stale claim to be taken over, got %v", err)
+		}
+
+		// A failed attempt releases the event for the next delivery
+		_ = repos.WebhookEvents.MarkFailed(ctx, event.ID, "order is locked")
+		if err := repos.WebhookEvents.Claim(ctx, event.ID, now, now.Add(-time.Minute)); err != nil {
+			t.Fatalf("expected a failed event to be claimed, got %v", err)
+		}
+		if err := repos.WebhookEvents.Claim(ctx, event.ID, now, now.Add(-time.Minute)); !errors.Is(err, domain.ErrWebhookEventInProgress) {
+			t.Fatalf("expected ErrWebhookEventInProgress, got %v", err)
+		}
+
+		// Processed events are never claimed again
+		_ = repos.WebhookEvents.MarkProcessed(ctx, event.ID, now)
+		if err := repos.WebhookEvents.Claim(ctx, event.ID, now, now.Add(time.Hour)); !errors.Is(err, domain.ErrWebhookEventInProgress) {
+			t.Fatalf("expected ErrWebhookEventInProgress for a processed event, got %v", err)
+		}
+		if err := repos.WebhookEvents.Claim(ctx, 999, now, now); !errors.Is(err, domain.ErrWebhookEventNotFound) {
+			t.Fatalf("expected ErrWebhookEventNotFound, got %v", err)
+		}
+	})
+}
+
+// RunReturnRequest runs the ReturnRequestRepository contract.
+func RunReturnRequest(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveUpdateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		other := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		rejected, err := repos.ReturnRequests.Save(ctx, domain.NewReturnRequest(order.ID, 1, "too late"))
+		if err != nil || rejected.ID == 0 || !rejected.IsRequested() {
+			t.Fatalf("Save: %+v, %v", rejected, err)
+		}
+		rejected.Reject("outside the return window")
+		if _, err := repos.ReturnRequests.Update(ctx, rejected); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		approved, _ := repos.ReturnRequests.Save(ctx, domain.NewReturnRequest(order.ID, 1, "damaged"))
+		approved.Approve(domain.StockConditionDamaged, 5)
+		if _, err := repos.ReturnRequests.Update(ctx, approved); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		requested, _ := repos.ReturnRequests.Save(ctx, domain.NewReturnRequest(other.ID, 1, ""))
+
+		found, err := repos.ReturnRequests.FindByID(ctx, approved.ID)
+		if err != nil || found.Status != domain.ReturnStatusApproved || found.Condition != domain.StockConditionDamaged || found.RefundAmount != 5 {
+			t.Fatalf("FindByID: %+v, %v", found, err)
+		}
+		if _, err := repos.ReturnRequests.FindByID(ctx, 999); !errors.Is(err, domain.ErrReturnNotFound) {
+			t.Fatalf("expected ErrReturnNotFound, got %v", err)
+		}
+
+		requests, err := repos.ReturnRequests.FindByOrderID(ctx, order.ID)
+		if err != nil || len(requests) != 2 || requests[0].ID != rejected.ID || requests[1].ID != approved.ID {
+			t.Fatalf("expected the order's returns oldest first, got %+v, %v", requests, err)
+		}
+		if requests[0].RejectionReason != "outside the return window" {
+			t.Fatalf("expected the rejection reason to be kept, got %+v", requests[0])
+		}
+
+		pending, total, err := repos.ReturnRequests.FindByStatus(ctx, domain.ReturnStatusRequested, domain.Page{})
+		if err != nil || len(pending) != 1 || pending[0].ID != requested.ID {
+			t.Fatalf("FindByStatus: %+v, %v", pending, err)
+		}
+		if total != 1 {
+			t.Fatalf("expected 1 requested return in all, got %d", total)
+		}
+		if all, total, _ := repos.ReturnRequests.FindByStatus(ctx, "", domain.Page{}); len(all) != 3 || total != 3 {
+			t.Fatalf("expected all returns without a status, got %+v", all)
+		}
+		page, total, err := repos.ReturnRequests.FindByStatus(ctx, "", domain.Page{Limit: 2, Offset: 2})
+		if err != nil || len(page) != 1 || page[0].ID != requested.ID || total != 3 {
+			t.Fatalf("expected the newest return on the second page, got %+v of %d, %v", page, total, err)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		request, _ := repos.ReturnRequests.Save(ctx, domain.NewReturnRequest(order.ID, 1, ""))
+
+		if err := repos.ReturnRequests.UpdateStatus(ctx, request.ID, domain.ReturnStatusRequested, domain.ReturnStatusApproving); err != nil {
+			t.Fatalf("UpdateStatus: %v", err)
+		}
+		if found, _ := repos.ReturnRequests.FindByID(ctx, request.ID); found.Status != domain.ReturnStatusApproving {
+			t.Fatalf("expected approving, got %s", found.Status)
+		}
+
+		// Only one caller can move the request out of a status
+		err := repos.ReturnRequests.UpdateStatus(ctx, request.ID, domain.ReturnStatusRequested, domain.ReturnStatusRejected)
+		if !errors.Is(err, domain.ErrReturnNotRequested) {
+			t.Fatalf("expected ErrReturnNotRequested, got %v", err)
+		}
+
+		err = repos.ReturnRequests.UpdateStatus(ctx, 999, domain.ReturnStatusRequested, domain.ReturnStatusRejected)
+		if !errors.Is(err, domain.ErrReturnNotFound) {
+			t.Fatalf("expected ErrReturnNotFound, got %v", err)
+		}
+	})
+
+	t.Run("PurgedOrderTakesItsReturns", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		request, _ := repos.ReturnRequests.Save(ctx, domain.NewReturnRequest(order.ID, 1, ""))
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		if _, err := repos.Orders.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if _, err := repos.ReturnRequests.FindByID(ctx, request.ID); !errors.Is(err, domain.ErrReturnNotFound) {
+			t.Fatalf("expected return to be purged with its order, got %v", err)
+		}
+	})
+}
+
+// RunPromotion runs the PromotionRepository contract.
+func RunPromotion(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveUpdateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+
+		saved, err := repos.Promotions.Save(ctx, newPromotion("SPRING10"))
+		if err != nil || saved.ID == 0 {
+			t.Fatalf("Save: %+v, %v", saved, err)
+		}
+		if _, err := repos.Promotions.Save(ctx, newPromotion("SPRING10")); !errors.Is(err, domain.ErrPromotionCodeExists) {
+			t.Fatalf("expected ErrPromotionCodeExists, got %v", err)
+		}
+
+		ends := time.Now().Add(time.Hour).Truncate(time.Second)
+		saved.Value = 15
+		saved.EndsAt = &ends
+		saved.BookID = &book.ID
+		if _, err := repos.Promotions.Update(ctx, saved); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		other, _ := repos.Promotions.Save(ctx, newPromotion("SUMMER"))
+		other.Code = "SPRING10"
+		if _, err := repos.Promotions.Update(ctx, other); !errors.Is(err, domain.ErrPromotionCodeExists) {
+			t.Fatalf("expected ErrPromotionCodeExists on update, got %v", err)
+		}
+
+		found, err := repos.Promotions.FindByCode(ctx, "SPRING10")
+		if err != nil || found.ID != saved.ID || found.Value != 15 || found.BookID == nil || *found.BookID != book.ID || found.EndsAt == nil || !found.EndsAt.Equal(ends) {
+			t.Fatalf("FindByCode: %+v, %v", found, err)
+		}
+		if _, err := repos.Promotions.FindByCode(ctx, "WINTER"); !errors.Is(err, domain.ErrPromotionNotFound) {
+			t.Fatalf("expected ErrPromotionNotFound, got %v", err)
+		}
+		if _, err := repos.Promotions.FindByID(ctx, 999); !errors.Is(err, domain.ErrPromotionNotFound) {
+			t.Fatalf("expected ErrPromotionNotFound, got %v", err)
+		}
+
+		all, err := repos.Promotions.FindAll(ctx)
+		if err != nil || len(all) != 2 || all[0].ID != saved.ID || all[1].Code != "SUMMER" {
+			t.Fatalf("expected promotions oldest first, got %+v, %v", all, err)
+		}
+
+		if err := repos.Promotions.Delete(ctx, saved.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Promotions.FindByID(ctx, saved.ID); !errors.Is(err, domain.ErrPromotionNotFound) {
+			t.Fatalf("expected deleted promotion to be gone, got %v", err)
+		}
+		if err := repos.Promotions.Delete(ctx, saved.ID); !errors.Is(err, domain.ErrPromotionNotFound) {
+			t.Fatalf("expected ErrPromotionNotFound, got %v", err)
+		}
+	})
+
+	t.Run("RedeemEnforcesLimits", func(t *testing.T) {
+		repos := newRepos(t)
+		first := mustSaveUser(t, repos, "a@example.com")
+		second := mustSaveUser(t, repos, "b@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+
+		promotion := newPromotion("SPRING10")
+		promotion.UsageLimit = 2
+		promotion.PerUserLimit = 1
+		promotion, _ = repos.Promotions.Save(ctx, promotion)
+
+		redeem := func(user domain.User) (domain.Order, error) {
+			order := mustSaveOrder(t, repos, user.ID, book.ID)
+			return order, repos.Promotions.Redeem(ctx, domain.NewPromotionRedemption(promotion, order))
+		}
+
+		used, err := redeem(first)
+		if err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		if _, err := redeem(first); !errors.Is(err, domain.ErrPromotionUserLimit) {
+			t.Fatalf("expected ErrPromotionUserLimit, got %v", err)
+		}
+		if _, err := redeem(second); err != nil {
+			t.Fatalf("Redeem: %v", err)
+		}
+		third := mustSaveUser(t, repos, "c@example.com")
+		if _, err := redeem(third); !errors.Is(err, domain.ErrPromotionUsedUp) {
+			t.Fatalf("expected ErrPromotionUsedUp, got %v", err)
+		}
+
+		found, _ := repos.Promotions.FindByID(ctx, promotion.ID)
+		if found.UsedCount != 2 {
+			t.Fatalf("expected two redemptions, got %d", found.UsedCount)
+		}
+
+		// A released redemption frees its place under both limits
+		if err := repos.Promotions.Release(ctx, used.ID); err != nil {
+			t.Fatalf("Release: %v", err)
+		}
+		if _, err := redeem(first); err != nil {
+			t.Fatalf("expected the released promotion to be redeemable, got %v", err)
+		}
+		if err := repos.Promotions.Release(ctx, 999); err != nil {
+			t.Fatalf("expected releasing an order without a redemption to succeed, got %v", err)
+		}
+	})
+
+	t.Run("PurgedOrderTakesItsRedemption", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 1)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		promotion, _ := repos.Promotions.Save(ctx, newPromotion("SPRING10"))
+		_ = repos.Promotions.Redeem(ctx, domain.NewPromotionRedemption(promotion, order))
+
+		_ = repos.Orders.Delete(ctx, order.ID)
+		if _, err := repos.Orders.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if found, _ := repos.Promotions.FindByID(ctx, promotion.ID); found.UsedCount != 0 {
+			t.Fatalf("expected the redemption to be purged with its order, got %d", found.UsedCount)
+		}
+	})
+}
+
+// RunBookPrice runs the BookPriceRepository contract.
+func RunBookPrice(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("FindByBookIDOrdersByEffectiveFrom", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+		other := mustSaveBook(t, repos, "B", 1)
+
+		later, _ := repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeRegular, 12, now.Add(time.Hour), nil))
+		earlier, err := repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeRegular, 10, now, nil))
+		if err != nil || earlier.ID == 0 {
+			t.Fatalf("Save: %+v, %v", earlier, err)
+		}
+		_, _ = repos.BookPrices.Save(ctx, domain.NewBookPrice(other.ID, domain.BookPriceTypeRegular, 20, now, nil))
+
+		earlier.EffectiveTo = &later.EffectiveFrom
+		if _, err := repos.BookPrices.Update(ctx, earlier); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		prices, err := repos.BookPrices.FindByBookID(ctx, book.ID)
+		if err != nil || len(prices) != 2 || prices[0].ID != earlier.ID || prices[1].ID != later.ID {
+			t.Fatalf("expected prices in effect order, got %+v, %v", prices, err)
+		}
+		if prices[0].EffectiveTo == nil || !prices[0].EffectiveTo.Equal(later.EffectiveFrom) {
+			t.Fatalf("expected the earlier price to end when the later starts, got %v", prices[0].EffectiveTo)
+		}
+	})
+
+	t.Run("FindEffective", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+		other := mustSaveBook(t, repos, "B", 1)
+		unrelated := mustSaveBook(t, repos, "C", 1)
+		saleEnds := now.Add(time.Hour)
+		ended := now.Add(-time.Minute)
+
+		regular, _ := repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeRegular, 10, now.Add(-time.Hour), nil))
+		sale, _ := repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeSale, 8, now, &saleEnds))
+		_, _ = repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeSale, 5, now.Add(-time.Hour), &ended))
+		_, _ = repos.BookPrices.Save(ctx, domain.NewBookPrice(other.ID, domain.BookPriceTypeRegular, 20, now.Add(time.Hour), nil))
+		_, _ = repos.BookPrices.Save(ctx, domain.NewBookPrice(unrelated.ID, domain.BookPriceTypeRegular, 30, now, nil))
+
+		prices, err := repos.BookPrices.FindEffective(ctx, []uint{book.ID, other.ID}, now)
+		if err != nil || len(prices) != 2 || prices[0].ID != regular.ID || prices[1].ID != sale.ID {
+			t.Fatalf("expected the regular price and the running sale, got %+v, %v", prices, err)
+		}
+
+		prices, err = repos.BookPrices.FindEffective(ctx, []uint{}, now)
+		if err != nil || len(prices) != 0 {
+			t.Fatalf("expected no prices for no books, got %+v, %v", prices, err)
+		}
+	})
+
+	t.Run("PurgedBookLosesItsHistory", func(t *testing.T) {
+		repos := newRepos(t)
+		book := mustSaveBook(t, repos, "A", 1)
+		_, _ = repos.BookPrices.Save(ctx, domain.NewBookPrice(book.ID, domain.BookPriceTypeRegular, 10, now, nil))
+
+		if err := repos.Books.Delete(ctx, book.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Books.Purge(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Purge: %v", err)
+		}
+		if prices, _ := repos.BookPrices.FindByBookID(ctx, book.ID); len(prices) != 0 {
+			t.Fatalf("expected the history to be purged with its book, got %+v", prices)
+		}
+	})
+}
+
+// RunAddress runs the AddressRepository contract.
+func RunAddress(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveUpdateAndFindByUserID", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		other := mustSaveUser(t, repos, "b@example.com")
+
+		home, err := repos.Addresses.Save(ctx, newAddress(user.ID, "home", "10210"))
+		if err != nil || home.ID == 0 {
+			t.Fatalf("Save: %+v, %v", home, err)
+		}
+		office, _ := repos.Addresses.Save(ctx, newAddress(user.ID, "office", "10220"))
+		_, _ = repos.Addresses.Save(ctx, newAddress(other.ID, "home", "10230"))
+
+		home.Line2 = "Floor 2"
+		if _, err := repos.Addresses.Update(ctx, home); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		found, err := repos.Addresses.FindByID(ctx, home.ID)
+		if err != nil || found.Line2 != "Floor 2" || found.PostalCode != "10210" || found.Label != "home" {
+			t.Fatalf("expected the updated address, got %+v, %v", found, err)
+		}
+
+		addresses, err := repos.Addresses.FindByUserID(ctx, user.ID)
+		if err != nil || len(addresses) != 2 || addresses[0].ID != home.ID || addresses[1].ID != office.ID {
+			t.Fatalf("expected the user's addresses oldest first, got %+v, %v", addresses, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		address, _ := repos.Addresses.Save(ctx, newAddress(user.ID, "home", "10210"))
+
+		if err := repos.Addresses.Delete(ctx, address.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repos.Addresses.FindByID(ctx, address.ID); !errors.Is(err, domain.ErrAddressNotFound) {
+			t.Fatalf("expected ErrAddressNotFound, got %v", err)
+		}
+		if err := repos.Addresses.Delete(ctx, address.ID); !errors.Is(err, domain.ErrAddressNotFound) {
+			t.Fatalf("expected ErrAddressNotFound deleting twice, got %v", err)
+		}
+	})
+
+	t.Run("OrderKeepsItsCopy", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 5)
+		address, _ := repos.Addresses.Save(ctx, newAddress(user.ID, "home", "10210"))
+
+		order := domain.NewOrder(user.ID, book.ID, 1, 10)
+		order.ShippingAddress = &address.AddressSnapshot
+		saved, err := repos.Orders.Save(ctx, order)
+		if err != nil {
+			t.Fatalf("Save order: %v", err)
+		}
+
+		_ = repos.Addresses.Delete(ctx, address.ID)
+
+		found, err := repos.Orders.FindByID(ctx, saved.ID)
+		if err != nil || found.ShippingAddress == nil || *found.ShippingAddress != address.AddressSnapshot {
+			t.Fatalf("expected the order to keep its shipping address, got %+v, %v", found.ShippingAddress, err)
+		}
+		if found.BillingAddress != nil {
+			t.Fatalf("expected no billing address, got %+v", found.BillingAddress)
+		}
+	})
+}
+
+// RunOrderShipment runs the OrderShipmentRepository contract.
+func RunOrderShipment(t *testing.T, newRepos Factory) {
+	ctx := context.Background()
+
+	t.Run("SaveUpdateAndFindByOrderID", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 5)
+		order := mustSaveOrder(t, repos, user.ID, book.ID)
+		other := mustSaveOrder(t, repos, user.ID, book.ID)
+
+		if _, err := repos.OrderShipments.FindByOrderID(ctx, order.ID); !errors.Is(err, domain.ErrOrderShipmentNotFound) {
+			t.Fatalf("expected ErrOrderShipmentNotFound before packing, got %v", err)
+		}
+
+		shipment, err := repos.OrderShipments.Save(ctx, domain.NewOrderShipment(order.ID))
+		if err != nil || shipment.ID == 0 {
+			t.Fatalf("Save: %+v, %v", shipment, err)
+		}
+		_, _ = repos.OrderShipments.Save(ctx, domain.NewOrderShipment(other.ID))
+
+		if err := shipment.Ship("JNE", "JNE123"); err != nil {
+			t.Fatalf("Ship: %v", err)
+		}
+		if _, err := repos.OrderShipments.Update(ctx, shipment); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		found, err := repos.OrderShipments.FindByOrderID(ctx, order.ID)
+		if err != nil || found.ID != shipment.ID || found.Carrier != "JNE" || found.TrackingNumber != "JNE123" {
+			t.Fatalf("expected the shipped shipment, got %+v, %v", found, err)
+		}
+		if found.ShippedAt == nil || found.DeliveredAt != nil {
+			t.Fatalf("expected a ship time and no delivery time, got %+v", found)
+		}
+	})
+
+	t.Run("OrderKeepsItsShippingLine", func(t *testing.T) {
+		repos := newRepos(t)
+		user := mustSaveUser(t, repos, "a@example.com")
+		book := mustSaveBook(t, repos, "A", 5)
+
+		order := domain.NewOrder(user.ID, book.ID, 1, 10)
+		order.ApplyShipping("standard", 5)
+		saved, err := repos.Orders.Save(ctx, order)
+		if err != nil {
+			t.Fatalf("Save order: %v", err)
+		}
+
+		found, err := repos.Orders.FindByID(ctx, saved.ID)
+		if err != nil || found.ShippingMethod != "standard" || found.ShippingCost != 5 || found.Total != 15 {
+			t.Fatalf("expected the shipping line to be stored, got %+v, %v", found, err)
+		}
+	})
+}
+
+func newAddress(userID uint, label, postalCode string) domain.Address {
+	return domain.NewAddress(userID, label, domain.AddressSnapshot{
+		Name:       "Budi",
+		Line1:      "Jl. Sudirman 1",
+		City:       "Jakarta",
+		Region:     "JK",
+		PostalCode: postalCode,
+		Country:    "ID",
+	})
+}
+
+func newPromotion(code string) domain.Promotion {
+	now := time.Now()
+	return domain.Promotion{
+		Code:      code,
+		Type:      domain.PromotionTypePercentage,
+		Value:     10,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func mustSaveBook(t *testing.T, repos Repositories, title string, stock int) domain.Book {
+	t.Helper()
+	book, err := repos.Books.Save(context.Background(), domain.NewBook(title, 10, stock), domain.NewInitialStock(""))
+	if err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	return book
+}
+
+func mustSaveUser(t *testing.T, repos Repositories, email string) domain.User {
+	t.Helper()
+	user, err := repos.Users.Save(context.Background(), domain.NewUser("Test", email, "secret123", "customer"))
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	return user
+}
+
+func mustSaveOrder(t *testing.T, repos Repositories, userID, bookID uint) domain.Order {
+	t.Helper()
+	order, err := repos.Orders.Save(context.Background(), domain.NewOrder(userID, bookID, 1, 10))
+	if err != nil {
+		t.Fatalf("save order: %v", err)
+	}
+	return order
+}
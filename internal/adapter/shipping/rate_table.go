@@ -0,0 +1,76 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// RateTable implements domain.ShippingCalculator with a fixed set of
+// shipping methods, each priced by its own table of rates.
+type RateTable struct {
+	methods []domain.ShippingMethod
+}
+
+// NewRateTable creates a new RateTable. Method codes are not case sensitive
+// and must be unique, and each method needs rates in ascending order of
+// From, none of them negative.
+func NewRateTable(methods []domain.ShippingMethod) (*RateTable, error) {
+	seen := make(map[string]bool, len(methods))
+	normalized := make([]domain.ShippingMethod, len(methods))
+
+	for i, method := range methods {
+		method.Code = domain.NormalizeShippingMethod(method.Code)
+		if method.Code == "" {
+			return nil, fmt.Errorf("shipping method needs a code")
+		}
+		if seen[method.Code] {
+			return nil, fmt.Errorf("duplicate shipping method %s", method.Code)
+		}
+		seen[method.Code] = true
+
+		if method.Basis != domain.ShippingBasisWeight && method.Basis != domain.ShippingBasisQuantity {
+			return nil, fmt.Errorf("shipping method %s must be rated by weight or quantity", method.Code)
+		}
+		if len(method.Rates) == 0 {
+			return nil, fmt.Errorf("shipping method %s has no rates", method.Code)
+		}
+		for j, rate := range method.Rates {
+			if rate.From < 0 || rate.Cost < 0 {
+				return nil, fmt.Errorf("rates of shipping method %s cannot be negative", method.Code)
+			}
+			if j > 0 && rate.From <= method.Rates[j-1].From {
+				return nil, fmt.Errorf("rates of shipping method %s must be in ascending order", method.Code)
+			}
+		}
+
+		method.Rates = append([]domain.ShippingRate(nil), method.Rates...)
+		normalized[i] = method
+	}
+
+	return &RateTable{methods: normalized}, nil
+}
+
+// Methods returns the shipping methods in the order they were configured.
+func (t *RateTable) Methods() []domain.ShippingMethod {
+	methods := make([]domain.ShippingMethod, len(t.methods))
+	copy(methods, t.methods)
+	return methods
+}
+
+// Quote prices a line with the rate table of its method.
+func (t *RateTable) Quote(ctx context.Context, line domain.ShippingLine) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	code := domain.NormalizeShippingMethod(line.Method)
+	for _, method := range t.methods {
+		if method.Code == code {
+			return method.Cost(line)
+		}
+	}
+
+	return 0, domain.ErrInvalidShippingMethod
+}
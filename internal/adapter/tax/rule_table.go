@@ -0,0 +1,92 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// RuleTable implements domain.TaxCalculator with a fixed table of rules.
+// The most specific rule for a line applies: a rule for its region beats
+// one for its country (US for US-CA), which beats one for any region, and
+// within those a rule for its category beats one for any category.
+type RuleTable struct {
+	rules []domain.TaxRule
+}
+
+// NewRuleTable creates a new RuleTable. Rates are percentages from 0 to
+// 100, and each region and category pair may only have one rule.
+func NewRuleTable(rules []domain.TaxRule) (*RuleTable, error) {
+	seen := make(map[string]bool, len(rules))
+	normalized := make([]domain.TaxRule, len(rules))
+
+	for i, rule := range rules {
+		if rule.Rate < 0 || rule.Rate > 100 {
+			return nil, fmt.Errorf("tax rate of %s/%s must be between 0 and 100", describe(rule.Region), describe(rule.Category))
+		}
+
+		rule.Region = strings.ToUpper(strings.TrimSpace(rule.Region))
+		rule.Category = strings.ToLower(strings.TrimSpace(rule.Category))
+		key := rule.Region + "/" + rule.Category
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate tax rule for %s/%s", describe(rule.Region), describe(rule.Category))
+		}
+		seen[key] = true
+		normalized[i] = rule
+	}
+
+	return &RuleTable{rules: normalized}, nil
+}
+
+// Calculate taxes a line with the most specific rule covering it.
+func (t *RuleTable) Calculate(ctx context.Context, line domain.TaxLine) (domain.TaxBreakdown, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.TaxBreakdown{}, err
+	}
+
+	region := strings.ToUpper(strings.TrimSpace(line.Region))
+	country, _, _ := strings.Cut(region, "-")
+	category := strings.ToLower(strings.TrimSpace(line.Category))
+
+	best, bestScore := domain.TaxRule{}, -1
+	for _, rule := range t.rules {
+		var score int
+		switch rule.Region {
+		case region:
+			score = 4
+		case country:
+			score = 2
+		case "":
+			score = 0
+		default:
+			continue
+		}
+		switch rule.Category {
+		case category:
+			score++
+		case "":
+		default:
+			continue
+		}
+
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+
+	if bestScore < 0 {
+		return domain.TaxBreakdown{}, domain.ErrTaxRegionUnsupported
+	}
+
+	return best.Apply(line.Amount), nil
+}
+
+// describe names an empty region or category the way rules are configured.
+func describe(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
@@ -5,47 +5,263 @@ import (
 	"log"
 
 	"kikukafandi/book-shop-api/internal/adapter/db"
+	"kikukafandi/book-shop-api/internal/domain"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Supported database drivers.
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// sqliteMemory is the SQLite path for a private in-memory database.
+const sqliteMemory = ":memory:"
+
 // DatabaseConfig holds database configuration.
 type DatabaseConfig struct {
+	Driver   string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+	// SSLMode is the PostgreSQL sslmode.
+	SSLMode string
+	// Path is the SQLite database file, or ":memory:".
+	Path string
+	// LogLevel is the GORM log level: silent, error, warn or info.
+	LogLevel string
 }
 
-// NewDatabase creates a new database connection.
+// NewDatabase creates a new database connection for the configured driver.
 func NewDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.DBName,
-	)
-
-	database, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logLevel(cfg.LogLevel)),
+		// Foreign keys are created by migrateForeignKeys, not implicitly
+		DisableForeignKeyConstraintWhenMigrating: true,
+		// Map driver errors such as duplicate keys to GORM errors
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	log.Println("Database connected successfully")
+	if cfg.Driver == DriverSQLite && cfg.Path == sqliteMemory {
+		// Every connection to :memory: opens a new empty database,
+		// so all queries must share a single connection.
+		sqlDB, err := database.DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure database: %w", err)
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	log.Printf("Database connected successfully (%s)", cfg.Driver)
 	return database, nil
 }
 
+// newDialector builds the GORM dialector for the configured driver.
+func newDialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL, "":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.DBName,
+		)
+		return mysql.Open(dsn), nil
+
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host,
+			cfg.Port,
+			cfg.User,
+			cfg.Password,
+			cfg.DBName,
+			cfg.SSLMode,
+		)
+		return postgres.Open(dsn), nil
+
+	case DriverSQLite:
+		// SQLite leaves foreign keys off by default and fails immediately
+		// on a locked database; enable both per connection. It has no row
+		// locks, so transactions take the write lock up front instead.
+		dsn := cfg.Path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+		return sqlite.Open(dsn), nil
+
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", cfg.Driver)
+	}
+}
+
+// logLevel converts a log level name to a GORM log level.
+func logLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
+}
+
+// models lists every model created by AutoMigrate.
+var models = []interface{}{
+	&db.BookModel{},
+	&db.UserModel{},
+	&db.OrderModel{},
+	&db.PasswordResetModel{},
+	&db.StockMovementModel{},
+	&db.ReservationModel{},
+	&db.IdempotencyKeyModel{},
+	&db.PaymentModel{},
+	&db.WebhookEventModel{},
+	&db.ReturnRequestModel{},
+	&db.PromotionModel{},
+	&db.PromotionRedemptionModel{},
+	&db.BookPriceModel{},
+	&db.AddressModel{},
+	&db.OrderShipmentModel{},
+}
+
 // AutoMigrate runs auto migration for all models.
 func AutoMigrate(database *gorm.DB) error {
-	return database.AutoMigrate(
-		&db.BookModel{},
-		&db.UserModel{},
-		&db.OrderModel{},
-	)
+	if err := database.AutoMigrate(models...); err != nil {
+		return err
+	}
+
+	if err := migrateForeignKeys(database); err != nil {
+		return err
+	}
+
+	// SQLite adds a foreign key by rebuilding the table, which drops the
+	// table's indexes; migrating again recreates them
+	if database.Dialector.Name() == DriverSQLite {
+		if err := database.AutoMigrate(models...); err != nil {
+			return err
+		}
+	}
+
+	if err := migrateStockLedger(database); err != nil {
+		return err
+	}
+
+	if err := migratePriceHistory(database); err != nil {
+		return err
+	}
+
+	if err := migrateOrderTax(database); err != nil {
+		return err
+	}
+
+	return migrateSearchIndex(database)
+}
+
+// migrateStockLedger records an opening balance for books that have stock
+// but no ledger entries yet, e.g. books created before the ledger existed.
+func migrateStockLedger(database *gorm.DB) error {
+	return database.Exec(`
+		INSERT INTO stock_movements (book_id, delta, balance, reason, actor, reference, created_at)
+		SELECT id, stock, stock, ?, 'system', 'opening balance', CURRENT_TIMESTAMP
+		FROM books
+		WHERE stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements WHERE stock_movements.book_id = books.id)`,
+		domain.StockReasonAdjustment,
+	).Error
+}
+
+// migratePriceHistory starts the price history of books that have none yet,
+// e.g. books created before the history existed, at their current price,
+// and works out the unit price of orders placed before it was recorded.
+func migratePriceHistory(database *gorm.DB) error {
+	err := database.Exec(
+		"UPDATE orders SET unit_price = (total + discount) / quantity WHERE unit_price = 0 AND quantity > 0",
+	).Error
+	if err != nil {
+		return err
+	}
+
+	return database.Exec(`
+		INSERT INTO book_prices (book_id, type, price, effective_from, created_at)
+		SELECT id, ?, price, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM books
+		WHERE NOT EXISTS (SELECT 1 FROM book_prices WHERE book_prices.book_id = books.id)`,
+		domain.BookPriceTypeRegular,
+	).Error
+}
+
+// migrateOrderTax fills in the subtotal of orders placed before tax was
+// charged; their total had no tax in it. Those orders were not shipped
+// either, which tells them apart from shipped orders a promotion made free
+// but for their shipping.
+func migrateOrderTax(database *gorm.DB) error {
+	return database.Exec("UPDATE orders SET subtotal = total WHERE subtotal = 0 AND tax = 0 AND shipping_cost = 0").Error
+}
+
+// migrateSearchIndex creates the full-text index used by BookRepositoryPostgres.Search.
+// Other engines search with LIKE and need no extra index.
+func migrateSearchIndex(database *gorm.DB) error {
+	if database.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+
+	return database.Exec(
+		"CREATE INDEX IF NOT EXISTS idx_books_title_search ON books USING GIN (to_tsvector('simple', title))",
+	).Error
+}
+
+// foreignKey describes a foreign key declared as a relation on a model.
+type foreignKey struct {
+	model    interface{}
+	relation string
+}
+
+// foreignKeys lists all foreign keys created by migrations.
+var foreignKeys = []foreignKey{
+	{model: &db.OrderModel{}, relation: "User"},
+	{model: &db.OrderModel{}, relation: "Book"},
+	{model: &db.StockMovementModel{}, relation: "Book"},
+	{model: &db.ReservationModel{}, relation: "Order"},
+	{model: &db.PaymentModel{}, relation: "Order"},
+	{model: &db.ReturnRequestModel{}, relation: "Order"},
+	{model: &db.PromotionModel{}, relation: "Book"},
+	{model: &db.PromotionRedemptionModel{}, relation: "Promotion"},
+	{model: &db.PromotionRedemptionModel{}, relation: "Order"},
+	{model: &db.BookPriceModel{}, relation: "Book"},
+	{model: &db.AddressModel{}, relation: "User"},
+	{model: &db.OrderShipmentModel{}, relation: "Order"},
+}
+
+// migrateForeignKeys creates missing foreign key constraints.
+// It fails if existing rows violate a constraint; run cmd/integrity to find them.
+func migrateForeignKeys(database *gorm.DB) error {
+	migrator := database.Migrator()
+
+	for _, fk := range foreignKeys {
+		if migrator.HasConstraint(fk.model, fk.relation) {
+			continue
+		}
+		if err := migrator.CreateConstraint(fk.model, fk.relation); err != nil {
+			return fmt.Errorf("failed to create foreign key %s (run cmd/integrity to find orphaned rows): %w", fk.relation, err)
+		}
+	}
+
+	return nil
 }
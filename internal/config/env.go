@@ -3,14 +3,37 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Environment constants. Development and test run without real secrets
+// or payment providers.
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
+)
+
+// devSecret stands in for secrets left unset in development and test.
+const devSecret = "change-me"
+
 // Config holds all application configuration.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Env         string
+	Server      ServerConfig
+	Database    DatabaseConfig
+	Mail        MailConfig
+	Auth        AuthConfig
+	Purge       PurgeConfig
+	Order       OrderConfig
+	Alert       StockAlertConfig
+	Idempotency IdempotencyConfig
+	Payment     PaymentConfig
+	Tax         TaxConfig
+	Shipping    ShippingConfig
 }
 
 // ServerConfig holds server configuration.
@@ -19,25 +42,120 @@ type ServerConfig struct {
 	Port string
 }
 
+// AuthConfig holds authentication configuration.
+type AuthConfig struct {
+	// AppURL is where the API is served, used in links sent by email.
+	AppURL string
+	// ResetURL is the front-end page where users choose a new password.
+	ResetURL                   string
+	ResetTokenTTL              time.Duration
+	VerificationSecret         string
+	VerificationTokenTTL       time.Duration
+	VerificationResendInterval time.Duration
+	RequireVerifiedEmail       bool
+}
+
+// PurgeConfig holds configuration for purging soft deleted records.
+type PurgeConfig struct {
+	Retention time.Duration
+}
+
+// OrderConfig holds configuration for stock reservations of pending orders.
+type OrderConfig struct {
+	ReservationTTL          time.Duration
+	BackorderReservationTTL time.Duration
+	SweepInterval           time.Duration
+}
+
+// IdempotencyConfig holds configuration for idempotency keys.
+type IdempotencyConfig struct {
+	KeyTTL        time.Duration
+	SweepInterval time.Duration
+}
+
 // LoadConfig loads configuration from environment variables.
 func LoadConfig() Config {
 	if err := godotenv.Load(); err != nil {
 		log.Fatalf("Failed to load environment variables: %v", err)
 	}
 
+	env := getEnv("APP_ENV", EnvProduction)
+	driver := getEnv("DB_DRIVER", DriverMySQL)
+	appURL := getEnv("APP_URL", "http://localhost:8080")
+
 	return Config{
+		Env: env,
 		Server: ServerConfig{
 			Host: getEnv("SERVER_HOST", "localhost"),
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
 		Database: DatabaseConfig{
+			Driver:   driver,
 			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "3306"),
+			Port:     getEnv("DB_PORT", defaultDBPort(driver)),
 			User:     getEnv("DB_USER", "root"),
 			Password: getEnv("DB_PASSWORD", "Kikuk@123"),
 			DBName:   getEnv("DB_NAME", "bookstore"),
+			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Path:     getEnv("DB_PATH", "bookstore.db"),
+			LogLevel: getEnv("DB_LOG_LEVEL", "info"),
+		},
+		Mail: MailConfig{
+			Driver:   getEnv("MAIL_DRIVER", "log"),
+			Host:     getEnv("SMTP_HOST", "localhost"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "no-reply@bookstore.local"),
+			LogPath:  getEnv("MAIL_LOG_PATH", ""),
+		},
+		Auth: AuthConfig{
+			AppURL:                     appURL,
+			ResetURL:                   getEnv("PASSWORD_RESET_URL", appURL+"/reset-password"),
+			ResetTokenTTL:              getEnvDuration("RESET_TOKEN_TTL", time.Hour),
+			VerificationSecret:         getSecret(env, "VERIFICATION_SECRET"),
+			VerificationTokenTTL:       getEnvDuration("VERIFICATION_TOKEN_TTL", 24*time.Hour),
+			VerificationResendInterval: getEnvDuration("VERIFICATION_RESEND_INTERVAL", time.Minute),
+			RequireVerifiedEmail:       getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+		},
+		Purge: PurgeConfig{
+			Retention: getEnvDuration("PURGE_RETENTION", 30*24*time.Hour),
+		},
+		Order: OrderConfig{
+			ReservationTTL:          getEnvDuration("ORDER_RESERVATION_TTL", 15*time.Minute),
+			BackorderReservationTTL: getEnvDuration("ORDER_BACKORDER_RESERVATION_TTL", 72*time.Hour),
+			SweepInterval:           getEnvDuration("ORDER_SWEEP_INTERVAL", time.Minute),
+		},
+		Alert: StockAlertConfig{
+			Driver:     getEnv("STOCK_ALERT_DRIVER", "log"),
+			WebhookURL: getEnv("STOCK_ALERT_WEBHOOK_URL", ""),
+			Email:      getEnv("STOCK_ALERT_EMAIL", ""),
 		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL:        getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			SweepInterval: getEnvDuration("IDEMPOTENCY_SWEEP_INTERVAL", time.Hour),
+		},
+		Payment: PaymentConfig{
+			Driver:           getPaymentDriver(env),
+			WebhookSecret:    getSecret(env, "PAYMENT_WEBHOOK_SECRET"),
+			WebhookTolerance: getEnvDuration("PAYMENT_WEBHOOK_TOLERANCE", 5*time.Minute),
+		},
+		Tax: TaxConfig{
+			Rules:  getEnv("TAX_RULES", "*:*:0"),
+			Region: getEnv("TAX_REGION", "ID"),
+		},
+		Shipping: ShippingConfig{
+			Methods: getEnv("SHIPPING_METHODS", "standard:weight:0=0"),
+		},
+	}
+}
+
+// defaultDBPort returns the default port of a database driver.
+func defaultDBPort(driver string) string {
+	if driver == DriverPostgres {
+		return "5432"
 	}
+	return "3306"
 }
 
 // getEnv gets environment variable with default value.
@@ -47,3 +165,63 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getSecret gets a secret environment variable. Outside development and
+// test it must be set to something other than the placeholder, so a
+// deployment never signs with a publicly known key.
+func getSecret(env, key string) string {
+	value := os.Getenv(key)
+	if value != "" && value != devSecret {
+		return value
+	}
+	if !isDevelopment(env) {
+		log.Fatalf("%s must be set to a real secret when APP_ENV is %s", key, env)
+	}
+	return devSecret
+}
+
+// getPaymentDriver gets the payment gateway driver. The fake gateway
+// approves every payment without collecting money, so it is refused
+// outside development and test.
+func getPaymentDriver(env string) string {
+	driver := getEnv("PAYMENT_GATEWAY_DRIVER", "fake")
+	if driver == "fake" && !isDevelopment(env) {
+		log.Fatalf("PAYMENT_GATEWAY_DRIVER must name a real payment provider when APP_ENV is %s", env)
+	}
+	return driver
+}
+
+// isDevelopment checks if env runs without real secrets and providers.
+func isDevelopment(env string) bool {
+	return env == EnvDevelopment || env == EnvTest
+}
+
+// getEnvDuration gets environment variable as duration with default value.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using default: %v", key, err)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvBool gets environment variable as bool with default value.
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid bool for %s, using default: %v", key, err)
+		return defaultValue
+	}
+	return parsed
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+
+	"kikukafandi/book-shop-api/internal/adapter/mail"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// MailConfig holds mail configuration.
+type MailConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	LogPath  string
+}
+
+// NewMailer creates a mailer for the configured driver.
+func NewMailer(cfg MailConfig) (domain.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From), nil
+	case "log", "":
+		return mail.NewLogMailer(cfg.LogPath), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+
+	"kikukafandi/book-shop-api/internal/adapter/notify"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// StockAlertConfig holds low-stock alert configuration.
+type StockAlertConfig struct {
+	Driver     string
+	WebhookURL string
+	Email      string
+}
+
+// NewStockNotifier creates a low-stock notifier for the configured driver.
+// The email driver sends through mailer.
+func NewStockNotifier(cfg StockAlertConfig, mailer domain.Mailer) (domain.StockNotifier, error) {
+	switch cfg.Driver {
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("stock alert webhook driver needs STOCK_ALERT_WEBHOOK_URL")
+		}
+		return notify.NewWebhookNotifier(cfg.WebhookURL), nil
+	case "email":
+		if cfg.Email == "" {
+			return nil, fmt.Errorf("stock alert email driver needs STOCK_ALERT_EMAIL")
+		}
+		return notify.NewEmailNotifier(mailer, cfg.Email), nil
+	case "log", "":
+		return notify.NewLogNotifier(), nil
+	default:
+		return nil, fmt.Errorf("unknown stock alert driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PaymentConfig holds payment gateway configuration.
+type PaymentConfig struct {
+	Driver string
+	// WebhookSecret is shared with the provider to sign webhooks.
+	WebhookSecret string
+	// WebhookTolerance is how old or early a webhook's signing time may be.
+	WebhookTolerance time.Duration
+}
+
+// NewPaymentGateway creates a payment gateway for the configured driver.
+func NewPaymentGateway(cfg PaymentConfig) (domain.PaymentGateway, error) {
+	switch cfg.Driver {
+	case "fake", "":
+		return payment.NewFakeGateway(), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"kikukafandi/book-shop-api/internal/adapter/db"
+	"kikukafandi/book-shop-api/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Repositories holds the repository adapters for the configured database driver.
+type Repositories struct {
+	Books           domain.BookRepository
+	Users           domain.UserRepository
+	Orders          domain.OrderRepository
+	PasswordResets  domain.PasswordResetRepository
+	StockMovements  domain.StockMovementRepository
+	Reservations    domain.ReservationRepository
+	IdempotencyKeys domain.IdempotencyKeyRepository
+	Payments        domain.PaymentRepository
+	WebhookEvents   domain.WebhookEventRepository
+	ReturnRequests  domain.ReturnRequestRepository
+	Promotions      domain.PromotionRepository
+	BookPrices      domain.BookPriceRepository
+	Addresses       domain.AddressRepository
+	OrderShipments  domain.OrderShipmentRepository
+}
+
+// NewRepositories creates the repository adapters for the given driver. The
+// GORM repositories use portable SQL on every driver; only books need
+// PostgreSQL specific stock locking and full-text search.
+func NewRepositories(driver string, database *gorm.DB) Repositories {
+	repos := Repositories{
+		Books:           db.NewBookRepositoryGORM(database),
+		Users:           db.NewUserRepositoryGORM(database),
+		Orders:          db.NewOrderRepositoryGORM(database),
+		PasswordResets:  db.NewPasswordResetRepositoryGORM(database),
+		StockMovements:  db.NewStockMovementRepositoryGORM(database),
+		Reservations:    db.NewReservationRepositoryGORM(database),
+		IdempotencyKeys: db.NewIdempotencyKeyRepositoryGORM(database),
+		Payments:        db.NewPaymentRepositoryGORM(database),
+		WebhookEvents:   db.NewWebhookEventRepositoryGORM(database),
+		ReturnRequests:  db.NewReturnRequestRepositoryGORM(database),
+		Promotions:      db.NewPromotionRepositoryGORM(database),
+		BookPrices:      db.NewBookPriceRepositoryGORM(database),
+		Addresses:       db.NewAddressRepositoryGORM(database),
+		OrderShipments:  db.NewOrderShipmentRepositoryGORM(database),
+	}
+
+	if driver == DriverPostgres {
+		repos.Books = db.NewBookRepositoryPostgres(database)
+	}
+
+	return repos
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kikukafandi/book-shop-api/internal/adapter/shipping"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// ShippingConfig holds shipping configuration.
+type ShippingConfig struct {
+	// Methods is a semicolon separated list of code:basis:rates methods.
+	// Basis is weight, in grams, or quantity, and rates is a comma separated
+	// list of from=cost steps, e.g.
+	// "standard:weight:0=5,1000=8;express:quantity:1=15,3=25".
+	Methods string
+}
+
+// NewShippingCalculator creates a shipping calculator from the configured methods.
+func NewShippingCalculator(cfg ShippingConfig) (domain.ShippingCalculator, error) {
+	methods, err := parseShippingMethods(cfg.Methods)
+	if err != nil {
+		return nil, err
+	}
+
+	return shipping.NewRateTable(methods)
+}
+
+// parseShippingMethods parses the SHIPPING_METHODS format described on ShippingConfig.
+func parseShippingMethods(value string) ([]domain.ShippingMethod, error) {
+	var methods []domain.ShippingMethod
+
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid shipping method %q: want code:basis:rates", entry)
+		}
+
+		method := domain.ShippingMethod{
+			Code:  fields[0],
+			Basis: fields[1],
+		}
+		for _, step := range strings.Split(fields[2], ",") {
+			from, cost, ok := strings.Cut(strings.TrimSpace(step), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid rate %q of shipping method %q: want from=cost", step, fields[0])
+			}
+
+			var rate domain.ShippingRate
+			var err error
+			if rate.From, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid rate %q of shipping method %q: %w", step, fields[0], err)
+			}
+			if rate.Cost, err = strconv.ParseFloat(cost, 64); err != nil {
+				return nil, fmt.Errorf("invalid rate %q of shipping method %q: %w", step, fields[0], err)
+			}
+			method.Rates = append(method.Rates, rate)
+		}
+
+		methods = append(methods, method)
+	}
+
+	return methods, nil
+}
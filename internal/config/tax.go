@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kikukafandi/book-shop-api/internal/adapter/tax"
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// TaxConfig holds tax configuration.
+type TaxConfig struct {
+	// Rules is a comma separated list of region:category:rate rules, with
+	// an optional :inclusive suffix for prices that include tax. A * region
+	// or category matches any, e.g. "ID:*:11:inclusive,US-CA:*:7.25".
+	Rules string
+	// Region is where orders are taxed.
+	Region string
+}
+
+// NewTaxCalculator creates a tax calculator from the configured rule table.
+func NewTaxCalculator(cfg TaxConfig) (domain.TaxCalculator, error) {
+	rules, err := parseTaxRules(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return tax.NewRuleTable(rules)
+}
+
+// parseTaxRules parses the TAX_RULES format described on TaxConfig.
+func parseTaxRules(value string) ([]domain.TaxRule, error) {
+	var rules []domain.TaxRule
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, ":")
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid tax rule %q: want region:category:rate[:inclusive]", entry)
+		}
+
+		rate, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tax rate in rule %q: %w", entry, err)
+		}
+
+		rule := domain.TaxRule{
+			Region:   wildcard(fields[0]),
+			Category: wildcard(fields[1]),
+			Rate:     rate,
+		}
+		if len(fields) == 4 {
+			switch fields[3] {
+			case "inclusive":
+				rule.Inclusive = true
+			case "exclusive":
+			default:
+				return nil, fmt.Errorf("invalid tax rule %q: pricing must be inclusive or exclusive", entry)
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// wildcard turns the * of a tax rule into the empty value matching any.
+func wildcard(value string) string {
+	if value == "*" {
+		return ""
+	}
+	return value
+}
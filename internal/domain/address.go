@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Address is an entry in a user's address book.
+type Address struct {
+	ID     uint
+	UserID uint
+	// Label tells a user's addresses apart, e.g. home or office.
+	Label string
+	AddressSnapshot
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AddressSnapshot is where an order ships or is billed to. Orders keep a
+// copy of the address as it was when they were placed, so editing or
+// deleting an address never rewrites them.
+type AddressSnapshot struct {
+	// Name is who receives the parcel.
+	Name  string
+	Line1 string
+	Line2 string
+	City  string
+	// Region is the state, province or prefecture; some countries require it.
+	Region     string
+	PostalCode string
+	// Country is an ISO 3166-1 alpha-2 code, e.g. ID or US.
+	Country string
+	Phone   string
+}
+
+// addressFormat lists what a country's addresses need beyond a recipient,
+// street, city and country.
+type addressFormat struct {
+	requireRegion bool
+	postalCode    *regexp.Regexp
+}
+
+// addressFormats are the countries with rules of their own. Addresses in
+// other countries only need the common fields, and their postal code is
+// optional.
+var addressFormats = map[string]addressFormat{
+	"AU": {requireRegion: true, postalCode: regexp.MustCompile(`^\d{4}$`)},
+	"CA": {requireRegion: true, postalCode: regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`)},
+	"DE": {postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"GB": {postalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+	"ID": {requireRegion: true, postalCode: regexp.MustCompile(`^\d{5}$`)},
+	"JP": {requireRegion: true, postalCode: regexp.MustCompile(`^\d{3}-?\d{4}$`)},
+	"SG": {postalCode: regexp.MustCompile(`^\d{6}$`)},
+	"US": {requireRegion: true, postalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+}
+
+// countryCode matches ISO 3166-1 alpha-2 codes.
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Normalize trims the fields and upper-cases the country and postal code,
+// so they can be checked against the country's format.
+func (a AddressSnapshot) Normalize() AddressSnapshot {
+	return AddressSnapshot{
+		Name:       strings.TrimSpace(a.Name),
+		Line1:      strings.TrimSpace(a.Line1),
+		Line2:      strings.TrimSpace(a.Line2),
+		City:       strings.TrimSpace(a.City),
+		Region:     strings.TrimSpace(a.Region),
+		PostalCode: strings.ToUpper(strings.TrimSpace(a.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(a.Country)),
+		Phone:      strings.TrimSpace(a.Phone),
+	}
+}
+
+// Validate checks a normalized address against the rules of its country.
+func (a AddressSnapshot) Validate() error {
+	if !countryCode.MatchString(a.Country) {
+		return ErrInvalidCountry
+	}
+	if a.Name == "" || a.Line1 == "" || a.City == "" {
+		return ErrInvalidAddress
+	}
+
+	format, ok := addressFormats[a.Country]
+	if !ok {
+		return nil
+	}
+	if format.requireRegion && a.Region == "" {
+		return ErrAddressRegionRequired
+	}
+	if !format.postalCode.MatchString(a.PostalCode) {
+		return ErrInvalidPostalCode
+	}
+
+	return nil
+}
+
+// TaxRegion is the region orders shipped to the address are taxed in: the
+// country, followed by the region when there is one, e.g. US-CA.
+func (a AddressSnapshot) TaxRegion() string {
+	if a.Region == "" {
+		return a.Country
+	}
+	return a.Country + "-" + strings.ToUpper(a.Region)
+}
+
+// NewAddress creates a new Address entry for a user.
+func NewAddress(userID uint, label string, snapshot AddressSnapshot) Address {
+	now := time.Now()
+	return Address{
+		UserID:          userID,
+		Label:           strings.TrimSpace(label),
+		AddressSnapshot: snapshot.Normalize(),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "context"
+
+// AddressRepository is the port (interface) for address book persistence.
+type AddressRepository interface {
+	Save(ctx context.Context, address Address) (Address, error)
+	Update(ctx context.Context, address Address) (Address, error)
+	FindByID(ctx context.Context, id uint) (Address, error)
+	// FindByUserID returns a user's addresses, oldest first.
+	FindByUserID(ctx context.Context, userID uint) ([]Address, error)
+	// Delete deletes an address. Orders keep their copy of it.
+	Delete(ctx context.Context, id uint) error
+}
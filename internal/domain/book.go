@@ -1,14 +1,45 @@
 package domain
 
+import "time"
+
 // Book represents the book entity in domain layer.
 // No framework imports, no GORM tags - pure domain model.
 type Book struct {
 	ID    uint
 	Title string
-	Price float64
+	// Category groups books, e.g. for promotions; it is optional.
+	Category string
+	// Weight is the shipping weight of one copy in grams; weight-based
+	// shipping rates use it.
+	Weight int
+	Price  float64
+	// Stock is the quantity on hand, including stock reserved for pending orders.
 	Stock int
+	// Reserved is the part of Stock held by unexpired reservations.
+	Reserved int
+	// ReorderThreshold is the available stock below which the book should be
+	// reordered; zero disables low-stock alerts.
+	ReorderThreshold int
+	// ReorderQuantity is how many copies purchasing should reorder.
+	ReorderQuantity int
+	// AllowBackorder accepts orders beyond the available stock; they wait
+	// for incoming stock.
+	AllowBackorder bool
+	// AllowPreorder accepts orders beyond the available stock until ReleaseDate.
+	AllowPreorder bool
+	ReleaseDate   *time.Time
+	// Version is incremented on every update and used for optimistic locking.
+	Version uint
+	// DeletedAt is set when the book is soft deleted.
+	DeletedAt *time.Time
 }
 
+// StockCondition constants describe the state of copies coming back into stock.
+const (
+	StockConditionSellable = "sellable"
+	StockConditionDamaged  = "damaged"
+)
+
 // NewBook creates a new Book entity.
 func NewBook(title string, price float64, stock int) Book {
 	return Book{
@@ -18,21 +49,73 @@ func NewBook(title string, price float64, stock int) Book {
 	}
 }
 
-// IsAvailable checks if book has stock.
+// MatchesVersion checks if the book is at the expected version.
+// A zero expected version matches any version.
+func (b Book) MatchesVersion(expected uint) bool {
+	return expected == 0 || b.Version == expected
+}
+
+// Available returns the stock that is not reserved.
+func (b Book) Available() int {
+	return b.Stock - b.Reserved
+}
+
+// IsAvailable checks if book has unreserved stock.
 func (b Book) IsAvailable() bool {
-	return b.Stock > 0
+	return b.Available() > 0
+}
+
+// IsLowStock checks if available stock is below the reorder threshold.
+func (b Book) IsLowStock() bool {
+	return b.ReorderThreshold > 0 && b.Available() < b.ReorderThreshold
+}
+
+// IsPreorder checks if the book is taking pre-orders at the given time.
+func (b Book) IsPreorder(now time.Time) bool {
+	return b.AllowPreorder && b.ReleaseDate != nil && now.Before(*b.ReleaseDate)
+}
+
+// AcceptsBackorders checks if orders beyond the available stock can wait
+// for incoming stock instead of being rejected.
+func (b Book) AcceptsBackorders(now time.Time) bool {
+	return b.AllowBackorder || b.IsPreorder(now)
 }
 
 // DecreaseStock decreases book stock by given amount.
+// Reserved stock cannot be taken.
 func (b *Book) DecreaseStock(amount int) error {
-	if b.Stock < amount {
+	if b.Available() < amount {
 		return ErrInsufficientStock
 	}
 	b.Stock -= amount
 	return nil
 }
 
-// IncreaseStock increases book stock by given amount.
-func (b *Book) IncreaseStock(amount int) {
+// IncreaseStock increases book stock by given amount of copies in the given
+// condition. Damaged copies cannot be sold, so they are written off instead.
+func (b *Book) IncreaseStock(amount int, condition string) {
+	if condition == StockConditionDamaged {
+		return
+	}
 	b.Stock += amount
 }
+
+// Reserve holds the given amount of unreserved stock.
+func (b *Book) Reserve(amount int) error {
+	if b.Available() < amount {
+		return ErrInsufficientStock
+	}
+	b.Reserved += amount
+	return nil
+}
+
+// ReleaseReservation returns reserved stock to the available stock.
+func (b *Book) ReleaseReservation(amount int) {
+	b.Reserved = max(b.Reserved-amount, 0)
+}
+
+// CommitReservation turns reserved stock into a sale, removing it from stock.
+func (b *Book) CommitReservation(amount int) {
+	b.ReleaseReservation(amount)
+	b.Stock -= amount
+}
@@ -0,0 +1,91 @@
+package domain
+
+import "time"
+
+// BookPrice is an entry in a book's price history. Regular prices follow
+// each other: each one is in effect until the next one starts. Sale prices
+// override the regular price for a limited time.
+type BookPrice struct {
+	ID     uint
+	BookID uint
+	Type   string
+	Price  float64
+	// EffectiveFrom is when the price takes effect; it may be in the future.
+	EffectiveFrom time.Time
+	// EffectiveTo is when the price stops being in effect; nil means until
+	// further notice.
+	EffectiveTo *time.Time
+	CreatedAt   time.Time
+}
+
+// PriceQuote is what a book costs at a point in time.
+type PriceQuote struct {
+	// Price is what customers pay.
+	Price float64
+	// RegularPrice is the price without any sale.
+	RegularPrice float64
+	// SaleEndsAt is set when Price is a sale price.
+	SaleEndsAt *time.Time
+}
+
+// BookPriceType constants.
+const (
+	BookPriceTypeRegular = "regular"
+	BookPriceTypeSale    = "sale"
+)
+
+// NewBookPrice creates a new BookPrice entry.
+func NewBookPrice(bookID uint, priceType string, price float64, from time.Time, to *time.Time) BookPrice {
+	return BookPrice{
+		BookID:        bookID,
+		Type:          priceType,
+		Price:         price,
+		EffectiveFrom: from,
+		EffectiveTo:   to,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// IsEffective checks if the price is in effect at the given time.
+func (p BookPrice) IsEffective(at time.Time) bool {
+	if at.Before(p.EffectiveFrom) {
+		return false
+	}
+	return p.EffectiveTo == nil || at.Before(*p.EffectiveTo)
+}
+
+// Overlaps checks if two prices are in effect at the same time.
+func (p BookPrice) Overlaps(other BookPrice) bool {
+	startsBeforeOtherEnds := other.EffectiveTo == nil || p.EffectiveFrom.Before(*other.EffectiveTo)
+	endsAfterOtherStarts := p.EffectiveTo == nil || other.EffectiveFrom.Before(*p.EffectiveTo)
+	return startsBeforeOtherEnds && endsAfterOtherStarts
+}
+
+// QuotePrice works out a book's price at the given time from its price
+// history. A sale in effect wins over the regular price; books without a
+// regular price in effect, e.g. before their history starts, cost their
+// list price.
+func QuotePrice(book Book, prices []BookPrice, at time.Time) PriceQuote {
+	quote := PriceQuote{Price: book.Price, RegularPrice: book.Price}
+
+	var sale *BookPrice
+	for i, price := range prices {
+		if price.BookID != book.ID || !price.IsEffective(at) {
+			continue
+		}
+		switch price.Type {
+		case BookPriceTypeRegular:
+			quote.RegularPrice = price.Price
+		case BookPriceTypeSale:
+			sale = &prices[i]
+		}
+	}
+
+	quote.Price = quote.RegularPrice
+	if sale != nil {
+		quote.Price = sale.Price
+		quote.SaleEndsAt = sale.EffectiveTo
+	}
+
+	return quote
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BookPriceRepository is the port (interface) for price history persistence.
+type BookPriceRepository interface {
+	Save(ctx context.Context, price BookPrice) (BookPrice, error)
+	Update(ctx context.Context, price BookPrice) (BookPrice, error)
+	// FindByBookID returns a book's price history ordered by EffectiveFrom,
+	// then ID.
+	FindByBookID(ctx context.Context, bookID uint) ([]BookPrice, error)
+	// FindEffective returns the prices of the given books in effect at a
+	// point in time.
+	FindEffective(ctx context.Context, bookIDs []uint, at time.Time) ([]BookPrice, error)
+}
@@ -1,13 +1,46 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // BookRepository is the port (interface) for book persistence.
 // This interface lives in domain - implementations live in adapter/db.
 type BookRepository interface {
-	Save(ctx context.Context, book Book) (Book, error)
+	// Save creates a book and records its initial stock as movement in the
+	// same transaction.
+	Save(ctx context.Context, book Book, movement StockMovement) (Book, error)
 	FindByID(ctx context.Context, id uint) (Book, error)
-	FindAll(ctx context.Context) ([]Book, error)
+	// FindAll returns a page of books, oldest first, and how many books
+	// there are in all.
+	FindAll(ctx context.Context, page Page) ([]Book, int64, error)
+	// Search returns a page of the books whose title matches the query and
+	// how many match in all.
+	Search(ctx context.Context, query string, page Page) ([]Book, int64, error)
+	// FindLowStock returns books whose available stock is below their
+	// reorder threshold.
+	FindLowStock(ctx context.Context) ([]Book, error)
+	// Update saves the book only if it is still at book.Version and
+	// increments the version. It fails with ErrBookConflict otherwise.
+	// Stock and Reserved are not persisted; they only change through UpdateStock.
 	Update(ctx context.Context, book Book) (Book, error)
+	// UpdateStock loads the book under a row lock, lets apply change its
+	// stock and saves it atomically, so concurrent stock changes never
+	// overwrite each other. Only Stock and Reserved are persisted, and the
+	// stock change is appended to the ledger as movement in the same
+	// transaction. Reservation changes alone are not recorded.
+	UpdateStock(ctx context.Context, id uint, movement StockMovement, apply func(book *Book) error) (Book, error)
+	// ReconcileStock loads the book under a row lock and resets its stock to
+	// the sum of its ledger in the same transaction, so no stock change
+	// slips in between. A reset is appended to the ledger as movement, with
+	// no delta and the replaced stock as its reference. It returns the book
+	// as it was and its ledger stock; soft deleted books are reconciled too.
+	ReconcileStock(ctx context.Context, id uint, movement StockMovement) (Book, int, error)
+	// Delete soft deletes a book; it is hidden from FindByID and FindAll.
 	Delete(ctx context.Context, id uint) error
+	FindDeleted(ctx context.Context) ([]Book, error)
+	Restore(ctx context.Context, id uint) error
+	// Purge permanently removes books soft deleted before the given time.
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
@@ -4,14 +4,85 @@ import "errors"
 
 // Domain errors - these are business rule violations.
 var (
-	ErrBookNotFound      = errors.New("book not found")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrOrderNotFound     = errors.New("order not found")
-	ErrInsufficientStock = errors.New("insufficient stock")
-	ErrInvalidPrice      = errors.New("price must be positive")
-	ErrInvalidStock      = errors.New("stock cannot be negative")
-	ErrInvalidQuantity   = errors.New("quantity must be positive")
-	ErrEmailExists       = errors.New("email already exists")
-	ErrInvalidCredential = errors.New("invalid email or password")
-	ErrUnauthorized      = errors.New("unauthorized access")
+	ErrBookNotFound             = errors.New("book not found")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrOrderNotFound            = errors.New("order not found")
+	ErrInsufficientStock        = errors.New("insufficient stock")
+	ErrInvalidPrice             = errors.New("price must be positive")
+	ErrInvalidStock             = errors.New("stock cannot be negative")
+	ErrInvalidQuantity          = errors.New("quantity must be positive")
+	ErrEmailExists              = errors.New("email already exists")
+	ErrInvalidCredential        = errors.New("invalid email or password")
+	ErrUnauthorized             = errors.New("unauthorized access")
+	ErrInvalidResetToken        = errors.New("invalid or expired reset token")
+	ErrInvalidPassword          = errors.New("password must be at least 8 characters")
+	ErrInvalidVerifyToken       = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified         = errors.New("email not verified")
+	ErrVerifyThrottled          = errors.New("verification email recently requested, try again later")
+	ErrBookHasOpenOrders        = errors.New("book has orders that are not finished")
+	ErrUserHasOpenOrders        = errors.New("user has orders that are not finished")
+	ErrBookConflict             = errors.New("book was modified by another request")
+	ErrPrecondition             = errors.New("resource version does not match")
+	ErrInvalidAdjustment        = errors.New("invalid stock adjustment")
+	ErrEmptyShipment            = errors.New("shipment has no items")
+	ErrReservationNotFound      = errors.New("reservation not found")
+	ErrOrderNotPending          = errors.New("order is not pending")
+	ErrInvalidReorderLevel      = errors.New("reorder threshold and quantity cannot be negative")
+	ErrInvalidReleaseDate       = errors.New("pre-orders need a release date")
+	ErrOrderStatusChanged       = errors.New("order status was changed by another request")
+	ErrIdempotencyKeyExists     = errors.New("idempotency key already exists")
+	ErrIdempotencyKeyNotFound   = errors.New("idempotency key not found")
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be 1 to 255 characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
+	ErrPaymentNotFound          = errors.New("payment not found")
+	ErrInvalidPaymentToken      = errors.New("payment token is required")
+	ErrPaymentDeclined          = errors.New("payment was declined")
+	ErrPaymentTimeout           = errors.New("payment provider did not respond")
+	ErrPaymentExists            = errors.New("order already has an active payment")
+	ErrPaymentNotAuthorized     = errors.New("order has no authorized payment")
+	ErrPaymentRequired          = errors.New("order has no captured payment")
+	ErrPaymentChanged           = errors.New("payment was changed by another request")
+	ErrWebhookEventExists       = errors.New("webhook event already exists")
+	ErrWebhookEventNotFound     = errors.New("webhook event not found")
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrInvalidWebhookEvent      = errors.New("webhook event needs an id and a type")
+	ErrWebhookEventInProgress   = errors.New("webhook event is being processed")
+	ErrReturnNotFound           = errors.New("return request not found")
+	ErrOrderNotReturnable       = errors.New("only completed or delivered orders can be returned")
+	ErrReturnQuantityExceeded   = errors.New("return quantity exceeds the copies not yet returned")
+	ErrReturnNotRequested       = errors.New("return request was already resolved")
+	ErrInvalidStockCondition    = errors.New("condition must be sellable or damaged")
+	ErrInvalidRefundAmount      = errors.New("refund amount must be positive and within the unrefunded total")
+	ErrRefundDeclined           = errors.New("payment provider declined the refund")
+	ErrPromotionNotFound        = errors.New("promotion not found")
+	ErrPromotionCodeExists      = errors.New("promotion code already exists")
+	ErrInvalidPromotionCode     = errors.New("promotion code must be 1 to 50 characters")
+	ErrInvalidPromotionType     = errors.New("promotion type must be percentage or fixed")
+	ErrInvalidPromotionValue    = errors.New("promotion value must be positive, and at most 100 for percentages")
+	ErrInvalidPromotionLimits   = errors.New("minimum spend and usage limits cannot be negative")
+	ErrInvalidPromotionWindow   = errors.New("promotion must end after it starts")
+	ErrPromotionNotActive       = errors.New("promotion is not active")
+	ErrPromotionNotApplicable   = errors.New("promotion does not apply to this book")
+	ErrPromotionMinimumSpend    = errors.New("order does not reach the promotion's minimum spend")
+	ErrPromotionUsedUp          = errors.New("promotion has reached its usage limit")
+	ErrPromotionUserLimit       = errors.New("promotion was already used the allowed number of times")
+	ErrInvalidPriceType         = errors.New("price type must be regular or sale")
+	ErrInvalidPriceSchedule     = errors.New("prices must start now or later, and only sales end, after they start")
+	ErrPriceOverlap             = errors.New("price overlaps an existing price of the same type")
+	ErrTaxRegionUnsupported     = errors.New("orders cannot be taxed in this region")
+	ErrAddressNotFound          = errors.New("address not found")
+	ErrInvalidAddress           = errors.New("address needs a recipient name, street and city")
+	ErrInvalidCountry           = errors.New("country must be a two-letter ISO 3166 code")
+	ErrAddressRegionRequired    = errors.New("addresses in this country need a region")
+	ErrInvalidPostalCode        = errors.New("postal code is missing or invalid for this country")
+	ErrInvalidWeight            = errors.New("weight cannot be negative")
+	ErrInvalidShippingMethod    = errors.New("unknown shipping method")
+	ErrShippingAddressRequired  = errors.New("shipping needs a shipping address")
+	ErrShippingUnavailable      = errors.New("shipping method does not cover this order")
+	ErrOrderNotShippable        = errors.New("order has no shipping method")
+	ErrOrderNotFulfillable      = errors.New("order is not at the previous fulfilment step")
+	ErrOrderShipmentNotFound    = errors.New("order shipment not found")
+	ErrInvalidShipment          = errors.New("shipping needs a carrier and a tracking number")
+	ErrOrderNotDelivered        = errors.New("shipped orders are completed once delivered")
 )
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// IdempotencyKey remembers the response to a request sent with a client
+// chosen key, so retries of the same request can be answered without
+// running it again.
+type IdempotencyKey struct {
+	ID  uint
+	Key string
+	// Fingerprint identifies the request the key was first used with.
+	Fingerprint string
+	// StatusCode and ResponseBody are the stored response; StatusCode is
+	// zero while the first request is still being handled.
+	StatusCode   int
+	ResponseBody []byte
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// NewIdempotencyKey creates a new IdempotencyKey entity for a request in progress.
+func NewIdempotencyKey(key, fingerprint string, ttl time.Duration) IdempotencyKey {
+	now := time.Now()
+	return IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}
+}
+
+// IsCompleted checks if the response of the first request is stored.
+func (k IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
+}
+
+// IsExpired checks if the key is past its expiry time.
+func (k IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyKeyRepository is the port (interface) for idempotency key persistence.
+type IdempotencyKeyRepository interface {
+	// Create stores a new key. It must fail with ErrIdempotencyKeyExists if
+	// the key is already stored, so only one request can claim it.
+	Create(ctx context.Context, key IdempotencyKey) (IdempotencyKey, error)
+	FindByKey(ctx context.Context, key string) (IdempotencyKey, error)
+	// Complete stores the response of the request that claimed the key.
+	Complete(ctx context.Context, id uint, statusCode int, body []byte) error
+	Delete(ctx context.Context, id uint) error
+	// DeleteExpired removes keys that expired at or before now.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
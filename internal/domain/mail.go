@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// Mail represents an outgoing email message.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer is the port (interface) for sending emails.
+// Implementations live in adapter/mail.
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
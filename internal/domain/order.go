@@ -1,37 +1,127 @@
 package domain
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // Order represents the order entity in domain layer.
 type Order struct {
-	ID        uint
-	UserID    uint
-	BookID    uint
-	Quantity  int
-	Total     float64
-	Status    string
-	CreatedAt time.Time
+	ID       uint
+	UserID   uint
+	BookID   uint
+	Quantity int
+	// UnitPrice is what one copy cost when the order was placed.
+	UnitPrice float64
+	// Subtotal is the price of the order after discounts, before tax.
+	Subtotal float64
+	Tax      float64
+	// ShippingMethod is the code of the shipping method the order ships
+	// with, and ShippingCost what it cost; orders without one are not shipped.
+	ShippingMethod string
+	ShippingCost   float64
+	// Total is what the customer pays: Subtotal plus Tax plus ShippingCost.
+	Total float64
+	// Discount is the amount a promotion took off the order; Subtotal and
+	// Total are already reduced by it.
+	Discount float64
+	// PromotionCode is the code of the promotion applied to the order.
+	PromotionCode string
+	// RefundedAmount is the part of Total refunded through returns.
+	RefundedAmount float64
+	// ShippingAddress and BillingAddress are copies of the addresses the
+	// order was placed with; nil when none was given.
+	ShippingAddress *AddressSnapshot
+	BillingAddress  *AddressSnapshot
+	Status          string
+	CreatedAt       time.Time
+	// DeletedAt is set when the order is soft deleted.
+	DeletedAt *time.Time
+}
+
+// OrphanedOrder is an order whose user or book no longer exists.
+type OrphanedOrder struct {
+	Order       Order
+	MissingUser bool
+	MissingBook bool
 }
 
 // OrderStatus constants.
 const (
-	OrderStatusPending   = "pending"
-	OrderStatusCompleted = "completed"
-	OrderStatusCancelled = "cancelled"
+	OrderStatusPending     = "pending"
+	OrderStatusCompleted   = "completed"
+	OrderStatusCancelled   = "cancelled"
+	OrderStatusBackordered = "backordered"
+	OrderStatusPaid        = "paid"
+	// Paid orders with a shipping method are packed, shipped and delivered.
+	OrderStatusPacked    = "packed"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	// Completed orders become partially refunded, then refunded, as
+	// their returns are approved.
+	OrderStatusPartiallyRefunded = "partially_refunded"
+	OrderStatusRefunded          = "refunded"
 )
 
-// NewOrder creates a new Order entity.
-func NewOrder(userID, bookID uint, quantity int, total float64) Order {
+// NewOrder creates a new Order entity for quantity copies at unitPrice each.
+func NewOrder(userID, bookID uint, quantity int, unitPrice float64) Order {
 	return Order{
 		UserID:    userID,
 		BookID:    bookID,
 		Quantity:  quantity,
-		Total:     total,
+		UnitPrice: unitPrice,
+		Subtotal:  roundMoney(unitPrice * float64(quantity)),
+		Total:     roundMoney(unitPrice * float64(quantity)),
 		Status:    OrderStatusPending,
 		CreatedAt: time.Now(),
 	}
 }
 
+// ApplyPromotion takes a promotion's discount off the order total.
+func (o *Order) ApplyPromotion(promotion Promotion, discount float64) {
+	o.PromotionCode = promotion.Code
+	o.Discount = discount
+	o.Total = roundMoney(o.Total - discount)
+	o.Subtotal = o.Total
+}
+
+// ApplyTax splits the order total into its subtotal and tax. Shelf prices
+// already include tax in some regions, so the total may stay the same.
+func (o *Order) ApplyTax(breakdown TaxBreakdown) {
+	o.Subtotal = breakdown.Subtotal
+	o.Tax = breakdown.Tax
+	o.Total = breakdown.Total
+}
+
+// ApplyShipping adds the cost of shipping with a method to the order total.
+// Shipping is not taxed.
+func (o *Order) ApplyShipping(method string, cost float64) {
+	o.ShippingMethod = method
+	o.ShippingCost = cost
+	o.Total = roundMoney(o.Total + cost)
+}
+
+// fulfilmentSteps maps each fulfilment status to the status it follows.
+var fulfilmentSteps = map[string]string{
+	OrderStatusPacked:    OrderStatusPaid,
+	OrderStatusShipped:   OrderStatusPacked,
+	OrderStatusDelivered: OrderStatusShipped,
+}
+
+// Fulfil moves a shipped order to the next fulfilment status: paid orders
+// are packed, packed orders shipped and shipped orders delivered.
+func (o *Order) Fulfil(status string) error {
+	if !o.IsShippable() {
+		return ErrOrderNotShippable
+	}
+	if from, ok := fulfilmentSteps[status]; !ok || o.Status != from {
+		return ErrOrderNotFulfillable
+	}
+
+	o.Status = status
+	return nil
+}
+
 // Complete marks order as completed.
 func (o *Order) Complete() {
 	o.Status = OrderStatusCompleted
@@ -42,7 +132,129 @@ func (o *Order) Cancel() {
 	o.Status = OrderStatusCancelled
 }
 
+// MarkPaid marks order as paid once its payment is captured.
+func (o *Order) MarkPaid() {
+	o.Status = OrderStatusPaid
+}
+
+// Refund records an approved return's refund. Completed, delivered and
+// partially refunded orders can be refunded, never beyond their total. The
+// order is refunded once its copies are: shipping need not be refunded.
+func (o *Order) Refund(amount float64) error {
+	if !o.IsReturnable() {
+		return ErrOrderNotReturnable
+	}
+	refunded := roundMoney(o.RefundedAmount + amount)
+	if amount <= 0 || refunded > roundMoney(o.Total) {
+		return ErrInvalidRefundAmount
+	}
+
+	o.RefundedAmount = refunded
+	if refunded >= roundMoney(o.Total-o.ShippingCost) {
+		o.Status = OrderStatusRefunded
+	} else {
+		o.Status = OrderStatusPartiallyRefunded
+	}
+	return nil
+}
+
+// RefundFor returns the share of the total paid for the given number of
+// copies. Shipping is not part of it: the order was shipped all the same.
+func (o Order) RefundFor(quantity int) float64 {
+	if o.Quantity == 0 {
+		return 0
+	}
+	return roundMoney((o.Total - o.ShippingCost) * float64(quantity) / float64(o.Quantity))
+}
+
+// Backorder marks order as waiting for stock.
+func (o *Order) Backorder() {
+	o.Status = OrderStatusBackordered
+}
+
+// IsBackordered checks if order is waiting for stock.
+func (o Order) IsBackordered() bool {
+	return o.Status == OrderStatusBackordered
+}
+
+// IsOpen checks if order can still be cancelled.
+func (o Order) IsOpen() bool {
+	return o.IsPending() || o.IsBackordered()
+}
+
+// IsUnfinished checks if order still waits for stock, payment, shipping or
+// completion, so its book and user must stay.
+func (o Order) IsUnfinished() bool {
+	switch o.Status {
+	case OrderStatusPending, OrderStatusBackordered, OrderStatusPaid,
+		OrderStatusPacked, OrderStatusShipped, OrderStatusDelivered:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsPending checks if order is still pending.
 func (o Order) IsPending() bool {
 	return o.Status == OrderStatusPending
 }
+
+// IsPaid checks if order is paid and waiting to be completed.
+func (o Order) IsPaid() bool {
+	return o.Status == OrderStatusPaid
+}
+
+// IsShippable checks if order was placed with a shipping method.
+func (o Order) IsShippable() bool {
+	return o.ShippingMethod != ""
+}
+
+// IsCompletable checks if order waits to be completed: a shipped order once
+// it is delivered, any other once it is paid.
+func (o Order) IsCompletable() bool {
+	if o.IsShippable() {
+		return o.Status == OrderStatusDelivered
+	}
+	return o.IsPaid()
+}
+
+// IsInTransit checks if a shipped order is paid but not delivered yet.
+func (o Order) IsInTransit() bool {
+	if !o.IsShippable() {
+		return false
+	}
+	switch o.Status {
+	case OrderStatusPaid, OrderStatusPacked, OrderStatusShipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCompleted checks if order is completed.
+func (o Order) IsCompleted() bool {
+	return o.Status == OrderStatusCompleted
+}
+
+// WasPaid checks if order's payment was captured, whatever happened to
+// the order since.
+func (o Order) WasPaid() bool {
+	switch o.Status {
+	case OrderStatusPaid, OrderStatusPacked, OrderStatusShipped, OrderStatusDelivered,
+		OrderStatusCompleted, OrderStatusPartiallyRefunded, OrderStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsReturnable checks if order was completed or delivered and not fully
+// refunded yet.
+func (o Order) IsReturnable() bool {
+	return o.IsCompleted() || o.Status == OrderStatusDelivered || o.Status == OrderStatusPartiallyRefunded
+}
+
+// roundMoney rounds an amount to cents.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -1,13 +1,37 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // OrderRepository is the port (interface) for order persistence.
 type OrderRepository interface {
 	Save(ctx context.Context, order Order) (Order, error)
 	FindByID(ctx context.Context, id uint) (Order, error)
 	FindByUserID(ctx context.Context, userID uint) ([]Order, error)
-	FindAll(ctx context.Context) ([]Order, error)
+	// FindAll returns a page of orders, oldest first, and how many orders
+	// there are in all.
+	FindAll(ctx context.Context, page Page) ([]Order, int64, error)
 	Update(ctx context.Context, order Order) (Order, error)
+	// UpdateStatus moves an order from one status to another. It fails with
+	// ErrOrderStatusChanged if the order is no longer in the from status.
+	UpdateStatus(ctx context.Context, id uint, from, to string) error
+	// UpdateRefund moves an order's refunded amount and status from before
+	// to after. It fails with ErrOrderStatusChanged if either changed since
+	// the order was read as before.
+	UpdateRefund(ctx context.Context, before, after Order) error
+	// FindBackordered returns a book's backordered orders, oldest first.
+	FindBackordered(ctx context.Context, bookID uint) ([]Order, error)
+	// Delete soft deletes an order; it is hidden from all finders.
 	Delete(ctx context.Context, id uint) error
+	FindDeleted(ctx context.Context) ([]Order, error)
+	Restore(ctx context.Context, id uint) error
+	// Purge permanently removes orders soft deleted before the given time.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// CountOpenByBookID and CountOpenByUserID count unfinished orders:
+	// those still waiting for stock, payment, shipping or completion.
+	CountOpenByBookID(ctx context.Context, bookID uint) (int64, error)
+	CountOpenByUserID(ctx context.Context, userID uint) (int64, error)
+	FindOrphaned(ctx context.Context) ([]OrphanedOrder, error)
 }
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// OrderShipment is the parcel an order goes out in. It is created when the
+// order is packed and follows it until it is delivered.
+type OrderShipment struct {
+	ID      uint
+	OrderID uint
+	// Carrier and TrackingNumber are set when the parcel is handed over.
+	Carrier        string
+	TrackingNumber string
+	PackedAt       time.Time
+	ShippedAt      *time.Time
+	DeliveredAt    *time.Time
+}
+
+// NewOrderShipment creates a new OrderShipment for a packed order.
+func NewOrderShipment(orderID uint) OrderShipment {
+	return OrderShipment{
+		OrderID:  orderID,
+		PackedAt: time.Now(),
+	}
+}
+
+// Ship records that the parcel was handed to a carrier.
+func (s *OrderShipment) Ship(carrier, trackingNumber string) error {
+	carrier = strings.TrimSpace(carrier)
+	trackingNumber = strings.TrimSpace(trackingNumber)
+	if carrier == "" || trackingNumber == "" {
+		return ErrInvalidShipment
+	}
+
+	now := time.Now()
+	s.Carrier = carrier
+	s.TrackingNumber = trackingNumber
+	s.ShippedAt = &now
+	return nil
+}
+
+// Deliver records that the parcel reached the customer.
+func (s *OrderShipment) Deliver() {
+	now := time.Now()
+	s.DeliveredAt = &now
+}
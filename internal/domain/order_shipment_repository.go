@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// OrderShipmentRepository is the port (interface) for order shipment persistence.
+type OrderShipmentRepository interface {
+	Save(ctx context.Context, shipment OrderShipment) (OrderShipment, error)
+	Update(ctx context.Context, shipment OrderShipment) (OrderShipment, error)
+	// FindByOrderID returns an order's shipment. It fails with
+	// ErrOrderShipmentNotFound if the order was not packed yet.
+	FindByOrderID(ctx context.Context, orderID uint) (OrderShipment, error)
+}
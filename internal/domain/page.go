@@ -0,0 +1,8 @@
+package domain
+
+// Page selects part of a list: at most Limit items after skipping the
+// first Offset. A zero Limit selects the whole list.
+type Page struct {
+	Limit  int
+	Offset int
+}
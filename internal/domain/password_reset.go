@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// PasswordReset represents a single-use password reset token.
+// Only the hash of the token is stored, never the raw value.
+type PasswordReset struct {
+	ID        uint
+	UserID    uint
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NewPasswordReset creates a new PasswordReset entity.
+func NewPasswordReset(userID uint, tokenHash string, ttl time.Duration) PasswordReset {
+	now := time.Now()
+	return PasswordReset{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired checks if the token is past its expiry time.
+func (p PasswordReset) IsExpired(now time.Time) bool {
+	return !now.Before(p.ExpiresAt)
+}
+
+// IsUsed checks if the token has already been consumed.
+func (p PasswordReset) IsUsed() bool {
+	return p.UsedAt != nil
+}
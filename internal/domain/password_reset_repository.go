@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PasswordResetRepository is the port (interface) for password reset token persistence.
+type PasswordResetRepository interface {
+	Save(ctx context.Context, reset PasswordReset) (PasswordReset, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (PasswordReset, error)
+	// MarkUsed consumes the token. It must fail with ErrInvalidResetToken
+	// if the token was already used, so concurrent resets cannot both succeed.
+	MarkUsed(ctx context.Context, id uint, usedAt time.Time) error
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
@@ -0,0 +1,111 @@
+package domain
+
+import "time"
+
+// Payment is one attempt to collect an order's total through the payment gateway.
+type Payment struct {
+	ID      uint
+	OrderID uint
+	Amount  float64
+	Status  string
+	// Reference is the gateway's identifier of the authorization.
+	Reference string
+	// FailureReason explains a declined or failed authorization.
+	FailureReason  string
+	RefundedAmount float64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PaymentStatus constants.
+const (
+	PaymentStatusPending    = "pending"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusDeclined   = "declined"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusVoided     = "voided"
+	PaymentStatusRefunded   = "refunded"
+)
+
+// NewPayment creates a new Payment entity for an order, before the gateway is asked.
+func NewPayment(order Order) Payment {
+	now := time.Now()
+	return Payment{
+		OrderID:   order.ID,
+		Amount:    order.Total,
+		Status:    PaymentStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Authorize marks payment as authorized by the gateway.
+func (p *Payment) Authorize(reference string) {
+	p.Status = PaymentStatusAuthorized
+	p.Reference = reference
+}
+
+// Decline marks payment as declined by the gateway.
+func (p *Payment) Decline(reason string) {
+	p.Status = PaymentStatusDeclined
+	p.FailureReason = reason
+}
+
+// Fail marks payment as failed because the gateway could not be reached.
+func (p *Payment) Fail(reason string) {
+	p.Status = PaymentStatusFailed
+	p.FailureReason = reason
+}
+
+// Capture marks payment as captured.
+func (p *Payment) Capture() {
+	p.Status = PaymentStatusCaptured
+}
+
+// Void marks payment as voided.
+func (p *Payment) Void() {
+	p.Status = PaymentStatusVoided
+}
+
+// RecordRefunded records the total refunded so far. Totals only grow, so a
+// repeated or late report of a refund changes nothing; the payment is
+// refunded once nothing is left.
+func (p *Payment) RecordRefunded(total float64) {
+	total = roundMoney(total)
+	if total <= p.RefundedAmount {
+		return
+	}
+
+	p.RefundedAmount = total
+	if total >= roundMoney(p.Amount) {
+		p.Status = PaymentStatusRefunded
+	}
+}
+
+// IsAuthorized checks if payment is authorized but not yet captured.
+func (p Payment) IsAuthorized() bool {
+	return p.Status == PaymentStatusAuthorized
+}
+
+// IsUnsettled checks if payment is still waiting for the provider's answer,
+// e.g. because the authorization timed out.
+func (p Payment) IsUnsettled() bool {
+	return p.Status == PaymentStatusPending || p.Status == PaymentStatusFailed
+}
+
+// IsCaptured checks if payment has been captured.
+func (p Payment) IsCaptured() bool {
+	return p.Status == PaymentStatusCaptured
+}
+
+// IsActive checks if payment holds or has collected the customer's money.
+func (p Payment) IsActive() bool {
+	return p.IsAuthorized() || p.IsCaptured()
+}
+
+// HoldsOrder checks if payment keeps its order from being paid otherwise:
+// it is on its way to the provider, or holds or has collected the money.
+func (p Payment) HoldsOrder() bool {
+	return p.Status == PaymentStatusPending || p.IsActive()
+}
@@ -0,0 +1,24 @@
+package domain
+
+// PaymentEvent is a payment result reported asynchronously by the payment provider.
+type PaymentEvent struct {
+	// ID is the provider's identifier of the event.
+	ID   string
+	Type string
+	// PaymentID is the payment the provider was asked about.
+	PaymentID uint
+	// Reference is the provider's identifier of the authorization.
+	Reference string
+	// Amount is the total refunded so far for refunded events.
+	Amount float64
+	Reason string
+}
+
+// PaymentEvent types.
+const (
+	PaymentEventAuthorized = "payment.authorized"
+	PaymentEventDeclined   = "payment.declined"
+	PaymentEventCaptured   = "payment.captured"
+	PaymentEventVoided     = "payment.voided"
+	PaymentEventRefunded   = "payment.refunded"
+)
@@ -0,0 +1,35 @@
+package domain
+
+import "context"
+
+// PaymentGateway is the port (interface) for collecting money through a
+// payment provider. A declined request is a result, not an error; errors
+// mean the provider could not answer, e.g. ErrPaymentTimeout.
+type PaymentGateway interface {
+	// Authorize holds the amount on the customer's payment method.
+	Authorize(ctx context.Context, request PaymentRequest) (PaymentResult, error)
+	// Capture collects a previously authorized amount.
+	Capture(ctx context.Context, reference string, amount float64) (PaymentResult, error)
+	// Refund returns captured money to the customer.
+	Refund(ctx context.Context, reference string, amount float64) (PaymentResult, error)
+	// Void releases an authorization that will not be captured.
+	Void(ctx context.Context, reference string) (PaymentResult, error)
+}
+
+// PaymentRequest asks the gateway to authorize an order's total.
+type PaymentRequest struct {
+	OrderID uint
+	// PaymentID is echoed back by the provider in webhooks about the payment.
+	PaymentID uint
+	Amount    float64
+	// Token identifies the customer's payment method at the provider.
+	Token string
+}
+
+// PaymentResult is the provider's answer to a gateway request.
+type PaymentResult struct {
+	Approved bool
+	// Reference is the provider's identifier of the authorization.
+	Reference     string
+	DeclineReason string
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+// PaymentRepository is the port (interface) for payment persistence.
+type PaymentRepository interface {
+	// Save and Update fail with ErrPaymentExists if the payment would hold
+	// an order another payment already holds.
+	Save(ctx context.Context, payment Payment) (Payment, error)
+	Update(ctx context.Context, payment Payment) (Payment, error)
+	// UpdateRefund moves a payment's refunded amount and status from before
+	// to after. It fails with ErrPaymentChanged if either changed since the
+	// payment was read as before.
+	UpdateRefund(ctx context.Context, before, after Payment) error
+	FindByID(ctx context.Context, id uint) (Payment, error)
+	// FindByOrderID returns an order's payments, oldest first.
+	FindByOrderID(ctx context.Context, orderID uint) ([]Payment, error)
+}
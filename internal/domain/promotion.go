@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Promotion is a coupon customers enter at order time to get a discount.
+type Promotion struct {
+	ID uint
+	// Code is what customers enter; it is stored upper case and unique.
+	Code        string
+	Description string
+	Type        string
+	// Value is a percentage for percentage promotions and an amount off
+	// the order for fixed ones.
+	Value float64
+	// MinSpend is the order subtotal needed for the discount; zero means none.
+	MinSpend float64
+	// UsageLimit caps redemptions across all customers; zero means unlimited.
+	UsageLimit int
+	// PerUserLimit caps redemptions per customer; zero means unlimited.
+	PerUserLimit int
+	// UsedCount is how many orders currently hold a redemption.
+	UsedCount int
+	// StartsAt and EndsAt bound when the promotion can be used; either
+	// may be left open.
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	// BookID and Category restrict the promotion to a book or to a
+	// category; when both are empty it applies to every book.
+	BookID    *uint
+	Category  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PromotionRedemption records an order that used a promotion.
+type PromotionRedemption struct {
+	ID          uint
+	PromotionID uint
+	UserID      uint
+	OrderID     uint
+	CreatedAt   time.Time
+}
+
+// PromotionType constants.
+const (
+	PromotionTypePercentage = "percentage"
+	PromotionTypeFixed      = "fixed"
+)
+
+// NormalizePromotionCode returns the form codes are stored and looked up in.
+func NormalizePromotionCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks that a promotion can be offered to customers.
+func (p Promotion) Validate() error {
+	if p.Code == "" || len(p.Code) > 50 {
+		return ErrInvalidPromotionCode
+	}
+
+	switch p.Type {
+	case PromotionTypePercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return ErrInvalidPromotionValue
+		}
+	case PromotionTypeFixed:
+		if p.Value <= 0 {
+			return ErrInvalidPromotionValue
+		}
+	default:
+		return ErrInvalidPromotionType
+	}
+
+	if p.MinSpend < 0 || p.UsageLimit < 0 || p.PerUserLimit < 0 {
+		return ErrInvalidPromotionLimits
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return ErrInvalidPromotionWindow
+	}
+
+	return nil
+}
+
+// IsActive checks if the promotion can be used at the given time.
+func (p Promotion) IsActive(now time.Time) bool {
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+// AppliesTo checks if the promotion targets the book.
+func (p Promotion) AppliesTo(book Book) bool {
+	if p.BookID != nil && *p.BookID != book.ID {
+		return false
+	}
+	return p.Category == "" || strings.EqualFold(p.Category, book.Category)
+}
+
+// Discount returns the amount taken off an order of the book with the given
+// subtotal. A discount never exceeds the subtotal. Usage limits are checked
+// when the promotion is redeemed.
+func (p Promotion) Discount(book Book, subtotal float64, now time.Time) (float64, error) {
+	if !p.IsActive(now) {
+		return 0, ErrPromotionNotActive
+	}
+	if !p.AppliesTo(book) {
+		return 0, ErrPromotionNotApplicable
+	}
+	if subtotal < p.MinSpend {
+		return 0, ErrPromotionMinimumSpend
+	}
+
+	discount := p.Value
+	if p.Type == PromotionTypePercentage {
+		discount = subtotal * p.Value / 100
+	}
+
+	return roundMoney(math.Min(discount, subtotal)), nil
+}
+
+// NewPromotionRedemption creates a redemption of a promotion by an order.
+func NewPromotionRedemption(promotion Promotion, order Order) PromotionRedemption {
+	return PromotionRedemption{
+		PromotionID: promotion.ID,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		CreatedAt:   time.Now(),
+	}
+}
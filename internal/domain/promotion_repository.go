@@ -0,0 +1,24 @@
+package domain
+
+import "context"
+
+// PromotionRepository is the port (interface) for promotion persistence.
+type PromotionRepository interface {
+	// Save saves a new promotion; a taken code returns ErrPromotionCodeExists.
+	Save(ctx context.Context, promotion Promotion) (Promotion, error)
+	Update(ctx context.Context, promotion Promotion) (Promotion, error)
+	FindByID(ctx context.Context, id uint) (Promotion, error)
+	// FindByCode finds a promotion by its normalized code.
+	FindByCode(ctx context.Context, code string) (Promotion, error)
+	// FindAll returns all promotions, oldest first.
+	FindAll(ctx context.Context) ([]Promotion, error)
+	// Delete deletes a promotion and its redemptions.
+	Delete(ctx context.Context, id uint) error
+	// Redeem records the redemption unless the promotion's global or
+	// per-user limit is reached. The check and the insert happen under a
+	// lock on the promotion so concurrent orders cannot exceed the limits.
+	Redeem(ctx context.Context, redemption PromotionRedemption) error
+	// Release deletes an order's redemption so the promotion can be used
+	// again. Orders without a redemption are ignored.
+	Release(ctx context.Context, orderID uint) error
+}
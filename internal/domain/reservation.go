@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Reservation holds stock for a pending order until it expires.
+// Reserved stock stays on hand but cannot be ordered by anyone else.
+type Reservation struct {
+	ID        uint
+	OrderID   uint
+	BookID    uint
+	Quantity  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// NewReservation creates a new Reservation for an order.
+func NewReservation(order Order, ttl time.Duration) Reservation {
+	now := time.Now()
+	return Reservation{
+		OrderID:   order.ID,
+		BookID:    order.BookID,
+		Quantity:  order.Quantity,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired checks if the reservation is past its expiry time.
+func (r Reservation) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ReservationRepository is the port (interface) for stock reservation persistence.
+// The reserved quantity itself is kept on the book by BookRepository.UpdateStock.
+type ReservationRepository interface {
+	Save(ctx context.Context, reservation Reservation) (Reservation, error)
+	FindByOrderID(ctx context.Context, orderID uint) (Reservation, error)
+	// FindExpired returns reservations that expired at or before now, oldest first.
+	FindExpired(ctx context.Context, now time.Time) ([]Reservation, error)
+	// Delete consumes the reservation. It must fail with ErrReservationNotFound
+	// if it was already deleted, so checkout and the sweeper cannot both use it.
+	Delete(ctx context.Context, id uint) error
+}
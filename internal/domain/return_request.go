@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// ReturnRequest is a customer's request to send back copies of a completed
+// order. Orders hold a single book, so a return covers some or all of the
+// order's copies.
+type ReturnRequest struct {
+	ID       uint
+	OrderID  uint
+	Quantity int
+	Reason   string
+	Status   string
+	// Condition is the state the returned copies arrived in, set on approval.
+	Condition string
+	// RefundAmount is the amount refunded on approval.
+	RefundAmount float64
+	// RejectionReason explains a rejected return to the customer.
+	RejectionReason string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ReturnStatus constants.
+const (
+	ReturnStatusRequested = "requested"
+	// ReturnStatusApproving holds a return while its refund is with the
+	// payment provider, so only one approval can refund it.
+	ReturnStatusApproving = "approving"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+)
+
+// NewReturnRequest creates a new ReturnRequest waiting for approval.
+func NewReturnRequest(orderID uint, quantity int, reason string) ReturnRequest {
+	now := time.Now()
+	return ReturnRequest{
+		OrderID:   orderID,
+		Quantity:  quantity,
+		Reason:    reason,
+		Status:    ReturnStatusRequested,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Approve marks return as approved and refunded.
+func (r *ReturnRequest) Approve(condition string, refundAmount float64) {
+	r.Status = ReturnStatusApproved
+	r.Condition = condition
+	r.RefundAmount = refundAmount
+}
+
+// Reject marks return as rejected.
+func (r *ReturnRequest) Reject(reason string) {
+	r.Status = ReturnStatusRejected
+	r.RejectionReason = reason
+}
+
+// IsRequested checks if return is still waiting for approval.
+func (r ReturnRequest) IsRequested() bool {
+	return r.Status == ReturnStatusRequested
+}
+
+// IsRejected checks if return was rejected.
+func (r ReturnRequest) IsRejected() bool {
+	return r.Status == ReturnStatusRejected
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// ReturnRequestRepository is the port (interface) for return request persistence.
+type ReturnRequestRepository interface {
+	Save(ctx context.Context, request ReturnRequest) (ReturnRequest, error)
+	Update(ctx context.Context, request ReturnRequest) (ReturnRequest, error)
+	// UpdateStatus moves a return request from one status to another. It
+	// fails with ErrReturnNotRequested if the request is no longer in the
+	// from status.
+	UpdateStatus(ctx context.Context, id uint, from, to string) error
+	FindByID(ctx context.Context, id uint) (ReturnRequest, error)
+	// FindByOrderID returns an order's return requests, oldest first.
+	FindByOrderID(ctx context.Context, orderID uint) ([]ReturnRequest, error)
+	// FindByStatus returns a page of the return requests in a status,
+	// oldest first, and how many are in it in all. An empty status selects
+	// all of them.
+	FindByStatus(ctx context.Context, status string, page Page) ([]ReturnRequest, int64, error)
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// ShippingBasis constants are what a shipping method's rates are measured in.
+const (
+	// ShippingBasisWeight rates by the weight of the line in grams.
+	ShippingBasisWeight = "weight"
+	// ShippingBasisQuantity rates by the number of copies in the line.
+	ShippingBasisQuantity = "quantity"
+)
+
+// ShippingLine is an order line to be shipped.
+type ShippingLine struct {
+	// Method is the code of the shipping method the customer chose.
+	Method   string
+	Quantity int
+	// Weight is the weight of all copies in the line, in grams.
+	Weight int
+}
+
+// ShippingRate is one step of a rate table: lines measuring From or more
+// cost Cost to ship, up to the next step.
+type ShippingRate struct {
+	From int
+	Cost float64
+}
+
+// ShippingMethod is a way orders can be shipped, e.g. standard or express,
+// priced by a rate table measured in weight or quantity.
+type ShippingMethod struct {
+	Code  string
+	Basis string
+	// Rates are in ascending order of From.
+	Rates []ShippingRate
+}
+
+// NormalizeShippingMethod trims and lower-cases a shipping method code, so
+// codes are not case sensitive.
+func NormalizeShippingMethod(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// Cost works out what shipping a line costs: the rate of the highest step
+// the line reaches. Lines below the first step cannot be shipped this way.
+func (m ShippingMethod) Cost(line ShippingLine) (float64, error) {
+	measure := line.Quantity
+	if m.Basis == ShippingBasisWeight {
+		measure = line.Weight
+	}
+
+	cost, found := 0.0, false
+	for _, rate := range m.Rates {
+		if rate.From > measure {
+			break
+		}
+		cost, found = rate.Cost, true
+	}
+
+	if !found {
+		return 0, ErrShippingUnavailable
+	}
+	return roundMoney(cost), nil
+}
+
+// ShippingCalculator is the port (interface) for pricing shipping.
+type ShippingCalculator interface {
+	// Methods returns the shipping methods customers can choose from.
+	Methods() []ShippingMethod
+	// Quote works out what shipping a line costs with its method.
+	Quote(ctx context.Context, line ShippingLine) (float64, error)
+}
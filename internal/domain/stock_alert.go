@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// LowStockAlert reports a book whose available stock fell below its reorder threshold.
+type LowStockAlert struct {
+	BookID           uint
+	Title            string
+	Available        int
+	ReorderThreshold int
+	ReorderQuantity  int
+	// OrderID is the order that took the stock below the threshold.
+	OrderID   uint
+	CreatedAt time.Time
+}
+
+// NewLowStockAlert creates a new LowStockAlert for a book.
+func NewLowStockAlert(book Book, orderID uint) LowStockAlert {
+	return LowStockAlert{
+		BookID:           book.ID,
+		Title:            book.Title,
+		Available:        book.Available(),
+		ReorderThreshold: book.ReorderThreshold,
+		ReorderQuantity:  book.ReorderQuantity,
+		OrderID:          orderID,
+		CreatedAt:        time.Now(),
+	}
+}
+
+// StockNotifier is the port (interface) for telling purchasing a book is running out.
+// Implementations live in adapter/notify.
+type StockNotifier interface {
+	NotifyLowStock(ctx context.Context, alert LowStockAlert) error
+}
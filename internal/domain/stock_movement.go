@@ -0,0 +1,84 @@
+package domain
+
+import "time"
+
+// StockMovement is one append-only entry in a book's inventory ledger.
+// A book's stock always equals the sum of its movement deltas.
+type StockMovement struct {
+	ID     uint
+	BookID uint
+	// Delta is the change in stock, negative when stock leaves.
+	Delta int
+	// Balance is the book's stock right after this movement.
+	Balance int
+	Reason  string
+	// Actor identifies who caused the movement, e.g. "user:1".
+	Actor string
+	// Reference points at the record behind the movement, e.g. "order:7".
+	Reference string
+	CreatedAt time.Time
+}
+
+// StockMovement reason constants.
+const (
+	StockReasonOrder      = "order"
+	StockReasonCancel     = "cancel"
+	StockReasonRestock    = "restock"
+	StockReasonAdjustment = "adjustment"
+	StockReasonReturn     = "return"
+	StockReasonDamaged    = "damaged"
+	StockReasonLost       = "lost"
+	// StockReasonReconcile marks a stock reset to the ledger. It has no
+	// delta: the ledger was right, the stock was not.
+	StockReasonReconcile = "reconcile"
+)
+
+// NewStockMovement describes why stock is about to change.
+// The repository fills in the book, delta and balance when it applies it.
+func NewStockMovement(reason, actor, reference string) StockMovement {
+	return StockMovement{
+		Reason:    reason,
+		Actor:     actor,
+		Reference: reference,
+	}
+}
+
+// NewInitialStock describes the stock a book is created with.
+func NewInitialStock(actor string) StockMovement {
+	return NewStockMovement(StockReasonRestock, actor, "initial stock")
+}
+
+// ValidateAdjustment checks that a manual stock adjustment uses a reason staff
+// may pick and moves stock in the direction the reason implies. Order and
+// cancel movements are only recorded by the order flow.
+func ValidateAdjustment(reason string, delta int) error {
+	if delta == 0 {
+		return ErrInvalidAdjustment
+	}
+
+	switch reason {
+	case StockReasonRestock, StockReasonReturn:
+		if delta < 0 {
+			return ErrInvalidAdjustment
+		}
+	case StockReasonDamaged, StockReasonLost:
+		if delta > 0 {
+			return ErrInvalidAdjustment
+		}
+	case StockReasonAdjustment:
+	default:
+		return ErrInvalidAdjustment
+	}
+
+	return nil
+}
+
+// ValidateStockCondition checks that returned copies are in a known condition.
+func ValidateStockCondition(condition string) error {
+	switch condition {
+	case StockConditionSellable, StockConditionDamaged:
+		return nil
+	default:
+		return ErrInvalidStockCondition
+	}
+}
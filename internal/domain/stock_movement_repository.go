@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// StockMovementRepository is the port (interface) for reading the inventory ledger.
+// Movements are written by BookRepository together with the stock they change.
+type StockMovementRepository interface {
+	// FindByBookID returns a book's movements, oldest first.
+	FindByBookID(ctx context.Context, bookID uint) ([]StockMovement, error)
+	// Balances returns the sum of movement deltas per book.
+	Balances(ctx context.Context) (map[uint]int, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "context"
+
+// TaxLine is an order line to be taxed.
+type TaxLine struct {
+	// Region is where the order is taxed, e.g. ID or US-CA.
+	Region   string
+	Category string
+	// Amount is what the line costs after discounts, at shelf prices.
+	Amount float64
+}
+
+// TaxBreakdown splits a line into its price before tax and the tax on it.
+// Total is always Subtotal plus Tax.
+type TaxBreakdown struct {
+	Subtotal float64
+	Tax      float64
+	Total    float64
+}
+
+// TaxRule is the tax rate of a region and book category. Empty Region or
+// Category match any. Inclusive rules are for regions whose shelf prices
+// already include tax; exclusive rules add tax on top of them.
+type TaxRule struct {
+	Region    string
+	Category  string
+	Rate      float64
+	Inclusive bool
+}
+
+// Apply works out the tax on a line. Tax is rounded to the cent once per
+// line, never per copy, so a line always comes to the same amounts.
+func (r TaxRule) Apply(amount float64) TaxBreakdown {
+	if r.Inclusive {
+		total := roundMoney(amount)
+		tax := roundMoney(total * r.Rate / (100 + r.Rate))
+		return TaxBreakdown{Subtotal: roundMoney(total - tax), Tax: tax, Total: total}
+	}
+
+	subtotal := roundMoney(amount)
+	tax := roundMoney(subtotal * r.Rate / 100)
+	return TaxBreakdown{Subtotal: subtotal, Tax: tax, Total: roundMoney(subtotal + tax)}
+}
+
+// TaxCalculator is the port (interface) for taxing order lines.
+// Implementations live in adapter/tax.
+type TaxCalculator interface {
+	// Calculate taxes a line, or returns ErrTaxRegionUnsupported when no
+	// rule covers its region.
+	Calculate(ctx context.Context, line TaxLine) (TaxBreakdown, error)
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // User represents the user entity in domain layer.
 type User struct {
 	ID       uint
@@ -7,10 +9,20 @@ type User struct {
 	Email    string
 	Password string
 	Role     string
+	Verified bool
+	// VerificationSentAt is when the last verification email was sent.
+	VerificationSentAt *time.Time
+	// DeletedAt is set when the user is soft deleted.
+	DeletedAt *time.Time
 }
 
 // NewUser creates a new User entity.
+// An empty role defaults to customer.
 func NewUser(name, email, password, role string) User {
+	if role == "" {
+		role = "customer"
+	}
+
 	return User{
 		Name:     name,
 		Email:    email,
@@ -28,3 +40,8 @@ func (u User) IsAdmin() bool {
 func (u User) IsCustomer() bool {
 	return u.Role == "customer"
 }
+
+// MarkVerified marks the user's email as verified.
+func (u *User) MarkVerified() {
+	u.Verified = true
+}
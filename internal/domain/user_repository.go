@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // UserRepository is the port (interface) for user persistence.
 type UserRepository interface {
@@ -9,6 +12,12 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindAll(ctx context.Context) ([]User, error)
 	Update(ctx context.Context, user User) (User, error)
+	// Delete soft deletes a user; it is hidden from all finders.
 	Delete(ctx context.Context, id uint) error
+	FindDeleted(ctx context.Context) ([]User, error)
+	Restore(ctx context.Context, id uint) error
+	// Purge permanently removes users soft deleted before the given time.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+	// ExistsByEmail includes soft deleted users, since their email stays reserved.
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 }
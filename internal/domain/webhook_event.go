@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// WebhookEvent is a webhook received from the payment provider, stored
+// as it was sent so it can be deduplicated and replayed.
+type WebhookEvent struct {
+	ID uint
+	// EventID is the provider's identifier of the event.
+	EventID string
+	Type    string
+	Payload []byte
+	// Error is why the last attempt to process the event failed.
+	Error string
+	// ClaimedAt is when a delivery started processing the event. Failed
+	// attempts clear it so the next delivery can claim the event.
+	ClaimedAt   *time.Time
+	ProcessedAt *time.Time
+	ReceivedAt  time.Time
+}
+
+// NewWebhookEvent creates a new WebhookEvent entity for an event yet to be
+// processed, claimed by the delivery that stores it.
+func NewWebhookEvent(eventID, eventType string, payload []byte) WebhookEvent {
+	now := time.Now()
+	return WebhookEvent{
+		EventID:    eventID,
+		Type:       eventType,
+		Payload:    payload,
+		ClaimedAt:  &now,
+		ReceivedAt: now,
+	}
+}
+
+// IsProcessed checks if the event has been processed.
+func (e WebhookEvent) IsProcessed() bool {
+	return e.ProcessedAt != nil
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// WebhookEventRepository is the port (interface) for webhook event persistence.
+type WebhookEventRepository interface {
+	// Create stores a new event. It must fail with ErrWebhookEventExists if
+	// an event with the same EventID is already stored.
+	Create(ctx context.Context, event WebhookEvent) (WebhookEvent, error)
+	FindByEventID(ctx context.Context, eventID string) (WebhookEvent, error)
+	// FindAll returns all events, oldest first.
+	FindAll(ctx context.Context) ([]WebhookEvent, error)
+	// Claim marks an unprocessed event as being processed, so one delivery
+	// at a time applies it. It fails with ErrWebhookEventInProgress if the
+	// event was processed, or claimed at or after staleBefore.
+	Claim(ctx context.Context, id uint, claimedAt, staleBefore time.Time) error
+	MarkProcessed(ctx context.Context, id uint, processedAt time.Time) error
+	// MarkFailed records why processing failed and releases the claim.
+	MarkFailed(ctx context.Context, id uint, reason string) error
+}
@@ -40,6 +40,207 @@ func WriteErrorFromDomain(w http.ResponseWriter, err error) {
 	case errors.Is(err, domain.ErrUnauthorized):
 		WriteError(w, http.StatusUnauthorized, "unauthorized access")
 
+	case errors.Is(err, domain.ErrInvalidResetToken):
+		WriteError(w, http.StatusBadRequest, "invalid or expired reset token")
+
+	case errors.Is(err, domain.ErrInvalidPassword):
+		WriteError(w, http.StatusBadRequest, "password must be at least 8 characters")
+
+	case errors.Is(err, domain.ErrInvalidVerifyToken):
+		WriteError(w, http.StatusBadRequest, "invalid or expired verification token")
+
+	case errors.Is(err, domain.ErrEmailNotVerified):
+		WriteError(w, http.StatusForbidden, "email not verified")
+
+	case errors.Is(err, domain.ErrVerifyThrottled):
+		WriteError(w, http.StatusTooManyRequests, "verification email recently requested, try again later")
+
+	case errors.Is(err, domain.ErrBookHasOpenOrders):
+		WriteError(w, http.StatusConflict, "book has orders that are not finished")
+
+	case errors.Is(err, domain.ErrUserHasOpenOrders):
+		WriteError(w, http.StatusConflict, "user has orders that are not finished")
+
+	case errors.Is(err, domain.ErrBookConflict):
+		WriteError(w, http.StatusConflict, "book was modified by another request")
+
+	case errors.Is(err, domain.ErrPrecondition):
+		WriteError(w, http.StatusPreconditionFailed, "resource version does not match")
+
+	case errors.Is(err, domain.ErrInvalidAdjustment):
+		WriteError(w, http.StatusBadRequest, "delta must be non-zero and match the reason")
+
+	case errors.Is(err, domain.ErrEmptyShipment):
+		WriteError(w, http.StatusBadRequest, "shipment has no items")
+
+	case errors.Is(err, domain.ErrInvalidReorderLevel):
+		WriteError(w, http.StatusBadRequest, "reorder threshold and quantity cannot be negative")
+
+	case errors.Is(err, domain.ErrInvalidReleaseDate):
+		WriteError(w, http.StatusBadRequest, "pre-orders need a release date")
+
+	case errors.Is(err, domain.ErrOrderNotPending):
+		WriteError(w, http.StatusConflict, "order is not pending")
+
+	case errors.Is(err, domain.ErrOrderStatusChanged):
+		WriteError(w, http.StatusConflict, "order status was changed by another request")
+
+	case errors.Is(err, domain.ErrPaymentChanged):
+		WriteError(w, http.StatusConflict, "payment was changed by another request")
+
+	case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+		WriteError(w, http.StatusBadRequest, "Idempotency-Key must be 1 to 255 characters")
+
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		WriteError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+
+	case errors.Is(err, domain.ErrIdempotencyKeyInProgress):
+		WriteError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+
+	case errors.Is(err, domain.ErrPaymentNotFound):
+		WriteError(w, http.StatusNotFound, "payment not found")
+
+	case errors.Is(err, domain.ErrInvalidPaymentToken):
+		WriteError(w, http.StatusBadRequest, "payment token is required")
+
+	case errors.Is(err, domain.ErrPaymentDeclined):
+		WriteError(w, http.StatusPaymentRequired, "payment was declined")
+
+	case errors.Is(err, domain.ErrPaymentTimeout):
+		WriteError(w, http.StatusGatewayTimeout, "payment provider did not respond")
+
+	case errors.Is(err, domain.ErrPaymentExists):
+		WriteError(w, http.StatusConflict, "order already has an active payment")
+
+	case errors.Is(err, domain.ErrPaymentNotAuthorized):
+		WriteError(w, http.StatusConflict, "order has no authorized payment")
+
+	case errors.Is(err, domain.ErrPaymentRequired):
+		WriteError(w, http.StatusPaymentRequired, "order has no captured payment")
+
+	case errors.Is(err, domain.ErrWebhookEventNotFound):
+		WriteError(w, http.StatusNotFound, "webhook event not found")
+
+	case errors.Is(err, domain.ErrInvalidWebhookSignature):
+		WriteError(w, http.StatusUnauthorized, "invalid webhook signature")
+
+	case errors.Is(err, domain.ErrInvalidWebhookEvent):
+		WriteError(w, http.StatusBadRequest, "webhook event needs an id and a type")
+
+	case errors.Is(err, domain.ErrWebhookEventInProgress):
+		WriteError(w, http.StatusConflict, "webhook event is being processed")
+
+	case errors.Is(err, domain.ErrReturnNotFound):
+		WriteError(w, http.StatusNotFound, "return request not found")
+
+	case errors.Is(err, domain.ErrOrderNotReturnable):
+		WriteError(w, http.StatusConflict, "only completed or delivered orders can be returned")
+
+	case errors.Is(err, domain.ErrReturnQuantityExceeded):
+		WriteError(w, http.StatusConflict, "return quantity exceeds the copies not yet returned")
+
+	case errors.Is(err, domain.ErrReturnNotRequested):
+		WriteError(w, http.StatusConflict, "return request was already resolved")
+
+	case errors.Is(err, domain.ErrInvalidStockCondition):
+		WriteError(w, http.StatusBadRequest, "condition must be sellable or damaged")
+
+	case errors.Is(err, domain.ErrInvalidRefundAmount):
+		WriteError(w, http.StatusBadRequest, "refund amount must be positive and within the unrefunded total")
+
+	case errors.Is(err, domain.ErrRefundDeclined):
+		WriteError(w, http.StatusBadGateway, "payment provider declined the refund")
+
+	case errors.Is(err, domain.ErrPromotionNotFound):
+		WriteError(w, http.StatusNotFound, "promotion not found")
+
+	case errors.Is(err, domain.ErrPromotionCodeExists):
+		WriteError(w, http.StatusConflict, "promotion code already exists")
+
+	case errors.Is(err, domain.ErrInvalidPromotionCode):
+		WriteError(w, http.StatusBadRequest, "promotion code must be 1 to 50 characters")
+
+	case errors.Is(err, domain.ErrInvalidPromotionType):
+		WriteError(w, http.StatusBadRequest, "promotion type must be percentage or fixed")
+
+	case errors.Is(err, domain.ErrInvalidPromotionValue):
+		WriteError(w, http.StatusBadRequest, "promotion value must be positive, and at most 100 for percentages")
+
+	case errors.Is(err, domain.ErrInvalidPromotionLimits):
+		WriteError(w, http.StatusBadRequest, "minimum spend and usage limits cannot be negative")
+
+	case errors.Is(err, domain.ErrInvalidPromotionWindow):
+		WriteError(w, http.StatusBadRequest, "promotion must end after it starts")
+
+	case errors.Is(err, domain.ErrPromotionNotActive):
+		WriteError(w, http.StatusUnprocessableEntity, "promotion is not active")
+
+	case errors.Is(err, domain.ErrPromotionNotApplicable):
+		WriteError(w, http.StatusUnprocessableEntity, "promotion does not apply to this book")
+
+	case errors.Is(err, domain.ErrPromotionMinimumSpend):
+		WriteError(w, http.StatusUnprocessableEntity, "order does not reach the promotion's minimum spend")
+
+	case errors.Is(err, domain.ErrPromotionUsedUp):
+		WriteError(w, http.StatusUnprocessableEntity, "promotion has reached its usage limit")
+
+	case errors.Is(err, domain.ErrPromotionUserLimit):
+		WriteError(w, http.StatusUnprocessableEntity, "promotion was already used the allowed number of times")
+
+	case errors.Is(err, domain.ErrInvalidPriceType):
+		WriteError(w, http.StatusBadRequest, "price type must be regular or sale")
+
+	case errors.Is(err, domain.ErrInvalidPriceSchedule):
+		WriteError(w, http.StatusBadRequest, "prices must start now or later, and only sales end, after they start")
+
+	case errors.Is(err, domain.ErrPriceOverlap):
+		WriteError(w, http.StatusConflict, "price overlaps an existing price of the same type")
+
+	case errors.Is(err, domain.ErrTaxRegionUnsupported):
+		WriteError(w, http.StatusUnprocessableEntity, "orders cannot be taxed in this region")
+
+	case errors.Is(err, domain.ErrAddressNotFound):
+		WriteError(w, http.StatusNotFound, "address not found")
+
+	case errors.Is(err, domain.ErrInvalidAddress):
+		WriteError(w, http.StatusBadRequest, "address needs a recipient name, street and city")
+
+	case errors.Is(err, domain.ErrInvalidCountry):
+		WriteError(w, http.StatusBadRequest, "country must be a two-letter ISO 3166 code")
+
+	case errors.Is(err, domain.ErrAddressRegionRequired):
+		WriteError(w, http.StatusBadRequest, "addresses in this country need a region")
+
+	case errors.Is(err, domain.ErrInvalidPostalCode):
+		WriteError(w, http.StatusBadRequest, "postal code is missing or invalid for this country")
+
+	case errors.Is(err, domain.ErrInvalidWeight):
+		WriteError(w, http.StatusBadRequest, "weight cannot be negative")
+
+	case errors.Is(err, domain.ErrInvalidShippingMethod):
+		WriteError(w, http.StatusBadRequest, "unknown shipping method")
+
+	case errors.Is(err, domain.ErrShippingAddressRequired):
+		WriteError(w, http.StatusBadRequest, "shipping needs a shipping address")
+
+	case errors.Is(err, domain.ErrShippingUnavailable):
+		WriteError(w, http.StatusUnprocessableEntity, "shipping method does not cover this order")
+
+	case errors.Is(err, domain.ErrOrderNotShippable):
+		WriteError(w, http.StatusConflict, "order has no shipping method")
+
+	case errors.Is(err, domain.ErrOrderNotFulfillable):
+		WriteError(w, http.StatusConflict, "order is not at the previous fulfilment step")
+
+	case errors.Is(err, domain.ErrOrderShipmentNotFound):
+		WriteError(w, http.StatusNotFound, "order shipment not found")
+
+	case errors.Is(err, domain.ErrInvalidShipment):
+		WriteError(w, http.StatusBadRequest, "shipping needs a carrier and a tracking number")
+
+	case errors.Is(err, domain.ErrOrderNotDelivered):
+		WriteError(w, http.StatusConflict, "shipped orders are completed once delivered")
+
 	default:
 		WriteError(w, http.StatusInternalServerError, "internal server error")
 	}
@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// ETag formats a resource version as a strong entity tag.
+func ETag(version uint) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// IfMatchVersion reads the version from the If-Match header.
+// It returns 0 when the header is absent or "*", meaning no precondition.
+func IfMatchVersion(r *http.Request) (uint, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	// Weak tags are accepted since versions are compared exactly anyway
+	tag := strings.TrimPrefix(header, "W/")
+	tag = strings.Trim(tag, `"`)
+
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || version == 0 {
+		return 0, domain.ErrPrecondition
+	}
+
+	return uint(version), nil
+}
@@ -0,0 +1,142 @@
+package helper
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+const (
+	// DefaultPerPage is the page size used when per_page is not given.
+	DefaultPerPage = 20
+	// MaxPerPage is the largest page size a client can request.
+	MaxPerPage = 100
+	// MaxPage is the last page a client can request, so the offset of a
+	// page always fits in an int.
+	MaxPage = 1000000
+)
+
+// ListResponse is the standard API response structure for collections.
+// Data is always a JSON array, never null.
+type ListResponse struct {
+	Code   int         `json:"code"`
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+	Meta   ListMeta    `json:"meta"`
+	Links  ListLinks   `json:"links"`
+}
+
+// ListMeta describes the page returned in a ListResponse.
+type ListMeta struct {
+	Count      int `json:"count"`
+	Total      int `json:"total"`
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+}
+
+// ListLinks holds relative links to neighbouring pages.
+type ListLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// ReadPage reads the page selected by the page and per_page query
+// parameters. It writes a 400 and returns false when they are invalid.
+func ReadPage(w http.ResponseWriter, r *http.Request) (domain.Page, bool) {
+	query := r.URL.Query()
+
+	page, ok := queryInt(query, "page", 1)
+	if !ok || page < 1 || page > MaxPage {
+		WriteError(w, http.StatusBadRequest, "page must be between 1 and "+strconv.Itoa(MaxPage))
+		return domain.Page{}, false
+	}
+
+	perPage, ok := queryInt(query, "per_page", DefaultPerPage)
+	if !ok || perPage < 1 || perPage > MaxPerPage {
+		WriteError(w, http.StatusBadRequest, "per_page must be between 1 and "+strconv.Itoa(MaxPerPage))
+		return domain.Page{}, false
+	}
+
+	return domain.Page{Limit: perPage, Offset: (page - 1) * perPage}, true
+}
+
+// WriteList writes the page of items selected by the page and per_page
+// query parameters in the list envelope. It is meant for short lists, such
+// as an order's payments; long ones are paged by their repository and
+// written with WritePage.
+func WriteList[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	page, ok := ReadPage(w, r)
+	if !ok {
+		return
+	}
+
+	start := min(page.Offset, len(items))
+	end := min(start+page.Limit, len(items))
+	WritePage(w, r, items[start:end], page, int64(len(items)))
+}
+
+// WritePage writes items, the given page of a list of total items, in the
+// list envelope.
+func WritePage[T any](w http.ResponseWriter, r *http.Request, items []T, page domain.Page, total int64) {
+	query := r.URL.Query()
+	perPage := page.Limit
+	number := page.Offset/perPage + 1
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+
+	data := items
+	if data == nil {
+		data = []T{}
+	}
+
+	link := func(page int) string {
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(perPage))
+		return r.URL.Path + "?" + query.Encode()
+	}
+
+	links := ListLinks{
+		Self:  link(number),
+		First: link(1),
+		Last:  link(max(totalPages, 1)),
+	}
+	if number > 1 {
+		links.Prev = link(min(number-1, max(totalPages, 1)))
+	}
+	if number < totalPages {
+		links.Next = link(number + 1)
+	}
+
+	WriteJSON(w, http.StatusOK, ListResponse{
+		Code:   http.StatusOK,
+		Status: "success",
+		Data:   data,
+		Meta: ListMeta{
+			Count:      len(data),
+			Total:      int(total),
+			Page:       number,
+			PerPage:    perPage,
+			TotalPages: totalPages,
+		},
+		Links: links,
+	})
+}
+
+// queryInt parses an integer query parameter, returning fallback when absent.
+func queryInt(query url.Values, key string, fallback int) (int, bool) {
+	value := query.Get(key)
+	if value == "" {
+		return fallback, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -40,3 +40,11 @@ func WriteError(w http.ResponseWriter, statusCode int, message string) {
 func ReadJSON(r *http.Request, target interface{}) error {
 	return json.NewDecoder(r.Body).Decode(target)
 }
+
+// ReadJSONStrict reads JSON from request body into target, rejecting fields
+// target does not have.
+func ReadJSONStrict(r *http.Request, target interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
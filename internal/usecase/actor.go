@@ -0,0 +1,27 @@
+package usecase
+
+import "context"
+
+// actorKey is the context key of the actor making changes.
+type actorKey struct{}
+
+// systemActor is the actor of changes made by background jobs.
+const systemActor = "system"
+
+// WithUser returns a context whose changes are made by the given user. The
+// stock ledger records them as the actor of the movements they cause.
+func WithUser(ctx context.Context, userID uint) context.Context {
+	return withActor(ctx, userActor(userID))
+}
+
+// withActor returns a context whose changes are made by actor.
+func withActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFrom returns who makes the changes done with ctx, or an empty
+// string if nobody signed in.
+func actorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
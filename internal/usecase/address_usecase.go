@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// AddressUsecase handles users' address books. Orders copy addresses from
+// the book when they are placed, see OrderUsecase.
+type AddressUsecase struct {
+	addressRepo domain.AddressRepository
+	userRepo    domain.UserRepository
+}
+
+// NewAddressUsecase creates a new AddressUsecase.
+func NewAddressUsecase(addressRepo domain.AddressRepository, userRepo domain.UserRepository) *AddressUsecase {
+	return &AddressUsecase{
+		addressRepo: addressRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// AddressFields are the parts of an address. Country is an ISO 3166-1
+// alpha-2 code; which other fields are required depends on it.
+type AddressFields struct {
+	Name       string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+	Phone      string
+}
+
+// CreateAddressInput is the input for adding an address to a user's address book.
+type CreateAddressInput struct {
+	UserID uint
+	Label  string
+	AddressFields
+}
+
+// UpdateAddressInput is the input for updating an address.
+type UpdateAddressInput struct {
+	ID    uint
+	Label string
+	AddressFields
+}
+
+// AddressOutput is the output for address operations.
+type AddressOutput struct {
+	ID     uint
+	UserID uint
+	Label  string
+	AddressFields
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Create adds an address to a user's address book.
+func (u *AddressUsecase) Create(ctx context.Context, input CreateAddressInput) (AddressOutput, error) {
+	address := domain.NewAddress(input.UserID, input.Label, toAddressSnapshot(input.AddressFields))
+
+	// Business rule: addresses must be deliverable in their country
+	if err := address.Validate(); err != nil {
+		return AddressOutput{}, err
+	}
+
+	// Check if user exists
+	if _, err := u.userRepo.FindByID(ctx, input.UserID); err != nil {
+		return AddressOutput{}, err
+	}
+
+	saved, err := u.addressRepo.Save(ctx, address)
+	if err != nil {
+		return AddressOutput{}, err
+	}
+
+	return toAddressOutput(saved), nil
+}
+
+// FindByID finds an address by ID.
+func (u *AddressUsecase) FindByID(ctx context.Context, id uint) (AddressOutput, error) {
+	address, err := u.addressRepo.FindByID(ctx, id)
+	if err != nil {
+		return AddressOutput{}, err
+	}
+
+	return toAddressOutput(address), nil
+}
+
+// FindByUserID returns a user's address book, oldest first.
+func (u *AddressUsecase) FindByUserID(ctx context.Context, userID uint) ([]AddressOutput, error) {
+	// Check if user exists
+	if _, err := u.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	addresses, err := u.addressRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]AddressOutput, len(addresses))
+	for i, address := range addresses {
+		outputs[i] = toAddressOutput(address)
+	}
+
+	return outputs, nil
+}
+
+// Update updates an address. Orders placed with it keep their copy.
+func (u *AddressUsecase) Update(ctx context.Context, input UpdateAddressInput) (AddressOutput, error) {
+	// Check if address exists
+	address, err := u.addressRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return AddressOutput{}, err
+	}
+
+	updated := domain.NewAddress(address.UserID, input.Label, toAddressSnapshot(input.AddressFields))
+	updated.ID = address.ID
+	updated.CreatedAt = address.CreatedAt
+
+	// Business rule: addresses must be deliverable in their country
+	if err := updated.Validate(); err != nil {
+		return AddressOutput{}, err
+	}
+
+	saved, err := u.addressRepo.Update(ctx, updated)
+	if err != nil {
+		return AddressOutput{}, err
+	}
+
+	return toAddressOutput(saved), nil
+}
+
+// Delete deletes an address. Orders placed with it keep their copy.
+func (u *AddressUsecase) Delete(ctx context.Context, id uint) error {
+	return u.addressRepo.Delete(ctx, id)
+}
+
+// toAddressSnapshot converts AddressFields to domain.AddressSnapshot.
+func toAddressSnapshot(fields AddressFields) domain.AddressSnapshot {
+	return domain.AddressSnapshot{
+		Name:       fields.Name,
+		Line1:      fields.Line1,
+		Line2:      fields.Line2,
+		City:       fields.City,
+		Region:     fields.Region,
+		PostalCode: fields.PostalCode,
+		Country:    fields.Country,
+		Phone:      fields.Phone,
+	}
+}
+
+// toAddressFields converts domain.AddressSnapshot to AddressFields.
+func toAddressFields(snapshot domain.AddressSnapshot) AddressFields {
+	return AddressFields{
+		Name:       snapshot.Name,
+		Line1:      snapshot.Line1,
+		Line2:      snapshot.Line2,
+		City:       snapshot.City,
+		Region:     snapshot.Region,
+		PostalCode: snapshot.PostalCode,
+		Country:    snapshot.Country,
+		Phone:      snapshot.Phone,
+	}
+}
+
+// toAddressOutput converts domain.Address to AddressOutput.
+func toAddressOutput(address domain.Address) AddressOutput {
+	return AddressOutput{
+		ID:            address.ID,
+		UserID:        address.UserID,
+		Label:         address.Label,
+		AddressFields: toAddressFields(address.AddressSnapshot),
+		CreatedAt:     address.CreatedAt,
+		UpdatedAt:     address.UpdatedAt,
+	}
+}
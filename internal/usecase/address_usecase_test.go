@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestAddressUsecaseValidatesByCountry(t *testing.T) {
+	ctx := context.Background()
+	valid := usecase.AddressFields{Name: "Budi", Line1: "Jl. Sudirman 1", City: "Jakarta", Region: "JK", PostalCode: "10210", Country: "ID"}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*usecase.AddressFields)
+		want   error
+	}{
+		{"valid", func(a *usecase.AddressFields) {}, nil},
+		{"lower case country", func(a *usecase.AddressFields) { a.Country = " id " }, nil},
+		{"unknown country code", func(a *usecase.AddressFields) { a.Country = "IDN" }, domain.ErrInvalidCountry},
+		{"missing street", func(a *usecase.AddressFields) { a.Line1 = " " }, domain.ErrInvalidAddress},
+		{"missing region", func(a *usecase.AddressFields) { a.Region = "" }, domain.ErrAddressRegionRequired},
+		{"bad postal code", func(a *usecase.AddressFields) { a.PostalCode = "1021" }, domain.ErrInvalidPostalCode},
+		{"lower case UK postcode", func(a *usecase.AddressFields) { a.Country, a.Region, a.PostalCode = "GB", "", "sw1a 1aa" }, nil},
+		{"Canadian postal code", func(a *usecase.AddressFields) { a.Country, a.Region, a.PostalCode = "CA", "ON", "12345" }, domain.ErrInvalidPostalCode},
+		{"no rules of its own", func(a *usecase.AddressFields) { a.Country, a.Region, a.PostalCode = "NZ", "", "" }, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture()
+			user := f.user(t, "a@example.com", true)
+			fields := valid
+			tc.modify(&fields)
+
+			_, err := f.addressUsecase().Create(ctx, usecase.CreateAddressInput{UserID: user.ID, AddressFields: fields})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestOrderUsecaseSnapshotsAddresses(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	addresses := f.addressUsecase()
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+	other := f.user(t, "b@example.com", true)
+
+	home, err := addresses.Create(ctx, usecase.CreateAddressInput{UserID: user.ID, Label: "home", AddressFields: usecase.AddressFields{
+		Name: "Budi", Line1: "Jl. Sudirman 1", City: "Jakarta", Region: "JK", PostalCode: "10210", Country: "ID",
+	}})
+	if err != nil {
+		t.Fatalf("Create address: %v", err)
+	}
+	theirs, _ := addresses.Create(ctx, usecase.CreateAddressInput{UserID: other.ID, AddressFields: usecase.AddressFields{
+		Name: "Ani", Line1: "Jl. Thamrin 2", City: "Jakarta", Region: "JK", PostalCode: "10230", Country: "ID",
+	}})
+
+	// Billing defaults to the shipping address
+	order, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1, ShippingAddressID: &home.ID})
+	if err != nil {
+		t.Fatalf("Create order: %v", err)
+	}
+	if order.ShippingAddress == nil || order.BillingAddress == nil || *order.BillingAddress != home.AddressFields {
+		t.Fatalf("expected the home address for shipping and billing, got %+v", order)
+	}
+
+	edited := home.AddressFields
+	edited.Line1 = "Jl. Gatot Subroto 3"
+	if _, err := addresses.Update(ctx, usecase.UpdateAddressInput{ID: home.ID, Label: "home", AddressFields: edited}); err != nil {
+		t.Fatalf("Update address: %v", err)
+	}
+	found, _ := orders.FindByID(ctx, order.ID)
+	if found.ShippingAddress == nil || found.ShippingAddress.Line1 != "Jl. Sudirman 1" {
+		t.Fatalf("expected the order to keep the address as it was, got %+v", found.ShippingAddress)
+	}
+
+	// Other users' addresses cannot be used
+	_, err = orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1, ShippingAddressID: &home.ID, BillingAddressID: &theirs.ID})
+	if !errors.Is(err, domain.ErrAddressNotFound) {
+		t.Fatalf("expected ErrAddressNotFound, got %v", err)
+	}
+}
+
+func TestOrderUsecaseTaxesShippingRegion(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.taxes = newTaxes([]domain.TaxRule{{Region: "ID", Rate: 10}, {Region: "US-CA", Rate: 7.25}})
+	orders := f.orderUsecase(usecase.OrderConfig{TaxRegion: "ID"})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	address, err := f.addressUsecase().Create(ctx, usecase.CreateAddressInput{UserID: user.ID, AddressFields: usecase.AddressFields{
+		Name: "Budi", Line1: "1 Market St", City: "San Francisco", Region: "ca", PostalCode: "94105", Country: "US",
+	}})
+	if err != nil {
+		t.Fatalf("Create address: %v", err)
+	}
+
+	order, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1, ShippingAddressID: &address.ID})
+	if err != nil || order.Tax != 0.73 {
+		t.Fatalf("expected California tax, got %+v, %v", order, err)
+	}
+
+	// Orders without an address are taxed in the store's region
+	order, err = orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if err != nil || order.Tax != 1 {
+		t.Fatalf("expected tax in the store's region, got %+v, %v", order, err)
+	}
+}
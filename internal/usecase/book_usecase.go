@@ -2,43 +2,87 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/domain"
 )
 
 // BookUsecase handles all book business logic.
 type BookUsecase struct {
-	bookRepo domain.BookRepository
+	bookRepo  domain.BookRepository
+	orderRepo domain.OrderRepository
+	pricing   *PricingService
 }
 
 // NewBookUsecase creates a new BookUsecase.
-func NewBookUsecase(bookRepo domain.BookRepository) *BookUsecase {
+func NewBookUsecase(bookRepo domain.BookRepository, orderRepo domain.OrderRepository, pricing *PricingService) *BookUsecase {
 	return &BookUsecase{
-		bookRepo: bookRepo,
+		bookRepo:  bookRepo,
+		orderRepo: orderRepo,
+		pricing:   pricing,
 	}
 }
 
 // CreateBookInput is the input for creating a book.
 type CreateBookInput struct {
-	Title string
-	Price float64
-	Stock int
+	Title            string
+	Category         string
+	Weight           int
+	Price            float64
+	Stock            int
+	ReorderThreshold int
+	ReorderQuantity  int
+	AllowBackorder   bool
+	AllowPreorder    bool
+	ReleaseDate      *time.Time
 }
 
-// UpdateBookInput is the input for updating a book.
+// UpdateBookInput is the input for updating a book. Stock is changed
+// through InventoryUsecase instead. A new price takes effect immediately;
+// PricingService schedules changes ahead.
 type UpdateBookInput struct {
-	ID    uint
-	Title string
-	Price float64
-	Stock int
+	ID               uint
+	Title            string
+	Category         string
+	Weight           int
+	Price            float64
+	ReorderThreshold int
+	ReorderQuantity  int
+	AllowBackorder   bool
+	AllowPreorder    bool
+	ReleaseDate      *time.Time
+	// Version is the version the client last saw; zero skips the check.
+	Version uint
+}
+
+// DeleteBookInput is the input for deleting a book.
+type DeleteBookInput struct {
+	ID uint
+	// Version is the version the client last saw; zero skips the check.
+	Version uint
 }
 
 // BookOutput is the output for book operations.
+// Stock is on hand; Available excludes stock reserved for pending orders.
+// Price is what the book costs now, which is a sale price while a sale runs.
 type BookOutput struct {
-	ID    uint
-	Title string
-	Price float64
-	Stock int
+	ID               uint
+	Title            string
+	Category         string
+	Weight           int
+	Price            float64
+	RegularPrice     float64
+	SaleEndsAt       *time.Time
+	Stock            int
+	Reserved         int
+	Available        int
+	ReorderThreshold int
+	ReorderQuantity  int
+	AllowBackorder   bool
+	AllowPreorder    bool
+	ReleaseDate      *time.Time
+	Version          uint
+	DeletedAt        *time.Time
 }
 
 // Create creates a new book with validation.
@@ -53,14 +97,38 @@ func (u *BookUsecase) Create(ctx context.Context, input CreateBookInput) (BookOu
 		return BookOutput{}, domain.ErrInvalidStock
 	}
 
+	// Business rule: weight cannot be negative
+	if input.Weight < 0 {
+		return BookOutput{}, domain.ErrInvalidWeight
+	}
+
+	// Business rule: reorder levels cannot be negative
+	if input.ReorderThreshold < 0 || input.ReorderQuantity < 0 {
+		return BookOutput{}, domain.ErrInvalidReorderLevel
+	}
+
+	// Business rule: pre-orders are taken until a known release date
+	if input.AllowPreorder && input.ReleaseDate == nil {
+		return BookOutput{}, domain.ErrInvalidReleaseDate
+	}
+
 	book := domain.NewBook(input.Title, input.Price, input.Stock)
+	book.Category = input.Category
+	book.Weight = input.Weight
+	book.ReorderThreshold = input.ReorderThreshold
+	book.ReorderQuantity = input.ReorderQuantity
+	book.AllowBackorder = input.AllowBackorder
+	book.AllowPreorder = input.AllowPreorder
+	book.ReleaseDate = input.ReleaseDate
 
-	saved, err := u.bookRepo.Save(ctx, book)
+	saved, err := u.bookRepo.Save(ctx, book, domain.NewInitialStock(actorFrom(ctx)))
 	if err != nil {
 		return BookOutput{}, err
 	}
 
-	return toBookOutput(saved), nil
+	u.pricing.recordListPrice(ctx, saved)
+
+	return u.pricing.bookOutput(ctx, saved)
 }
 
 // FindByID finds a book by ID.
@@ -70,22 +138,30 @@ func (u *BookUsecase) FindByID(ctx context.Context, id uint) (BookOutput, error)
 		return BookOutput{}, err
 	}
 
-	return toBookOutput(book), nil
+	return u.pricing.bookOutput(ctx, book)
 }
 
-// FindAll returns all books.
-func (u *BookUsecase) FindAll(ctx context.Context) ([]BookOutput, error) {
-	books, err := u.bookRepo.FindAll(ctx)
+// FindAll returns a page of books and how many books there are in all.
+func (u *BookUsecase) FindAll(ctx context.Context, page domain.Page) ([]BookOutput, int64, error) {
+	books, total, err := u.bookRepo.FindAll(ctx, page)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	outputs := make([]BookOutput, len(books))
-	for i, book := range books {
-		outputs[i] = toBookOutput(book)
+	outputs, err := u.pricing.bookOutputs(ctx, books)
+	return outputs, total, err
+}
+
+// Search returns a page of the books whose title matches the query and how
+// many match in all.
+func (u *BookUsecase) Search(ctx context.Context, query string, page domain.Page) ([]BookOutput, int64, error) {
+	books, total, err := u.bookRepo.Search(ctx, query, page)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return outputs, nil
+	outputs, err := u.pricing.bookOutputs(ctx, books)
+	return outputs, total, err
 }
 
 // Update updates an existing book.
@@ -95,49 +171,129 @@ func (u *BookUsecase) Update(ctx context.Context, input UpdateBookInput) (BookOu
 		return BookOutput{}, domain.ErrInvalidPrice
 	}
 
-	// Business rule: stock cannot be negative
-	if input.Stock < 0 {
-		return BookOutput{}, domain.ErrInvalidStock
+	// Business rule: weight cannot be negative
+	if input.Weight < 0 {
+		return BookOutput{}, domain.ErrInvalidWeight
+	}
+
+	// Business rule: reorder levels cannot be negative
+	if input.ReorderThreshold < 0 || input.ReorderQuantity < 0 {
+		return BookOutput{}, domain.ErrInvalidReorderLevel
+	}
+
+	// Business rule: pre-orders are taken until a known release date
+	if input.AllowPreorder && input.ReleaseDate == nil {
+		return BookOutput{}, domain.ErrInvalidReleaseDate
 	}
 
 	// Check if book exists
-	_, err := u.bookRepo.FindByID(ctx, input.ID)
+	book, err := u.bookRepo.FindByID(ctx, input.ID)
 	if err != nil {
 		return BookOutput{}, err
 	}
 
-	book := domain.Book{
-		ID:    input.ID,
-		Title: input.Title,
-		Price: input.Price,
-		Stock: input.Stock,
+	// Business rule: client must be editing the current version
+	if !book.MatchesVersion(input.Version) {
+		return BookOutput{}, domain.ErrPrecondition
+	}
+
+	// A scheduled price may have replaced the list price since it was set,
+	// so compare with the regular price in effect
+	current, err := u.pricing.Quote(ctx, book, time.Now())
+	if err != nil {
+		return BookOutput{}, err
 	}
+	priceChanged := current.RegularPrice != input.Price
+
+	book.Title = input.Title
+	book.Category = input.Category
+	book.Weight = input.Weight
+	book.Price = input.Price
+	book.ReorderThreshold = input.ReorderThreshold
+	book.ReorderQuantity = input.ReorderQuantity
+	book.AllowBackorder = input.AllowBackorder
+	book.AllowPreorder = input.AllowPreorder
+	book.ReleaseDate = input.ReleaseDate
 
 	updated, err := u.bookRepo.Update(ctx, book)
 	if err != nil {
 		return BookOutput{}, err
 	}
 
-	return toBookOutput(updated), nil
+	if priceChanged {
+		u.pricing.recordListPrice(ctx, updated)
+	}
+
+	return u.pricing.bookOutput(ctx, updated)
 }
 
-// Delete deletes a book by ID.
-func (u *BookUsecase) Delete(ctx context.Context, id uint) error {
+// Delete soft deletes a book by ID.
+func (u *BookUsecase) Delete(ctx context.Context, input DeleteBookInput) error {
+	id := input.ID
+
 	// Check if book exists
-	_, err := u.bookRepo.FindByID(ctx, id)
+	book, err := u.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Business rule: client must be deleting the current version
+	if !book.MatchesVersion(input.Version) {
+		return domain.ErrPrecondition
+	}
+
+	// Business rule: a book with unfinished orders cannot be deleted, as
+	// returns may still restock it. Books with finished orders can; purging
+	// keeps them while orders reference them
+	open, err := u.orderRepo.CountOpenByBookID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if open > 0 {
+		return domain.ErrBookHasOpenOrders
+	}
 
 	return u.bookRepo.Delete(ctx, id)
 }
 
-// toBookOutput converts domain.Book to BookOutput.
-func toBookOutput(book domain.Book) BookOutput {
+// FindDeleted returns all soft deleted books.
+func (u *BookUsecase) FindDeleted(ctx context.Context) ([]BookOutput, error) {
+	books, err := u.bookRepo.FindDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.pricing.bookOutputs(ctx, books)
+}
+
+// Restore restores a soft deleted book.
+func (u *BookUsecase) Restore(ctx context.Context, id uint) (BookOutput, error) {
+	if err := u.bookRepo.Restore(ctx, id); err != nil {
+		return BookOutput{}, err
+	}
+
+	return u.FindByID(ctx, id)
+}
+
+// toBookOutput converts domain.Book and its price to BookOutput.
+func toBookOutput(book domain.Book, quote domain.PriceQuote) BookOutput {
 	return BookOutput{
-		ID:    book.ID,
-		Title: book.Title,
-		Price: book.Price,
-		Stock: book.Stock,
+		ID:               book.ID,
+		Title:            book.Title,
+		Category:         book.Category,
+		Weight:           book.Weight,
+		Price:            quote.Price,
+		RegularPrice:     quote.RegularPrice,
+		SaleEndsAt:       quote.SaleEndsAt,
+		Stock:            book.Stock,
+		Reserved:         book.Reserved,
+		Available:        book.Available(),
+		ReorderThreshold: book.ReorderThreshold,
+		ReorderQuantity:  book.ReorderQuantity,
+		AllowBackorder:   book.AllowBackorder,
+		AllowPreorder:    book.AllowPreorder,
+		ReleaseDate:      book.ReleaseDate,
+		Version:          book.Version,
+		DeletedAt:        book.DeletedAt,
 	}
 }
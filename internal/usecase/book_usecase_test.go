@@ -0,0 +1,162 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestBookUsecaseCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   usecase.CreateBookInput
+		wantErr error
+	}{
+		{name: "valid", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 5}},
+		{name: "zero stock", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 0}},
+		{name: "zero price", input: usecase.CreateBookInput{Title: "Go", Price: 0, Stock: 5}, wantErr: domain.ErrInvalidPrice},
+		{name: "negative price", input: usecase.CreateBookInput{Title: "Go", Price: -1, Stock: 5}, wantErr: domain.ErrInvalidPrice},
+		{name: "negative stock", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: -1}, wantErr: domain.ErrInvalidStock},
+		{name: "reorder levels", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 5, ReorderThreshold: 2, ReorderQuantity: 10}},
+		{name: "negative reorder threshold", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 5, ReorderThreshold: -1}, wantErr: domain.ErrInvalidReorderLevel},
+		{name: "pre-order without release date", input: usecase.CreateBookInput{Title: "Go", Price: 10, AllowPreorder: true}, wantErr: domain.ErrInvalidReleaseDate},
+		{name: "negative reorder quantity", input: usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 5, ReorderQuantity: -1}, wantErr: domain.ErrInvalidReorderLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			uc := f.bookUsecase()
+
+			output, err := uc.Create(context.Background(), tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (output.ID == 0 || output.Version != 1) {
+				t.Fatalf("unexpected output: %+v", output)
+			}
+		})
+	}
+}
+
+func TestBookUsecaseUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   func(book domain.Book) usecase.UpdateBookInput
+		wantErr error
+	}{
+		{
+			name: "without precondition",
+			input: func(book domain.Book) usecase.UpdateBookInput {
+				return usecase.UpdateBookInput{ID: book.ID, Title: "New", Price: 20}
+			},
+		},
+		{
+			name: "matching version",
+			input: func(book domain.Book) usecase.UpdateBookInput {
+				return usecase.UpdateBookInput{ID: book.ID, Title: "New", Price: 20, Version: book.Version}
+			},
+		},
+		{
+			name: "stale version",
+			input: func(book domain.Book) usecase.UpdateBookInput {
+				return usecase.UpdateBookInput{ID: book.ID, Title: "New", Price: 20, Version: book.Version + 1}
+			},
+			wantErr: domain.ErrPrecondition,
+		},
+		{
+			name: "invalid price",
+			input: func(book domain.Book) usecase.UpdateBookInput {
+				return usecase.UpdateBookInput{ID: book.ID, Title: "New", Price: 0}
+			},
+			wantErr: domain.ErrInvalidPrice,
+		},
+		{
+			name: "missing book",
+			input: func(book domain.Book) usecase.UpdateBookInput {
+				return usecase.UpdateBookInput{ID: 999, Title: "New", Price: 20}
+			},
+			wantErr: domain.ErrBookNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			uc := f.bookUsecase()
+			book := f.book(t, 10, 5)
+
+			output, err := uc.Update(context.Background(), tt.input(book))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (output.Title != "New" || output.Stock != book.Stock || output.Version <= book.Version) {
+				t.Fatalf("unexpected output: %+v", output)
+			}
+		})
+	}
+}
+
+func TestBookUsecaseDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unreferenced book is soft deleted and restorable", func(t *testing.T) {
+		f := newFixture()
+		uc := f.bookUsecase()
+		book := f.book(t, 10, 5)
+
+		if err := uc.Delete(ctx, usecase.DeleteBookInput{ID: book.ID}); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := uc.FindByID(ctx, book.ID); !errors.Is(err, domain.ErrBookNotFound) {
+			t.Fatalf("expected deleted book to be hidden, got %v", err)
+		}
+
+		restored, err := uc.Restore(ctx, book.ID)
+		if err != nil || restored.DeletedAt != nil {
+			t.Fatalf("Restore: %+v, %v", restored, err)
+		}
+	})
+
+	for _, status := range []string{domain.OrderStatusPending, domain.OrderStatusPaid, domain.OrderStatusDelivered} {
+		t.Run("book with "+status+" orders cannot be deleted", func(t *testing.T) {
+			f := newFixture()
+			uc := f.bookUsecase()
+			book := f.book(t, 10, 5)
+			user := f.user(t, "a@example.com", true)
+			order := domain.NewOrder(user.ID, book.ID, 1, 10)
+			order.Status = status
+			_, _ = f.orders.Save(ctx, order)
+
+			err := uc.Delete(ctx, usecase.DeleteBookInput{ID: book.ID})
+			if !errors.Is(err, domain.ErrBookHasOpenOrders) {
+				t.Fatalf("expected ErrBookHasOpenOrders, got %v", err)
+			}
+		})
+	}
+
+	t.Run("book that has sold is retired", func(t *testing.T) {
+		f := newFixture()
+		uc := f.bookUsecase()
+		book := f.book(t, 10, 5)
+		f.completedOrder(t, book, 1)
+
+		if err := uc.Delete(ctx, usecase.DeleteBookInput{ID: book.ID}); err != nil {
+			t.Fatalf("expected a book with completed orders to be soft deleted, got %v", err)
+		}
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		f := newFixture()
+		uc := f.bookUsecase()
+		book := f.book(t, 10, 5)
+
+		err := uc.Delete(ctx, usecase.DeleteBookInput{ID: book.ID, Version: book.Version + 1})
+		if !errors.Is(err, domain.ErrPrecondition) {
+			t.Fatalf("expected ErrPrecondition, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/adapter/memory"
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/adapter/shipping"
+	"kikukafandi/book-shop-api/internal/adapter/tax"
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// fixture wires in-memory repositories shared by all usecases in a test.
+type fixture struct {
+	books           *memory.BookRepositoryMemory
+	users           *memory.UserRepositoryMemory
+	orders          *memory.OrderRepositoryMemory
+	passwordResets  *memory.PasswordResetRepositoryMemory
+	stockMovements  *memory.StockMovementRepositoryMemory
+	reservations    *memory.ReservationRepositoryMemory
+	idempotencyKeys *memory.IdempotencyKeyRepositoryMemory
+	payments        *memory.PaymentRepositoryMemory
+	webhookEvents   *memory.WebhookEventRepositoryMemory
+	returnRequests  *memory.ReturnRequestRepositoryMemory
+	promotions      *memory.PromotionRepositoryMemory
+	bookPrices      *memory.BookPriceRepositoryMemory
+	addresses       *memory.AddressRepositoryMemory
+	orderShipments  *memory.OrderShipmentRepositoryMemory
+	taxes           domain.TaxCalculator
+	shipping        domain.ShippingCalculator
+	gateway         *payment.FakeGateway
+	mailer          *recordingMailer
+	notifier        *recordingNotifier
+}
+
+func newFixture() *fixture {
+	store := memory.NewStore()
+	return &fixture{
+		books:           memory.NewBookRepositoryMemory(store),
+		users:           memory.NewUserRepositoryMemory(store),
+		orders:          memory.NewOrderRepositoryMemory(store),
+		passwordResets:  memory.NewPasswordResetRepositoryMemory(store),
+		stockMovements:  memory.NewStockMovementRepositoryMemory(store),
+		reservations:    memory.NewReservationRepositoryMemory(store),
+		idempotencyKeys: memory.NewIdempotencyKeyRepositoryMemory(store),
+		payments:        memory.NewPaymentRepositoryMemory(store),
+		webhookEvents:   memory.NewWebhookEventRepositoryMemory(store),
+		returnRequests:  memory.NewReturnRequestRepositoryMemory(store),
+		promotions:      memory.NewPromotionRepositoryMemory(store),
+		bookPrices:      memory.NewBookPriceRepositoryMemory(store),
+		addresses:       memory.NewAddressRepositoryMemory(store),
+		orderShipments:  memory.NewOrderShipmentRepositoryMemory(store),
+		taxes:           newTaxes([]domain.TaxRule{{Rate: 0}}),
+		shipping:        newShipping(nil),
+		gateway:         payment.NewFakeGateway(),
+		mailer:          &recordingMailer{},
+		notifier:        &recordingNotifier{},
+	}
+}
+
+// newTaxes builds a rule table, panicking on invalid rules.
+func newTaxes(rules []domain.TaxRule) *tax.RuleTable {
+	table, err := tax.NewRuleTable(rules)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// newShipping builds a rate table, panicking on invalid methods.
+func newShipping(methods []domain.ShippingMethod) *shipping.RateTable {
+	table, err := shipping.NewRateTable(methods)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+func (f *fixture) pricingService() *usecase.PricingService {
+	return usecase.NewPricingService(f.bookPrices, f.books)
+}
+
+func (f *fixture) bookUsecase() *usecase.BookUsecase {
+	return usecase.NewBookUsecase(f.books, f.orders, f.pricingService())
+}
+
+func (f *fixture) orderUsecase(config usecase.OrderConfig) *usecase.OrderUsecase {
+	if config.ReservationTTL == 0 {
+		config.ReservationTTL = time.Hour
+	}
+	return usecase.NewOrderUsecase(f.orders, f.books, f.users, f.reservations, f.payments, f.promotions, f.addresses, f.pricingService(), f.taxes, f.shipping, f.gateway, f.notifier, f.mailer, config)
+}
+
+func (f *fixture) inventoryUsecase() *usecase.InventoryUsecase {
+	return usecase.NewInventoryUsecase(f.books, f.stockMovements, f.orderUsecase(usecase.OrderConfig{}), f.pricingService())
+}
+
+func (f *fixture) promotionUsecase() *usecase.PromotionUsecase {
+	return usecase.NewPromotionUsecase(f.promotions, f.books)
+}
+
+func (f *fixture) addressUsecase() *usecase.AddressUsecase {
+	return usecase.NewAddressUsecase(f.addresses, f.users)
+}
+
+func (f *fixture) fulfilmentUsecase() *usecase.FulfilmentUsecase {
+	return usecase.NewFulfilmentUsecase(f.orders, f.orderShipments, f.shipping)
+}
+
+func (f *fixture) returnUsecase() *usecase.ReturnUsecase {
+	return usecase.NewReturnUsecase(f.returnRequests, f.orders, f.books, f.payments, f.gateway, f.orderUsecase(usecase.OrderConfig{}))
+}
+
+// pay authorizes and captures the payment of a pending order, so it can be completed.
+func (f *fixture) pay(t *testing.T, uc *usecase.OrderUsecase, orderID uint) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: orderID, PaymentToken: "tok_visa"}); err != nil {
+		t.Fatalf("pay order %d: %v", orderID, err)
+	}
+	if _, err := uc.CapturePayment(ctx, orderID); err != nil {
+		t.Fatalf("capture payment of order %d: %v", orderID, err)
+	}
+}
+
+// completedOrder places, pays and completes an order for quantity copies of book.
+func (f *fixture) completedOrder(t *testing.T, book domain.Book, quantity int) usecase.OrderOutput {
+	t.Helper()
+	ctx := context.Background()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	user := f.user(t, "a@example.com", true)
+
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: quantity})
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	f.pay(t, uc, order.ID)
+	completed, err := uc.Complete(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("complete order: %v", err)
+	}
+	return completed
+}
+
+func (f *fixture) book(t *testing.T, price float64, stock int) domain.Book {
+	t.Helper()
+	book, err := f.books.Save(context.Background(), domain.NewBook("Test Book", price, stock), domain.NewInitialStock(""))
+	if err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+	return book
+}
+
+func (f *fixture) user(t *testing.T, email string, verified bool) domain.User {
+	t.Helper()
+	user := domain.NewUser("Test", email, "secret123", "customer")
+	user.Verified = verified
+	saved, err := f.users.Save(context.Background(), user)
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+	return saved
+}
+
+// recordingMailer is a domain.Mailer that keeps sent mails for assertions.
+type recordingMailer struct {
+	mu    sync.Mutex
+	mails []domain.Mail
+}
+
+func (m *recordingMailer) Send(_ context.Context, mail domain.Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+func (m *recordingMailer) sent() []domain.Mail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Mail(nil), m.mails...)
+}
+
+// recordingNotifier is a domain.StockNotifier that keeps alerts for assertions.
+type recordingNotifier struct {
+	mu     sync.Mutex
+	alerts []domain.LowStockAlert
+}
+
+func (n *recordingNotifier) NotifyLowStock(_ context.Context, alert domain.LowStockAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func (n *recordingNotifier) sent() []domain.LowStockAlert {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]domain.LowStockAlert(nil), n.alerts...)
+}
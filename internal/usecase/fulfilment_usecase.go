@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// FulfilmentUsecase handles getting paid orders to customers: orders placed
+// with a shipping method are packed, shipped and delivered, and customers
+// track them along the way.
+type FulfilmentUsecase struct {
+	orderRepo    domain.OrderRepository
+	shipmentRepo domain.OrderShipmentRepository
+	shipping     domain.ShippingCalculator
+}
+
+// NewFulfilmentUsecase creates a new FulfilmentUsecase.
+func NewFulfilmentUsecase(
+	orderRepo domain.OrderRepository,
+	shipmentRepo domain.OrderShipmentRepository,
+	shipping domain.ShippingCalculator,
+) *FulfilmentUsecase {
+	return &FulfilmentUsecase{
+		orderRepo:    orderRepo,
+		shipmentRepo: shipmentRepo,
+		shipping:     shipping,
+	}
+}
+
+// ShipOrderInput is the input for handing a packed order to a carrier.
+type ShipOrderInput struct {
+	OrderID        uint
+	Carrier        string
+	TrackingNumber string
+}
+
+// ShippingMethodOutput is the output for a shipping method.
+type ShippingMethodOutput struct {
+	Code  string
+	Basis string
+	Rates []ShippingRateOutput
+}
+
+// ShippingRateOutput is one step of a shipping method's rate table.
+type ShippingRateOutput struct {
+	From int
+	Cost float64
+}
+
+// TrackingOutput is where an order is on its way to the customer. The
+// shipment fields are empty until the order is packed.
+type TrackingOutput struct {
+	OrderID         uint
+	Status          string
+	ShippingMethod  string
+	ShippingAddress *AddressFields
+	Carrier         string
+	TrackingNumber  string
+	PackedAt        *time.Time
+	ShippedAt       *time.Time
+	DeliveredAt     *time.Time
+}
+
+// Methods returns the shipping methods customers can choose from.
+func (u *FulfilmentUsecase) Methods() []ShippingMethodOutput {
+	methods := u.shipping.Methods()
+
+	outputs := make([]ShippingMethodOutput, len(methods))
+	for i, method := range methods {
+		outputs[i] = toShippingMethodOutput(method)
+	}
+
+	return outputs
+}
+
+// Pack marks a paid order as packed and opens its shipment.
+func (u *FulfilmentUsecase) Pack(ctx context.Context, orderID uint) (TrackingOutput, error) {
+	order, err := u.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return TrackingOutput{}, err
+	}
+
+	// Business rule: orders are packed once paid, and only if they ship
+	if err := order.Fulfil(domain.OrderStatusPacked); err != nil {
+		return TrackingOutput{}, err
+	}
+
+	// Claim the order so a concurrent request cannot pack it twice
+	if err := u.orderRepo.UpdateStatus(ctx, order.ID, domain.OrderStatusPaid, order.Status); err != nil {
+		return TrackingOutput{}, err
+	}
+
+	shipment, err := u.shipmentRepo.Save(ctx, domain.NewOrderShipment(order.ID))
+	if err != nil {
+		u.revert(ctx, order.ID, order.Status, domain.OrderStatusPaid)
+		return TrackingOutput{}, err
+	}
+
+	return toTrackingOutput(order, &shipment), nil
+}
+
+// Ship marks a packed order as shipped with the carrier it was handed to.
+func (u *FulfilmentUsecase) Ship(ctx context.Context, input ShipOrderInput) (TrackingOutput, error) {
+	return u.advance(ctx, input.OrderID, domain.OrderStatusShipped, func(shipment *domain.OrderShipment) error {
+		return shipment.Ship(input.Carrier, input.TrackingNumber)
+	})
+}
+
+// Deliver marks a shipped order as delivered. Delivered orders can be returned.
+func (u *FulfilmentUsecase) Deliver(ctx context.Context, orderID uint) (TrackingOutput, error) {
+	return u.advance(ctx, orderID, domain.OrderStatusDelivered, func(shipment *domain.OrderShipment) error {
+		shipment.Deliver()
+		return nil
+	})
+}
+
+// advance moves a packed or shipped order to its next status, recording
+// the step on its shipment.
+func (u *FulfilmentUsecase) advance(ctx context.Context, orderID uint, status string, record func(*domain.OrderShipment) error) (TrackingOutput, error) {
+	order, err := u.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return TrackingOutput{}, err
+	}
+
+	from := order.Status
+	if err := order.Fulfil(status); err != nil {
+		return TrackingOutput{}, err
+	}
+
+	shipment, err := u.shipmentRepo.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return TrackingOutput{}, err
+	}
+	if err := record(&shipment); err != nil {
+		return TrackingOutput{}, err
+	}
+
+	// Claim the order so concurrent requests cannot record the step twice
+	if err := u.orderRepo.UpdateStatus(ctx, order.ID, from, order.Status); err != nil {
+		return TrackingOutput{}, err
+	}
+
+	updated, err := u.shipmentRepo.Update(ctx, shipment)
+	if err != nil {
+		u.revert(ctx, order.ID, order.Status, from)
+		return TrackingOutput{}, err
+	}
+
+	return toTrackingOutput(order, &updated), nil
+}
+
+// revert moves an order back to the status it had before a step that
+// failed. Failures are logged; the order is then ahead of its shipment.
+func (u *FulfilmentUsecase) revert(ctx context.Context, orderID uint, from, to string) {
+	if err := u.orderRepo.UpdateStatus(ctx, orderID, from, to); err != nil {
+		log.Printf("Failed to move order %d back to %s: %v", orderID, to, err)
+	}
+}
+
+// Tracking returns where a shipped order is on its way to the customer.
+func (u *FulfilmentUsecase) Tracking(ctx context.Context, orderID uint) (TrackingOutput, error) {
+	order, err := u.orderRepo.FindByID(ctx, orderID)
+	if err != nil {
+		return TrackingOutput{}, err
+	}
+
+	// Business rule: only orders placed with a shipping method are tracked
+	if !order.IsShippable() {
+		return TrackingOutput{}, domain.ErrOrderNotShippable
+	}
+
+	shipment, err := u.shipmentRepo.FindByOrderID(ctx, order.ID)
+	if errors.Is(err, domain.ErrOrderShipmentNotFound) {
+		return toTrackingOutput(order, nil), nil
+	}
+	if err != nil {
+		return TrackingOutput{}, err
+	}
+
+	return toTrackingOutput(order, &shipment), nil
+}
+
+// toShippingMethodOutput converts domain.ShippingMethod to ShippingMethodOutput.
+func toShippingMethodOutput(method domain.ShippingMethod) ShippingMethodOutput {
+	rates := make([]ShippingRateOutput, len(method.Rates))
+	for i, rate := range method.Rates {
+		rates[i] = ShippingRateOutput{From: rate.From, Cost: rate.Cost}
+	}
+
+	return ShippingMethodOutput{
+		Code:  method.Code,
+		Basis: method.Basis,
+		Rates: rates,
+	}
+}
+
+// toTrackingOutput converts an order and its shipment, if it has one, to TrackingOutput.
+func toTrackingOutput(order domain.Order, shipment *domain.OrderShipment) TrackingOutput {
+	output := TrackingOutput{
+		OrderID:         order.ID,
+		Status:          order.Status,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingAddress: toOrderAddressFields(order.ShippingAddress),
+	}
+	if shipment != nil {
+		output.Carrier = shipment.Carrier
+		output.TrackingNumber = shipment.TrackingNumber
+		output.PackedAt = &shipment.PackedAt
+		output.ShippedAt = shipment.ShippedAt
+		output.DeliveredAt = shipment.DeliveredAt
+	}
+
+	return output
+}
@@ -0,0 +1,202 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+// shippingMethods rate standard shipping by weight and express by copies.
+var shippingMethods = []domain.ShippingMethod{
+	{Code: "standard", Basis: domain.ShippingBasisWeight, Rates: []domain.ShippingRate{{From: 0, Cost: 5}, {From: 1000, Cost: 8}, {From: 5000, Cost: 15}}},
+	{Code: "express", Basis: domain.ShippingBasisQuantity, Rates: []domain.ShippingRate{{From: 2, Cost: 15}, {From: 4, Cost: 25}}},
+}
+
+// shippedOrder places an order for a book shipped with a method to a new
+// address of a new user.
+func (f *fixture) shippedOrder(t *testing.T, uc *usecase.OrderUsecase, book domain.Book, quantity int, method string) (usecase.OrderOutput, error) {
+	t.Helper()
+	ctx := context.Background()
+	user := f.user(t, "a@example.com", true)
+
+	address, err := f.addressUsecase().Create(ctx, usecase.CreateAddressInput{UserID: user.ID, AddressFields: usecase.AddressFields{
+		Name: "Budi", Line1: "Jl. Sudirman 1", City: "Jakarta", Region: "JK", PostalCode: "10210", Country: "ID",
+	}})
+	if err != nil {
+		t.Fatalf("create address: %v", err)
+	}
+
+	return uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: quantity, ShippingAddressID: &address.ID, ShippingMethod: method})
+}
+
+func TestOrderUsecaseChargesShipping(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		weight   int
+		quantity int
+		want     float64
+		err      error
+	}{
+		{"first weight step", "standard", 400, 2, 5, nil},
+		{"weight step reached exactly", "standard", 500, 2, 8, nil},
+		{"last weight step", "standard", 2000, 3, 15, nil},
+		{"books without a weight", "standard", 0, 3, 5, nil},
+		{"quantity step", "express", 400, 3, 15, nil},
+		{"method code case", "EXPRESS", 400, 4, 25, nil},
+		{"below the first step", "express", 400, 1, 0, domain.ErrShippingUnavailable},
+		{"unknown method", "pigeon", 400, 1, 0, domain.ErrInvalidShippingMethod},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture()
+			f.shipping = newShipping(shippingMethods)
+			book := domain.NewBook("Go", 10, 5)
+			book.Weight = tc.weight
+			book, _ = f.books.Save(ctx, book, domain.NewInitialStock(""))
+
+			order, err := f.shippedOrder(t, f.orderUsecase(usecase.OrderConfig{TaxRegion: "ID"}), book, tc.quantity, tc.method)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if order.ShippingCost != tc.want || order.Total != order.Subtotal+order.Tax+tc.want {
+				t.Fatalf("expected shipping of %v on top of the subtotal, got %+v", tc.want, order)
+			}
+		})
+	}
+}
+
+func TestOrderUsecaseShippingNeedsAnAddress(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	_, err := f.orderUsecase(usecase.OrderConfig{}).Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1, ShippingMethod: "standard"})
+	if !errors.Is(err, domain.ErrShippingAddressRequired) {
+		t.Fatalf("expected ErrShippingAddressRequired, got %v", err)
+	}
+}
+
+func TestFulfilmentUsecaseMovesOrdersStepByStep(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	fulfilment := f.fulfilmentUsecase()
+	book := f.book(t, 10, 5)
+
+	order, err := f.shippedOrder(t, orders, book, 1, "standard")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Business rule: orders are packed once paid
+	if _, err := fulfilment.Pack(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotFulfillable) {
+		t.Fatalf("expected ErrOrderNotFulfillable packing an unpaid order, got %v", err)
+	}
+	f.pay(t, orders, order.ID)
+
+	tracking, err := fulfilment.Tracking(ctx, order.ID)
+	if err != nil || tracking.Status != domain.OrderStatusPaid || tracking.PackedAt != nil {
+		t.Fatalf("expected a paid order without a shipment, got %+v, %v", tracking, err)
+	}
+
+	if _, err := fulfilment.Pack(ctx, order.ID); err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if _, err := fulfilment.Pack(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotFulfillable) {
+		t.Fatalf("expected ErrOrderNotFulfillable packing twice, got %v", err)
+	}
+	if _, err := fulfilment.Deliver(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotFulfillable) {
+		t.Fatalf("expected ErrOrderNotFulfillable delivering an unshipped order, got %v", err)
+	}
+
+	_, err = fulfilment.Ship(ctx, usecase.ShipOrderInput{OrderID: order.ID, Carrier: "JNE"})
+	if !errors.Is(err, domain.ErrInvalidShipment) {
+		t.Fatalf("expected ErrInvalidShipment without a tracking number, got %v", err)
+	}
+	if stored, _ := f.orders.FindByID(ctx, order.ID); stored.Status != domain.OrderStatusPacked {
+		t.Fatalf("expected a rejected shipment to leave the order packed, got %s", stored.Status)
+	}
+
+	if _, err := fulfilment.Ship(ctx, usecase.ShipOrderInput{OrderID: order.ID, Carrier: " JNE ", TrackingNumber: "JNE123"}); err != nil {
+		t.Fatalf("Ship: %v", err)
+	}
+	tracking, err = fulfilment.Deliver(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if tracking.Status != domain.OrderStatusDelivered || tracking.Carrier != "JNE" || tracking.PackedAt == nil || tracking.ShippedAt == nil || tracking.DeliveredAt == nil {
+		t.Fatalf("expected a delivered order with every step recorded, got %+v", tracking)
+	}
+
+	// Delivered orders can be returned
+	if _, err := f.returnUsecase().Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1, Reason: "damaged"}); err != nil {
+		t.Fatalf("expected a delivered order to be returnable, got %v", err)
+	}
+}
+
+func TestOrderUsecaseCompletesShippedOrdersOnceDelivered(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	fulfilment := f.fulfilmentUsecase()
+	book := f.book(t, 10, 5)
+
+	order, err := f.shippedOrder(t, orders, book, 1, "standard")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	f.pay(t, orders, order.ID)
+
+	// Business rule: a shipped order is completed only once delivered
+	steps := []func() error{
+		func() error { _, err := fulfilment.Pack(ctx, order.ID); return err },
+		func() error {
+			_, err := fulfilment.Ship(ctx, usecase.ShipOrderInput{OrderID: order.ID, Carrier: "JNE", TrackingNumber: "JNE123"})
+			return err
+		},
+		func() error { _, err := fulfilment.Deliver(ctx, order.ID); return err },
+	}
+	for _, step := range steps {
+		if _, err := orders.Complete(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotDelivered) {
+			t.Fatalf("expected ErrOrderNotDelivered, got %v", err)
+		}
+		if err := step(); err != nil {
+			t.Fatalf("fulfilment step: %v", err)
+		}
+	}
+
+	completed, err := orders.Complete(ctx, order.ID)
+	if err != nil || completed.Status != domain.OrderStatusCompleted {
+		t.Fatalf("expected a delivered order to complete, got %+v, %v", completed, err)
+	}
+	if _, err := orders.Complete(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending completing twice, got %v", err)
+	}
+}
+
+func TestFulfilmentUsecaseOnlyShipsOrdersWithAMethod(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 1)
+
+	if _, err := f.fulfilmentUsecase().Pack(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotShippable) {
+		t.Fatalf("expected ErrOrderNotShippable, got %v", err)
+	}
+	if _, err := f.fulfilmentUsecase().Tracking(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotShippable) {
+		t.Fatalf("expected ErrOrderNotShippable, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// maxIdempotencyKeyLength is the longest idempotency key a client can send.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyUsecase makes retried requests safe by remembering the
+// response to the first request sent with a key.
+type IdempotencyUsecase struct {
+	keyRepo domain.IdempotencyKeyRepository
+	ttl     time.Duration
+}
+
+// NewIdempotencyUsecase creates a new IdempotencyUsecase.
+// Keys are forgotten ttl after their first use.
+func NewIdempotencyUsecase(keyRepo domain.IdempotencyKeyRepository, ttl time.Duration) *IdempotencyUsecase {
+	return &IdempotencyUsecase{
+		keyRepo: keyRepo,
+		ttl:     ttl,
+	}
+}
+
+// BeginRequestInput is the input for starting a request with an idempotency key.
+type BeginRequestInput struct {
+	Key string
+	// Fingerprint identifies the request, e.g. a hash of its method, path and body.
+	Fingerprint string
+}
+
+// IdempotencyOutput is the outcome of starting a request with an idempotency key.
+// When Replay is set, StatusCode and Body are the stored response to the
+// first request and the request must not run again.
+type IdempotencyOutput struct {
+	ID         uint
+	Replay     bool
+	StatusCode int
+	Body       []byte
+}
+
+// Begin claims the key for a new request, or returns the stored response
+// when the request is a retry.
+func (u *IdempotencyUsecase) Begin(ctx context.Context, input BeginRequestInput) (IdempotencyOutput, error) {
+	// Business rule: keys are bounded so they fit the index
+	if input.Key == "" || len(input.Key) > maxIdempotencyKeyLength {
+		return IdempotencyOutput{}, domain.ErrInvalidIdempotencyKey
+	}
+
+	// A second attempt is needed when an expired key is cleared out of the way
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := u.keyRepo.Create(ctx, domain.NewIdempotencyKey(input.Key, input.Fingerprint, u.ttl))
+		if err == nil {
+			return IdempotencyOutput{ID: claimed.ID}, nil
+		}
+		if !errors.Is(err, domain.ErrIdempotencyKeyExists) {
+			return IdempotencyOutput{}, err
+		}
+
+		existing, err := u.keyRepo.FindByKey(ctx, input.Key)
+		if errors.Is(err, domain.ErrIdempotencyKeyNotFound) {
+			// Released by a failed first request in the meantime
+			continue
+		}
+		if err != nil {
+			return IdempotencyOutput{}, err
+		}
+
+		if existing.IsExpired(time.Now()) {
+			if err := u.keyRepo.Delete(ctx, existing.ID); err != nil {
+				return IdempotencyOutput{}, err
+			}
+			continue
+		}
+
+		// Business rule: a key belongs to the request it was first used with
+		if existing.Fingerprint != input.Fingerprint {
+			return IdempotencyOutput{}, domain.ErrIdempotencyKeyReused
+		}
+
+		if !existing.IsCompleted() {
+			return IdempotencyOutput{}, domain.ErrIdempotencyKeyInProgress
+		}
+
+		return IdempotencyOutput{
+			ID:         existing.ID,
+			Replay:     true,
+			StatusCode: existing.StatusCode,
+			Body:       existing.ResponseBody,
+		}, nil
+	}
+
+	return IdempotencyOutput{}, domain.ErrIdempotencyKeyInProgress
+}
+
+// Finish stores the response to the request that claimed the key.
+func (u *IdempotencyUsecase) Finish(ctx context.Context, id uint, statusCode int, body []byte) error {
+	return u.keyRepo.Complete(ctx, id, statusCode, body)
+}
+
+// Release forgets a claimed key whose request failed, so it can be retried.
+func (u *IdempotencyUsecase) Release(ctx context.Context, id uint) error {
+	return u.keyRepo.Delete(ctx, id)
+}
+
+// ExpireKeys deletes keys that expired at or before now and returns how many.
+func (u *IdempotencyUsecase) ExpireKeys(ctx context.Context, now time.Time) (int64, error) {
+	return u.keyRepo.DeleteExpired(ctx, now)
+}
@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestIdempotencyUsecaseBegin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewIdempotencyUsecase(f.idempotencyKeys, time.Hour)
+	request := usecase.BeginRequestInput{Key: "key-1", Fingerprint: "a"}
+
+	first, err := uc.Begin(ctx, request)
+	if err != nil || first.Replay {
+		t.Fatalf("expected the first request to claim the key, got %+v, %v", first, err)
+	}
+
+	// A retry while the first request runs must not run it twice
+	if _, err := uc.Begin(ctx, request); !errors.Is(err, domain.ErrIdempotencyKeyInProgress) {
+		t.Fatalf("expected ErrIdempotencyKeyInProgress, got %v", err)
+	}
+
+	if err := uc.Finish(ctx, first.ID, 201, []byte(`{"id":1}`)); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	retry, err := uc.Begin(ctx, request)
+	if err != nil || !retry.Replay || retry.StatusCode != 201 || string(retry.Body) != `{"id":1}` {
+		t.Fatalf("expected the stored response to be replayed, got %+v, %v", retry, err)
+	}
+
+	if _, err := uc.Begin(ctx, usecase.BeginRequestInput{Key: "key-1", Fingerprint: "b"}); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	for _, key := range []string{"", strings.Repeat("k", 256)} {
+		if _, err := uc.Begin(ctx, usecase.BeginRequestInput{Key: key, Fingerprint: "a"}); !errors.Is(err, domain.ErrInvalidIdempotencyKey) {
+			t.Fatalf("expected ErrInvalidIdempotencyKey for %d characters, got %v", len(key), err)
+		}
+	}
+}
+
+func TestIdempotencyUsecaseReleasedAndExpiredKeysAreReusable(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+
+	// A failed request releases its key so the retry runs
+	uc := usecase.NewIdempotencyUsecase(f.idempotencyKeys, time.Hour)
+	failed, _ := uc.Begin(ctx, usecase.BeginRequestInput{Key: "key-1", Fingerprint: "a"})
+	if err := uc.Release(ctx, failed.ID); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if retry, err := uc.Begin(ctx, usecase.BeginRequestInput{Key: "key-1", Fingerprint: "a"}); err != nil || retry.Replay {
+		t.Fatalf("expected the retry to claim the key, got %+v, %v", retry, err)
+	}
+
+	// An expired key is forgotten, even before the sweeper removes it
+	short := usecase.NewIdempotencyUsecase(f.idempotencyKeys, -time.Second)
+	old, _ := short.Begin(ctx, usecase.BeginRequestInput{Key: "key-2", Fingerprint: "a"})
+	_ = short.Finish(ctx, old.ID, 201, []byte(`{}`))
+	if reused, err := uc.Begin(ctx, usecase.BeginRequestInput{Key: "key-2", Fingerprint: "b"}); err != nil || reused.Replay {
+		t.Fatalf("expected the expired key to be claimable, got %+v, %v", reused, err)
+	}
+
+	_, _ = short.Begin(ctx, usecase.BeginRequestInput{Key: "key-3", Fingerprint: "a"})
+	if expired, err := uc.ExpireKeys(ctx, time.Now()); err != nil || expired != 1 {
+		t.Fatalf("expected 1 expired key, got %d, %v", expired, err)
+	}
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// IntegrityUsecase checks referential integrity between orders, users and books.
+type IntegrityUsecase struct {
+	orderRepo domain.OrderRepository
+}
+
+// NewIntegrityUsecase creates a new IntegrityUsecase.
+func NewIntegrityUsecase(orderRepo domain.OrderRepository) *IntegrityUsecase {
+	return &IntegrityUsecase{
+		orderRepo: orderRepo,
+	}
+}
+
+// OrphanedOrderOutput is the output for an order with a missing reference.
+type OrphanedOrderOutput struct {
+	OrderID     uint
+	UserID      uint
+	BookID      uint
+	MissingUser bool
+	MissingBook bool
+}
+
+// FindOrphanedOrders returns all orders pointing to a user or book that no longer exists.
+func (u *IntegrityUsecase) FindOrphanedOrders(ctx context.Context) ([]OrphanedOrderOutput, error) {
+	orphans, err := u.orderRepo.FindOrphaned(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]OrphanedOrderOutput, len(orphans))
+	for i, orphan := range orphans {
+		outputs[i] = OrphanedOrderOutput{
+			OrderID:     orphan.Order.ID,
+			UserID:      orphan.Order.UserID,
+			BookID:      orphan.Order.BookID,
+			MissingUser: orphan.MissingUser,
+			MissingBook: orphan.MissingBook,
+		}
+	}
+
+	return outputs, nil
+}
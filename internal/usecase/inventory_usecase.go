@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// InventoryUsecase handles the stock ledger.
+type InventoryUsecase struct {
+	bookRepo     domain.BookRepository
+	movementRepo domain.StockMovementRepository
+	allocator    BackorderAllocator
+	pricing      *PricingService
+}
+
+// BackorderAllocator hands incoming stock to orders waiting for it.
+// OrderUsecase implements it.
+type BackorderAllocator interface {
+	AllocateBackorders(ctx context.Context, bookID uint) (int, error)
+}
+
+// NewInventoryUsecase creates a new InventoryUsecase.
+func NewInventoryUsecase(bookRepo domain.BookRepository, movementRepo domain.StockMovementRepository, allocator BackorderAllocator, pricing *PricingService) *InventoryUsecase {
+	return &InventoryUsecase{
+		bookRepo:     bookRepo,
+		movementRepo: movementRepo,
+		allocator:    allocator,
+		pricing:      pricing,
+	}
+}
+
+// StockMovementOutput is the output for a stock ledger entry.
+type StockMovementOutput struct {
+	ID        uint
+	BookID    uint
+	Delta     int
+	Balance   int
+	Reason    string
+	Actor     string
+	Reference string
+	CreatedAt time.Time
+}
+
+// StockDriftOutput is the output for a book whose stock disagrees with its ledger.
+type StockDriftOutput struct {
+	BookID      uint
+	Title       string
+	Stock       int
+	LedgerStock int
+}
+
+// AdjustStockInput is the input for a manual stock adjustment.
+type AdjustStockInput struct {
+	BookID    uint
+	Delta     int
+	Reason    string
+	Reference string
+}
+
+// ReceiveShipmentInput is the input for receiving a shipment of many books.
+type ReceiveShipmentInput struct {
+	Reference string
+	Items     []ShipmentItemInput
+}
+
+// ShipmentItemInput is one line of a received shipment.
+type ShipmentItemInput struct {
+	BookID   uint
+	Quantity int
+}
+
+// Adjust applies a relative stock change to a book. It is applied to the
+// locked row, so concurrent orders are never overwritten. The ledger
+// records the signed in user of ctx as who made the change.
+func (u *InventoryUsecase) Adjust(ctx context.Context, input AdjustStockInput) (BookOutput, error) {
+	// Business rule: the delta must go the way the reason implies
+	if err := domain.ValidateAdjustment(input.Reason, input.Delta); err != nil {
+		return BookOutput{}, err
+	}
+
+	movement := domain.NewStockMovement(input.Reason, actorFrom(ctx), input.Reference)
+	book, err := u.bookRepo.UpdateStock(ctx, input.BookID, movement, func(book *domain.Book) error {
+		if input.Delta < 0 {
+			return book.DecreaseStock(-input.Delta)
+		}
+		book.IncreaseStock(input.Delta, domain.StockConditionSellable)
+		return nil
+	})
+	if err != nil {
+		return BookOutput{}, err
+	}
+
+	if input.Delta > 0 {
+		book = u.allocateBackorders(ctx, book)
+	}
+
+	return u.pricing.bookOutput(ctx, book)
+}
+
+// ReceiveShipment restocks every book in a shipment. All lines are checked
+// before any stock is changed, so a bad line rejects the whole shipment.
+func (u *InventoryUsecase) ReceiveShipment(ctx context.Context, input ReceiveShipmentInput) ([]BookOutput, error) {
+	if len(input.Items) == 0 {
+		return nil, domain.ErrEmptyShipment
+	}
+
+	for _, item := range input.Items {
+		// Business rule: quantity must be positive
+		if item.Quantity <= 0 {
+			return nil, domain.ErrInvalidQuantity
+		}
+
+		// Check if book exists
+		if _, err := u.bookRepo.FindByID(ctx, item.BookID); err != nil {
+			return nil, err
+		}
+	}
+
+	books := make([]domain.Book, len(input.Items))
+	for i, item := range input.Items {
+		movement := domain.NewStockMovement(domain.StockReasonRestock, actorFrom(ctx), input.Reference)
+		book, err := u.bookRepo.UpdateStock(ctx, item.BookID, movement, func(book *domain.Book) error {
+			book.IncreaseStock(item.Quantity, domain.StockConditionSellable)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		books[i] = u.allocateBackorders(ctx, book)
+	}
+
+	return u.pricing.bookOutputs(ctx, books)
+}
+
+// allocateBackorders hands a restocked book's stock to its backorders and
+// returns the book as it is afterwards. The restock stands even if
+// allocation fails; the next stock increase retries it.
+func (u *InventoryUsecase) allocateBackorders(ctx context.Context, book domain.Book) domain.Book {
+	allocated, err := u.allocator.AllocateBackorders(ctx, book.ID)
+	if err != nil {
+		log.Printf("Failed to allocate backorders of book %d: %v", book.ID, err)
+	}
+	if allocated == 0 {
+		return book
+	}
+
+	current, err := u.bookRepo.FindByID(ctx, book.ID)
+	if err != nil {
+		return book
+	}
+	return current
+}
+
+// LowStock returns the books whose available stock is below their reorder threshold.
+func (u *InventoryUsecase) LowStock(ctx context.Context) ([]BookOutput, error) {
+	books, err := u.bookRepo.FindLowStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.pricing.bookOutputs(ctx, books)
+}
+
+// StockHistory returns a book's stock movements, oldest first.
+func (u *InventoryUsecase) StockHistory(ctx context.Context, bookID uint) ([]StockMovementOutput, error) {
+	// Check if book exists
+	if _, err := u.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	movements, err := u.movementRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]StockMovementOutput, len(movements))
+	for i, movement := range movements {
+		outputs[i] = toStockMovementOutput(movement)
+	}
+
+	return outputs, nil
+}
+
+// Reconcile recomputes every book's stock from the ledger and returns the
+// books that disagree. With apply set, their stock is reset to the ledger
+// under the book's lock, the reset is recorded in the ledger, and stock that
+// went up is allocated to backorders.
+func (u *InventoryUsecase) Reconcile(ctx context.Context, apply bool) ([]StockDriftOutput, error) {
+	ctx = withActor(ctx, systemActor)
+
+	books, _, err := u.bookRepo.FindAll(ctx, domain.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Soft deleted books can be restored, so their stock must be right too
+	deleted, err := u.bookRepo.FindDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	books = append(books, deleted...)
+
+	balances, err := u.movementRepo.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := make([]StockDriftOutput, 0)
+	for _, book := range books {
+		ledgerStock := balances[book.ID]
+		if book.Stock == ledgerStock {
+			continue
+		}
+
+		if apply {
+			// Check again under the book's lock: the stock may have moved
+			// along with the ledger since they were read
+			movement := domain.NewStockMovement(domain.StockReasonReconcile, actorFrom(ctx), "")
+			locked, ledger, err := u.bookRepo.ReconcileStock(ctx, book.ID, movement)
+			if err != nil {
+				return nil, err
+			}
+			book.Stock, ledgerStock = locked.Stock, ledger
+			if book.Stock == ledgerStock {
+				continue
+			}
+			if ledgerStock > book.Stock && book.DeletedAt == nil {
+				u.allocateBackorders(ctx, book)
+			}
+		}
+
+		drifts = append(drifts, StockDriftOutput{
+			BookID:      book.ID,
+			Title:       book.Title,
+			Stock:       book.Stock,
+			LedgerStock: ledgerStock,
+		})
+	}
+
+	return drifts, nil
+}
+
+// toStockMovementOutput converts domain.StockMovement to StockMovementOutput.
+func toStockMovementOutput(movement domain.StockMovement) StockMovementOutput {
+	return StockMovementOutput{
+		ID:        movement.ID,
+		BookID:    movement.BookID,
+		Delta:     movement.Delta,
+		Balance:   movement.Balance,
+		Reason:    movement.Reason,
+		Actor:     movement.Actor,
+		Reference: movement.Reference,
+		CreatedAt: movement.CreatedAt,
+	}
+}
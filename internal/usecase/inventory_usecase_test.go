@@ -0,0 +1,193 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestInventoryUsecaseStockHistory(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.inventoryUsecase()
+	book := f.book(t, 10, 5)
+
+	if _, err := uc.Adjust(ctx, usecase.AdjustStockInput{BookID: book.ID, Delta: 3, Reason: domain.StockReasonAdjustment}); err != nil {
+		t.Fatalf("Adjust: %v", err)
+	}
+
+	history, err := uc.StockHistory(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("StockHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 movements, got %+v", history)
+	}
+	if adjustment := history[1]; adjustment.Reason != domain.StockReasonAdjustment || adjustment.Delta != 3 || adjustment.Balance != 8 {
+		t.Fatalf("unexpected adjustment: %+v", adjustment)
+	}
+
+	if _, err := uc.StockHistory(ctx, 999); !errors.Is(err, domain.ErrBookNotFound) {
+		t.Fatalf("expected ErrBookNotFound, got %v", err)
+	}
+}
+
+func TestInventoryUsecaseAdjust(t *testing.T) {
+	tests := []struct {
+		name      string
+		delta     int
+		reason    string
+		wantStock int
+		wantErr   error
+	}{
+		{name: "restock", delta: 4, reason: domain.StockReasonRestock, wantStock: 9},
+		{name: "damaged", delta: -2, reason: domain.StockReasonDamaged, wantStock: 3},
+		{name: "correction down", delta: -5, reason: domain.StockReasonAdjustment, wantStock: 0},
+		{name: "below zero", delta: -6, reason: domain.StockReasonLost, wantErr: domain.ErrInsufficientStock},
+		{name: "zero delta", delta: 0, reason: domain.StockReasonAdjustment, wantErr: domain.ErrInvalidAdjustment},
+		{name: "wrong direction", delta: 2, reason: domain.StockReasonLost, wantErr: domain.ErrInvalidAdjustment},
+		{name: "order reason", delta: -1, reason: domain.StockReasonOrder, wantErr: domain.ErrInvalidAdjustment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := usecase.WithUser(context.Background(), 7)
+			f := newFixture()
+			uc := f.inventoryUsecase()
+			book := f.book(t, 10, 5)
+
+			output, err := uc.Adjust(ctx, usecase.AdjustStockInput{BookID: book.ID, Delta: tt.delta, Reason: tt.reason, Reference: "count"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if current, _ := f.books.FindByID(ctx, book.ID); current.Stock != 5 {
+					t.Fatalf("rejected adjustment changed stock to %d", current.Stock)
+				}
+				return
+			}
+			if output.Stock != tt.wantStock || output.Version <= book.Version {
+				t.Fatalf("unexpected output: %+v", output)
+			}
+
+			history, _ := f.stockMovements.FindByBookID(ctx, book.ID)
+			if last := history[len(history)-1]; last.Reason != tt.reason || last.Delta != tt.delta || last.Reference != "count" || last.Actor != "user:7" {
+				t.Fatalf("unexpected movement: %+v", last)
+			}
+		})
+	}
+}
+
+func TestInventoryUsecaseReceiveShipment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.inventoryUsecase()
+	first := f.book(t, 10, 1)
+	second := f.book(t, 12, 0)
+
+	// A bad line rejects the whole shipment
+	bad := usecase.ReceiveShipmentInput{Items: []usecase.ShipmentItemInput{
+		{BookID: first.ID, Quantity: 5},
+		{BookID: 999, Quantity: 5},
+	}}
+	if _, err := uc.ReceiveShipment(ctx, bad); !errors.Is(err, domain.ErrBookNotFound) {
+		t.Fatalf("expected ErrBookNotFound, got %v", err)
+	}
+	if current, _ := f.books.FindByID(ctx, first.ID); current.Stock != 1 {
+		t.Fatalf("rejected shipment changed stock to %d", current.Stock)
+	}
+
+	if _, err := uc.ReceiveShipment(ctx, usecase.ReceiveShipmentInput{}); !errors.Is(err, domain.ErrEmptyShipment) {
+		t.Fatalf("expected ErrEmptyShipment, got %v", err)
+	}
+
+	input := usecase.ReceiveShipmentInput{
+		Reference: "PO-42",
+		Items: []usecase.ShipmentItemInput{
+			{BookID: first.ID, Quantity: 5},
+			{BookID: second.ID, Quantity: 3},
+		},
+	}
+	outputs, err := uc.ReceiveShipment(usecase.WithUser(ctx, 7), input)
+	if err != nil {
+		t.Fatalf("ReceiveShipment: %v", err)
+	}
+	if len(outputs) != 2 || outputs[0].Stock != 6 || outputs[1].Stock != 3 {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+
+	history, _ := f.stockMovements.FindByBookID(ctx, second.ID)
+	if len(history) != 1 || history[0].Reason != domain.StockReasonRestock || history[0].Reference != "PO-42" || history[0].Actor != "user:7" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestInventoryUsecaseReconcile(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.inventoryUsecase()
+	drifted := f.book(t, 10, 5)
+	f.book(t, 10, 3)
+
+	// Simulate a write that bypassed the ledger
+	f.books.SetStock(drifted.ID, 9)
+
+	drifts, err := uc.Reconcile(ctx, false)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].BookID != drifted.ID || drifts[0].Stock != 9 || drifts[0].LedgerStock != 5 {
+		t.Fatalf("unexpected drifts: %+v", drifts)
+	}
+	if current, _ := f.books.FindByID(ctx, drifted.ID); current.Stock != 9 {
+		t.Fatalf("dry run changed stock to %d", current.Stock)
+	}
+
+	if _, err := uc.Reconcile(ctx, true); err != nil {
+		t.Fatalf("Reconcile apply: %v", err)
+	}
+	if current, _ := f.books.FindByID(ctx, drifted.ID); current.Stock != 5 {
+		t.Fatalf("expected stock reset to 5, got %d", current.Stock)
+	}
+	history, _ := f.stockMovements.FindByBookID(ctx, drifted.ID)
+	last := history[len(history)-1]
+	if last.Reason != domain.StockReasonReconcile || last.Delta != 0 || last.Balance != 5 || last.Reference != "stock was 9" || last.Actor != "system" {
+		t.Fatalf("unexpected reconcile movement: %+v", last)
+	}
+	if drifts, _ := uc.Reconcile(ctx, false); len(drifts) != 0 {
+		t.Fatalf("expected no drift after apply, got %+v", drifts)
+	}
+}
+
+func TestInventoryUsecaseLowStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.inventoryUsecase()
+	f.book(t, 10, 1)
+
+	book := domain.NewBook("Go", 10, 3)
+	book.ReorderThreshold = 5
+	book, err := f.books.Save(ctx, book, domain.NewInitialStock(""))
+	if err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+
+	books, err := uc.LowStock(ctx)
+	if err != nil {
+		t.Fatalf("LowStock: %v", err)
+	}
+	if len(books) != 1 || books[0].ID != book.ID {
+		t.Fatalf("expected only the book below its threshold, got %+v", books)
+	}
+
+	// Restocking above the threshold takes it off the list
+	if _, err := uc.Adjust(ctx, usecase.AdjustStockInput{BookID: book.ID, Delta: 5, Reason: domain.StockReasonRestock}); err != nil {
+		t.Fatalf("Adjust: %v", err)
+	}
+	if books, _ := uc.LowStock(ctx); len(books) != 0 {
+		t.Fatalf("expected no low-stock books, got %+v", books)
+	}
+}
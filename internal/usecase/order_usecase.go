@@ -2,15 +2,44 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/domain"
 )
 
 // OrderUsecase handles all order business logic.
 type OrderUsecase struct {
-	orderRepo domain.OrderRepository
-	bookRepo  domain.BookRepository
-	userRepo  domain.UserRepository
+	orderRepo       domain.OrderRepository
+	bookRepo        domain.BookRepository
+	userRepo        domain.UserRepository
+	reservationRepo domain.ReservationRepository
+	paymentRepo     domain.PaymentRepository
+	promotionRepo   domain.PromotionRepository
+	addressRepo     domain.AddressRepository
+	pricing         *PricingService
+	taxes           domain.TaxCalculator
+	shipping        domain.ShippingCalculator
+	gateway         domain.PaymentGateway
+	notifier        domain.StockNotifier
+	mailer          domain.Mailer
+	config          OrderConfig
+}
+
+// OrderConfig holds settings for placing orders.
+type OrderConfig struct {
+	// RequireVerifiedEmail rejects orders from unverified accounts.
+	RequireVerifiedEmail bool
+	// ReservationTTL is how long a pending order holds its stock.
+	ReservationTTL time.Duration
+	// BackorderReservationTTL is how long a backordered order holds the
+	// stock allocated to it. Its customer is told by mail and is not at
+	// checkout, so it is longer than ReservationTTL.
+	BackorderReservationTTL time.Duration
+	// TaxRegion is where orders without a shipping address are taxed.
+	TaxRegion string
 }
 
 // NewOrderUsecase creates a new OrderUsecase.
@@ -18,11 +47,33 @@ func NewOrderUsecase(
 	orderRepo domain.OrderRepository,
 	bookRepo domain.BookRepository,
 	userRepo domain.UserRepository,
+	reservationRepo domain.ReservationRepository,
+	paymentRepo domain.PaymentRepository,
+	promotionRepo domain.PromotionRepository,
+	addressRepo domain.AddressRepository,
+	pricing *PricingService,
+	taxes domain.TaxCalculator,
+	shipping domain.ShippingCalculator,
+	gateway domain.PaymentGateway,
+	notifier domain.StockNotifier,
+	mailer domain.Mailer,
+	config OrderConfig,
 ) *OrderUsecase {
 	return &OrderUsecase{
-		orderRepo: orderRepo,
-		bookRepo:  bookRepo,
-		userRepo:  userRepo,
+		orderRepo:       orderRepo,
+		bookRepo:        bookRepo,
+		userRepo:        userRepo,
+		reservationRepo: reservationRepo,
+		paymentRepo:     paymentRepo,
+		promotionRepo:   promotionRepo,
+		addressRepo:     addressRepo,
+		pricing:         pricing,
+		taxes:           taxes,
+		shipping:        shipping,
+		gateway:         gateway,
+		notifier:        notifier,
+		mailer:          mailer,
+		config:          config,
 	}
 }
 
@@ -31,16 +82,56 @@ type CreateOrderInput struct {
 	UserID   uint
 	BookID   uint
 	Quantity int
+	// PromotionCode is an optional coupon code to apply to the order.
+	PromotionCode string
+	// ShippingAddressID and BillingAddressID pick addresses from the
+	// user's address book. Both are optional; billing defaults to shipping.
+	ShippingAddressID *uint
+	BillingAddressID  *uint
+	// ShippingMethod is the code of the shipping method to ship with. It
+	// needs a shipping address; orders without one are not shipped.
+	ShippingMethod string
 }
 
 // OrderOutput is the output for order operations.
 type OrderOutput struct {
-	ID       uint
-	UserID   uint
-	BookID   uint
-	Quantity int
-	Total    float64
-	Status   string
+	ID             uint
+	UserID         uint
+	BookID         uint
+	Quantity       int
+	UnitPrice      float64
+	Subtotal       float64
+	Tax            float64
+	ShippingMethod string
+	ShippingCost   float64
+	Total          float64
+	Discount       float64
+	PromotionCode  string
+	RefundedAmount float64
+	// ShippingAddress and BillingAddress are as they were when the order was placed.
+	ShippingAddress *AddressFields
+	BillingAddress  *AddressFields
+	Status          string
+	DeletedAt       *time.Time
+}
+
+// PayOrderInput is the input for paying an order.
+type PayOrderInput struct {
+	OrderID uint
+	// PaymentToken identifies the customer's payment method at the provider.
+	PaymentToken string
+}
+
+// PaymentOutput is the output for payment operations.
+type PaymentOutput struct {
+	ID             uint
+	OrderID        uint
+	Amount         float64
+	Status         string
+	Reference      string
+	FailureReason  string
+	RefundedAmount float64
+	CreatedAt      time.Time
 }
 
 // Create creates a new order with business validations.
@@ -51,43 +142,855 @@ func (u *OrderUsecase) Create(ctx context.Context, input CreateOrderInput) (Orde
 	}
 
 	// Check user exists
-	_, err := u.userRepo.FindByID(ctx, input.UserID)
+	user, err := u.userRepo.FindByID(ctx, input.UserID)
 	if err != nil {
 		return OrderOutput{}, domain.ErrUserNotFound
 	}
 
-	// Check book exists and has stock
-	book, err := u.bookRepo.FindByID(ctx, input.BookID)
+	// Business rule: unverified accounts cannot order when verification is required
+	if u.config.RequireVerifiedEmail && !user.Verified {
+		return OrderOutput{}, domain.ErrEmailNotVerified
+	}
+
+	// Copy the addresses, so editing the address book leaves the order alone
+	shipping, err := u.findAddress(ctx, user.ID, input.ShippingAddressID)
 	if err != nil {
-		return OrderOutput{}, domain.ErrBookNotFound
+		return OrderOutput{}, err
+	}
+	billing := shipping
+	if input.BillingAddressID != nil {
+		if billing, err = u.findAddress(ctx, user.ID, input.BillingAddressID); err != nil {
+			return OrderOutput{}, err
+		}
+	}
+
+	// Business rule: only orders with somewhere to go can be shipped
+	if input.ShippingMethod != "" && shipping == nil {
+		return OrderOutput{}, domain.ErrShippingAddressRequired
 	}
 
-	// Business rule: check stock availability
-	if err := book.DecreaseStock(input.Quantity); err != nil {
+	// Check book exists
+	book, err := u.bookRepo.FindByID(ctx, input.BookID)
+	if err != nil {
 		return OrderOutput{}, err
 	}
 
-	// Calculate total
-	total := book.Price * float64(input.Quantity)
+	// Price the book as customers see it, including any sale
+	quote, err := u.pricing.Quote(ctx, book, time.Now())
+	if err != nil {
+		return OrderOutput{}, err
+	}
 
 	// Create order
-	order := domain.NewOrder(input.UserID, input.BookID, input.Quantity, total)
+	order := domain.NewOrder(input.UserID, input.BookID, input.Quantity, quote.Price)
+	order.ShippingAddress = shipping
+	order.BillingAddress = billing
 
-	// Update book stock
-	_, err = u.bookRepo.Update(ctx, book)
+	// Take the promotion's discount off the total
+	promotion, err := u.applyPromotion(ctx, &order, book, input.PromotionCode)
 	if err != nil {
 		return OrderOutput{}, err
 	}
 
-	// Save order
+	// Tax the discounted price where the order ships to
+	region := u.config.TaxRegion
+	if shipping != nil {
+		region = shipping.TaxRegion()
+	}
+	breakdown, err := u.taxes.Calculate(ctx, domain.TaxLine{
+		Region:   region,
+		Category: book.Category,
+		Amount:   order.Total,
+	})
+	if err != nil {
+		return OrderOutput{}, err
+	}
+	order.ApplyTax(breakdown)
+
+	// Add shipping, priced by the method's rate table
+	if input.ShippingMethod != "" {
+		cost, err := u.shipping.Quote(ctx, domain.ShippingLine{
+			Method:   input.ShippingMethod,
+			Quantity: order.Quantity,
+			Weight:   book.Weight * order.Quantity,
+		})
+		if err != nil {
+			return OrderOutput{}, err
+		}
+		order.ApplyShipping(domain.NormalizeShippingMethod(input.ShippingMethod), cost)
+	}
+
+	// Save order first so its reservation can reference it
 	saved, err := u.orderRepo.Save(ctx, order)
 	if err != nil {
 		return OrderOutput{}, err
 	}
 
+	// Business rule: usage limits are checked as the promotion is redeemed,
+	// so concurrent orders cannot use it more often than allowed
+	if promotion != nil {
+		if err := u.promotionRepo.Redeem(ctx, domain.NewPromotionRedemption(*promotion, saved)); err != nil {
+			u.discard(ctx, saved)
+			return OrderOutput{}, err
+		}
+	}
+
+	// Business rule: orders for a book others are waiting on join the back
+	// of the queue, so incoming stock goes to the earliest orders first
+	if book.AcceptsBackorders(time.Now()) {
+		queued, err := u.orderRepo.FindBackordered(ctx, book.ID)
+		if err != nil {
+			u.discard(ctx, saved)
+			return OrderOutput{}, err
+		}
+		if len(queued) > 0 {
+			return u.backorder(ctx, saved)
+		}
+	}
+
+	// Business rule: check stock availability and hold the stock until the
+	// order is completed, cancelled or expires
+	err = u.reserveStock(ctx, saved, u.config.ReservationTTL)
+	if errors.Is(err, domain.ErrInsufficientStock) && book.AcceptsBackorders(time.Now()) {
+		return u.backorder(ctx, saved)
+	}
+	if err != nil {
+		u.discard(ctx, saved)
+		return OrderOutput{}, err
+	}
+
 	return toOrderOutput(saved), nil
 }
 
+// findAddress returns a copy of an address from a user's address book, or
+// nil when no address was picked. Other users' addresses are not found.
+func (u *OrderUsecase) findAddress(ctx context.Context, userID uint, id *uint) (*domain.AddressSnapshot, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	address, err := u.addressRepo.FindByID(ctx, *id)
+	if err != nil {
+		return nil, err
+	}
+	if address.UserID != userID {
+		return nil, domain.ErrAddressNotFound
+	}
+
+	return &address.AddressSnapshot, nil
+}
+
+// applyPromotion prices an order with the promotion the customer entered
+// and returns it for redemption. Orders without a code are left as they are.
+func (u *OrderUsecase) applyPromotion(ctx context.Context, order *domain.Order, book domain.Book, code string) (*domain.Promotion, error) {
+	code = domain.NormalizePromotionCode(code)
+	if code == "" {
+		return nil, nil
+	}
+
+	promotion, err := u.promotionRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := promotion.Discount(book, order.Total, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	order.ApplyPromotion(promotion, discount)
+	return &promotion, nil
+}
+
+// releasePromotion frees an order's promotion redemption so it counts
+// against no limit. Failures are logged; the order is already cancelled.
+func (u *OrderUsecase) releasePromotion(ctx context.Context, order domain.Order) {
+	if order.PromotionCode == "" {
+		return
+	}
+	if err := u.promotionRepo.Release(ctx, order.ID); err != nil {
+		log.Printf("Failed to release promotion of order %d: %v", order.ID, err)
+	}
+}
+
+// backorder parks an order until stock arrives for it.
+func (u *OrderUsecase) backorder(ctx context.Context, order domain.Order) (OrderOutput, error) {
+	order.Backorder()
+	updated, err := u.orderRepo.Update(ctx, order)
+	if err != nil {
+		u.discard(ctx, order)
+		return OrderOutput{}, err
+	}
+
+	return toOrderOutput(updated), nil
+}
+
+// AllocateBackorders reserves a book's available stock for its backordered
+// orders, oldest first, and returns how many became pending. Allocation
+// stops at the first order that does not fit, so later orders never jump
+// the queue.
+func (u *OrderUsecase) AllocateBackorders(ctx context.Context, bookID uint) (int, error) {
+	orders, err := u.orderRepo.FindBackordered(ctx, bookID)
+	if err != nil {
+		return 0, err
+	}
+
+	var allocated int
+	for _, order := range orders {
+		// Claim the order so concurrent allocations and cancellations skip it
+		err := u.orderRepo.UpdateStatus(ctx, order.ID, domain.OrderStatusBackordered, domain.OrderStatusPending)
+		if errors.Is(err, domain.ErrOrderStatusChanged) || errors.Is(err, domain.ErrOrderNotFound) {
+			continue
+		}
+		if err != nil {
+			return allocated, err
+		}
+
+		if err := u.reserveStock(ctx, order, u.config.BackorderReservationTTL); err != nil {
+			// Put the order back in its place in the queue
+			if err := u.orderRepo.UpdateStatus(ctx, order.ID, domain.OrderStatusPending, domain.OrderStatusBackordered); err != nil {
+				log.Printf("Failed to return order %d to the backorder queue: %v", order.ID, err)
+			}
+			if errors.Is(err, domain.ErrInsufficientStock) {
+				break
+			}
+			return allocated, err
+		}
+		allocated++
+		u.notifyAllocated(ctx, order)
+	}
+
+	return allocated, nil
+}
+
+// allocateBackorders allocates a book's backorders after stock was freed.
+// Failures are logged; the next stock increase retries them.
+func (u *OrderUsecase) allocateBackorders(ctx context.Context, bookID uint) {
+	if _, err := u.AllocateBackorders(ctx, bookID); err != nil {
+		log.Printf("Failed to allocate backorders of book %d: %v", bookID, err)
+	}
+}
+
+// reserveStock holds an order's stock under a row lock so concurrent
+// orders never reserve the same stock, then records the reservation for ttl.
+func (u *OrderUsecase) reserveStock(ctx context.Context, order domain.Order, ttl time.Duration) error {
+	movement := domain.NewStockMovement(domain.StockReasonOrder, userActor(order.UserID), orderReference(order.ID))
+
+	var wasLow bool
+	book, err := u.bookRepo.UpdateStock(ctx, order.BookID, movement, func(book *domain.Book) error {
+		wasLow = book.IsLowStock()
+		return book.Reserve(order.Quantity)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.reservationRepo.Save(ctx, domain.NewReservation(order, ttl)); err != nil {
+		if err := u.releaseStock(ctx, order.ID, order.BookID, order.Quantity); err != nil {
+			log.Printf("Failed to release stock of order %d: %v", order.ID, err)
+		}
+		return err
+	}
+
+	// Only the order that crosses the threshold raises the alert
+	if !wasLow && book.IsLowStock() {
+		u.notifyLowStock(ctx, book, order.ID)
+	}
+
+	return nil
+}
+
+// notifyAllocated tells a customer their backordered order has its stock
+// and must be paid before the reservation expires. A failed mail does not
+// undo the allocation.
+func (u *OrderUsecase) notifyAllocated(ctx context.Context, order domain.Order) {
+	user, err := u.userRepo.FindByID(ctx, order.UserID)
+	if err != nil {
+		log.Printf("Failed to find customer of allocated order %d: %v", order.ID, err)
+		return
+	}
+
+	mail := domain.Mail{
+		To:      user.Email,
+		Subject: "Your order is ready to pay",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe copies you ordered in order %d are in stock and held for you for %s. Please pay for the order before then, or the copies go to the next customer.\n",
+			user.Name, order.ID, u.config.BackorderReservationTTL,
+		),
+	}
+	if err := u.mailer.Send(ctx, mail); err != nil {
+		log.Printf("Failed to mail customer of allocated order %d: %v", order.ID, err)
+	}
+}
+
+// notifyLowStock tells purchasing a book is running out. A failed
+// notification does not fail the order.
+func (u *OrderUsecase) notifyLowStock(ctx context.Context, book domain.Book, orderID uint) {
+	if err := u.notifier.NotifyLowStock(ctx, domain.NewLowStockAlert(book, orderID)); err != nil {
+		log.Printf("Failed to send low stock alert for book %d: %v", book.ID, err)
+	}
+}
+
+// releaseStock returns an order's reserved stock to the available stock.
+func (u *OrderUsecase) releaseStock(ctx context.Context, orderID, bookID uint, quantity int) error {
+	movement := domain.NewStockMovement(domain.StockReasonCancel, actorFrom(ctx), orderReference(orderID))
+
+	_, err := u.bookRepo.UpdateStock(ctx, bookID, movement, func(book *domain.Book) error {
+		book.ReleaseReservation(quantity)
+		return nil
+	})
+	return err
+}
+
+// discard cancels and soft deletes an order that could not be placed.
+func (u *OrderUsecase) discard(ctx context.Context, order domain.Order) {
+	order.Cancel()
+	if _, err := u.orderRepo.Update(ctx, order); err != nil {
+		log.Printf("Failed to cancel order %d: %v", order.ID, err)
+	}
+	if err := u.orderRepo.Delete(ctx, order.ID); err != nil {
+		log.Printf("Failed to discard order %d: %v", order.ID, err)
+	}
+	u.releasePromotion(ctx, order)
+}
+
+// Pay authorizes a pending order's total with the payment gateway. Every
+// attempt is recorded; a declined attempt leaves the order pending so the
+// customer can try another payment method.
+func (u *OrderUsecase) Pay(ctx context.Context, input PayOrderInput) (PaymentOutput, error) {
+	if input.PaymentToken == "" {
+		return PaymentOutput{}, domain.ErrInvalidPaymentToken
+	}
+
+	order, err := u.pendingOrder(ctx, input.OrderID)
+	if err != nil {
+		return PaymentOutput{}, err
+	}
+
+	// Business rule: an order is paid with one payment at a time. Recording
+	// the attempt claims the order, so a concurrent attempt fails with
+	// ErrPaymentExists before it reaches the provider, and none goes unnoticed.
+	payment, err := u.paymentRepo.Save(ctx, domain.NewPayment(order))
+	if err != nil {
+		return PaymentOutput{}, err
+	}
+
+	result, authErr := u.gateway.Authorize(ctx, domain.PaymentRequest{
+		OrderID:   order.ID,
+		PaymentID: payment.ID,
+		Amount:    payment.Amount,
+		Token:     input.PaymentToken,
+	})
+	switch {
+	case authErr != nil:
+		payment.Fail(authErr.Error())
+	case !result.Approved:
+		payment.Decline(result.DeclineReason)
+	default:
+		payment.Authorize(result.Reference)
+	}
+
+	updated, err := u.paymentRepo.Update(ctx, payment)
+	if err != nil {
+		return PaymentOutput{}, err
+	}
+
+	if authErr != nil {
+		return toPaymentOutput(updated), authErr
+	}
+	if !result.Approved {
+		return toPaymentOutput(updated), domain.ErrPaymentDeclined
+	}
+
+	return toPaymentOutput(updated), nil
+}
+
+// CapturePayment collects a pending order's authorized payment and takes
+// its reserved stock for good. The order is then paid and waits to be
+// completed. A payment captured before a later step failed is not captured
+// again, so the call can be retried.
+func (u *OrderUsecase) CapturePayment(ctx context.Context, orderID uint) (OrderOutput, error) {
+	order, err := u.pendingOrder(ctx, orderID)
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	payment, err := u.activePayment(ctx, order.ID)
+	if errors.Is(err, domain.ErrPaymentNotFound) {
+		return OrderOutput{}, domain.ErrPaymentNotAuthorized
+	}
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	// Business rule: an order whose reservation was released cannot be paid
+	reservation, err := u.claimReservation(ctx, order.ID)
+	if errors.Is(err, domain.ErrReservationNotFound) {
+		return OrderOutput{}, domain.ErrOrderNotPending
+	}
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	if payment.IsAuthorized() {
+		if err := u.capture(ctx, payment); err != nil {
+			u.restoreReservation(ctx, reservation)
+			return OrderOutput{}, err
+		}
+	}
+
+	movement := domain.NewStockMovement(domain.StockReasonOrder, userActor(order.UserID), orderReference(order.ID))
+	_, err = u.bookRepo.UpdateStock(ctx, reservation.BookID, movement, func(book *domain.Book) error {
+		book.CommitReservation(reservation.Quantity)
+		return nil
+	})
+	if err != nil {
+		u.restoreReservation(ctx, reservation)
+		return OrderOutput{}, err
+	}
+
+	// Holding the reservation makes this the only request that can settle
+	// the order; a cancellation racing it backs off once it finds it gone
+	order.MarkPaid()
+	updated, err := u.orderRepo.Update(ctx, order)
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	return toOrderOutput(updated), nil
+}
+
+// capture collects an authorized payment and records it as captured.
+func (u *OrderUsecase) capture(ctx context.Context, payment domain.Payment) error {
+	result, err := u.gateway.Capture(ctx, payment.Reference, payment.Amount)
+	if err != nil {
+		return err
+	}
+	if !result.Approved {
+		return domain.ErrPaymentDeclined
+	}
+
+	payment.Capture()
+	if _, err := u.paymentRepo.Update(ctx, payment); err != nil {
+		log.Printf("Payment %d was captured but could not be recorded: %v", payment.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// Payments returns an order's payment attempts, oldest first.
+func (u *OrderUsecase) Payments(ctx context.Context, orderID uint) ([]PaymentOutput, error) {
+	if _, err := u.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	payments, err := u.paymentRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]PaymentOutput, len(payments))
+	for i, payment := range payments {
+		outputs[i] = toPaymentOutput(payment)
+	}
+
+	return outputs, nil
+}
+
+// activePayment finds the payment that holds or has collected an order's money.
+// It fails with ErrPaymentNotFound if the order has none.
+func (u *OrderUsecase) activePayment(ctx context.Context, orderID uint) (domain.Payment, error) {
+	payments, err := u.paymentRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	for _, payment := range payments {
+		if payment.IsActive() {
+			return payment, nil
+		}
+	}
+
+	return domain.Payment{}, domain.ErrPaymentNotFound
+}
+
+// voidPayments releases the authorizations of an order that will not be
+// paid. Failures are logged; providers let unused authorizations lapse.
+func (u *OrderUsecase) voidPayments(ctx context.Context, orderID uint) {
+	payments, err := u.paymentRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		log.Printf("Failed to find payments of order %d: %v", orderID, err)
+		return
+	}
+
+	for _, payment := range payments {
+		if payment.IsAuthorized() {
+			u.voidPayment(ctx, payment)
+		}
+	}
+}
+
+// voidPayment releases an authorization that will not be captured.
+// Failures are logged; providers let unused authorizations lapse.
+func (u *OrderUsecase) voidPayment(ctx context.Context, payment domain.Payment) {
+	result, err := u.gateway.Void(ctx, payment.Reference)
+	if err == nil && !result.Approved {
+		err = fmt.Errorf("void declined: %s", result.DeclineReason)
+	}
+	if err != nil {
+		log.Printf("Failed to void payment %d: %v", payment.ID, err)
+		return
+	}
+
+	payment.Void()
+	if _, err := u.paymentRepo.Update(ctx, payment); err != nil {
+		log.Printf("Failed to record void of payment %d: %v", payment.ID, err)
+	}
+}
+
+// ApplyPaymentEvent brings a payment, and its order, up to date with a
+// result the payment provider reported asynchronously. Events that arrive
+// late or twice, or are about unknown payments, change nothing.
+func (u *OrderUsecase) ApplyPaymentEvent(ctx context.Context, event domain.PaymentEvent) error {
+	payment, err := u.paymentRepo.FindByID(ctx, event.PaymentID)
+	if errors.Is(err, domain.ErrPaymentNotFound) {
+		log.Printf("Ignoring %s event %s for unknown payment %d", event.Type, event.ID, event.PaymentID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case domain.PaymentEventAuthorized:
+		if !payment.IsUnsettled() {
+			return nil
+		}
+		payment.Authorize(event.Reference)
+		return u.applyAuthorization(ctx, payment)
+
+	case domain.PaymentEventDeclined:
+		if !payment.IsUnsettled() {
+			return nil
+		}
+		payment.Decline(event.Reason)
+		_, err := u.paymentRepo.Update(ctx, payment)
+		return err
+
+	case domain.PaymentEventCaptured:
+		if payment.IsUnsettled() {
+			payment.Authorize(event.Reference)
+		}
+		if !payment.IsAuthorized() {
+			return nil
+		}
+		payment.Capture()
+		_, err := u.paymentRepo.Update(ctx, payment)
+		if errors.Is(err, domain.ErrPaymentExists) {
+			log.Printf("Payment %d was captured while order %d was paid otherwise and needs a refund", payment.ID, payment.OrderID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return u.applyCapture(ctx, payment)
+
+	case domain.PaymentEventVoided:
+		if !payment.IsAuthorized() {
+			return nil
+		}
+		payment.Void()
+		_, err := u.paymentRepo.Update(ctx, payment)
+		return err
+
+	case domain.PaymentEventRefunded:
+		// The provider reports the total refunded so far, so a refund
+		// already recorded on approval is not counted again
+		return recordRefunded(ctx, u.paymentRepo, payment.ID, event.Amount)
+
+	default:
+		log.Printf("Ignoring payment event %s of unknown type %s", event.ID, event.Type)
+		return nil
+	}
+}
+
+// recordRefunded records the total refunded on a captured payment with a
+// conditional update. When another refund of the payment got there first,
+// it rereads the payment and tries again.
+func recordRefunded(ctx context.Context, paymentRepo domain.PaymentRepository, paymentID uint, total float64) error {
+	for {
+		payment, err := paymentRepo.FindByID(ctx, paymentID)
+		if err != nil {
+			return err
+		}
+		if !payment.IsCaptured() {
+			return nil
+		}
+
+		refunded := payment
+		refunded.RecordRefunded(total)
+		if refunded == payment {
+			return nil
+		}
+
+		err = paymentRepo.UpdateRefund(ctx, payment, refunded)
+		if !errors.Is(err, domain.ErrPaymentChanged) {
+			return err
+		}
+	}
+}
+
+// applyAuthorization records a late authorization, e.g. one whose request
+// timed out. It is voided right away if the order no longer needs it.
+func (u *OrderUsecase) applyAuthorization(ctx context.Context, payment domain.Payment) error {
+	// The authorization is not recorded if another payment holds the order
+	updated, err := u.paymentRepo.Update(ctx, payment)
+	paidOtherwise := errors.Is(err, domain.ErrPaymentExists)
+	if err != nil && !paidOtherwise {
+		return err
+	}
+	if !paidOtherwise {
+		payment = updated
+	}
+
+	order, err := u.orderRepo.FindByID(ctx, payment.OrderID)
+	if err != nil && !errors.Is(err, domain.ErrOrderNotFound) {
+		return err
+	}
+	if err != nil || !order.IsPending() || paidOtherwise {
+		log.Printf("Voiding late authorization of payment %d for order %d", payment.ID, payment.OrderID)
+		u.voidPayment(ctx, payment)
+	}
+
+	return nil
+}
+
+// applyCapture takes the stock of a pending order whose payment the
+// provider reported captured, making the order paid.
+func (u *OrderUsecase) applyCapture(ctx context.Context, payment domain.Payment) error {
+	order, err := u.orderRepo.FindByID(ctx, payment.OrderID)
+	if errors.Is(err, domain.ErrOrderNotFound) {
+		log.Printf("Payment %d was captured for deleted order %d and needs a refund", payment.ID, payment.OrderID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !order.IsPending() {
+		if !order.WasPaid() {
+			log.Printf("Payment %d was captured for %s order %d and needs a refund", payment.ID, order.Status, order.ID)
+		}
+		return nil
+	}
+
+	_, err = u.CapturePayment(ctx, order.ID)
+	if errors.Is(err, domain.ErrOrderNotPending) {
+		// The reservation expired while the capture was on its way
+		log.Printf("Payment %d was captured but order %d no longer holds its stock", payment.ID, order.ID)
+		return nil
+	}
+	return err
+}
+
+// Complete completes a paid order, or a shipped order once it is delivered.
+func (u *OrderUsecase) Complete(ctx context.Context, id uint) (OrderOutput, error) {
+	order, err := u.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	// Business rule: an order is completed only once its payment is captured
+	if order.IsPending() {
+		return OrderOutput{}, domain.ErrPaymentRequired
+	}
+	// Business rule: a shipped order is completed only once delivered
+	if order.IsInTransit() {
+		return OrderOutput{}, domain.ErrOrderNotDelivered
+	}
+	if !order.IsCompletable() {
+		return OrderOutput{}, domain.ErrOrderNotPending
+	}
+
+	payment, err := u.activePayment(ctx, order.ID)
+	if errors.Is(err, domain.ErrPaymentNotFound) || err == nil && !payment.IsCaptured() {
+		return OrderOutput{}, domain.ErrPaymentRequired
+	}
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	// Only the status moves, so a refund recorded meanwhile is kept
+	from := order.Status
+	order.Complete()
+	if err := u.orderRepo.UpdateStatus(ctx, order.ID, from, order.Status); err != nil {
+		return OrderOutput{}, err
+	}
+
+	return toOrderOutput(order), nil
+}
+
+// Cancel cancels a pending or backordered order and releases its reserved stock.
+func (u *OrderUsecase) Cancel(ctx context.Context, id uint) (OrderOutput, error) {
+	order, err := u.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return OrderOutput{}, err
+	}
+
+	// Business rule: only open orders can be cancelled
+	if !order.IsOpen() {
+		return OrderOutput{}, domain.ErrOrderNotPending
+	}
+
+	return u.cancel(ctx, order)
+}
+
+// ExpireReservations cancels pending orders whose reservation expired at or
+// before now and returns how many were cancelled. A reservation that fails
+// is logged and retried on the next run.
+func (u *OrderUsecase) ExpireReservations(ctx context.Context, now time.Time) (int, error) {
+	ctx = withActor(ctx, systemActor)
+
+	reservations, err := u.reservationRepo.FindExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int
+	for _, reservation := range reservations {
+		order, err := u.orderRepo.FindByID(ctx, reservation.OrderID)
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound), err == nil && !order.IsPending():
+			// Nothing to cancel, but the stock is still held
+			err = u.releaseReservation(ctx, reservation.OrderID)
+			if errors.Is(err, domain.ErrReservationNotFound) {
+				err = nil
+			}
+		case err == nil:
+			_, err = u.cancel(ctx, order)
+			if err == nil {
+				expired++
+			}
+		}
+		// A checkout or cancellation that got there first is not a failure
+		if err != nil && !errors.Is(err, domain.ErrOrderStatusChanged) {
+			log.Printf("Failed to expire reservation of order %d: %v", reservation.OrderID, err)
+		}
+	}
+
+	return expired, nil
+}
+
+// cancel marks an open order cancelled, releases its reservation and
+// voids its authorized payment. The freed stock, or the freed place in the
+// queue, goes to the book's backorders. It fails with ErrOrderStatusChanged
+// if a concurrent request paid, allocated or cancelled the order first.
+func (u *OrderUsecase) cancel(ctx context.Context, order domain.Order) (OrderOutput, error) {
+	from := order.Status
+
+	// Claim the order so a concurrent allocation or cancellation cannot race it
+	if err := u.orderRepo.UpdateStatus(ctx, order.ID, from, domain.OrderStatusCancelled); err != nil {
+		return OrderOutput{}, err
+	}
+
+	// Pending orders hold a reservation; checkout claims it before taking
+	// the money, so a missing one means the order is being paid
+	if from == domain.OrderStatusPending {
+		err := u.releaseReservation(ctx, order.ID)
+		if errors.Is(err, domain.ErrReservationNotFound) {
+			err = domain.ErrOrderStatusChanged
+		}
+		if err != nil {
+			u.reopen(ctx, order.ID, from)
+			return OrderOutput{}, err
+		}
+	}
+	order.Cancel()
+
+	u.voidPayments(ctx, order.ID)
+
+	u.releasePromotion(ctx, order)
+
+	u.allocateBackorders(ctx, order.BookID)
+
+	return toOrderOutput(order), nil
+}
+
+// reopen moves an order whose cancellation could not go through back to
+// the status it had. Failures are logged; checkout may already have moved
+// the order on.
+func (u *OrderUsecase) reopen(ctx context.Context, orderID uint, status string) {
+	err := u.orderRepo.UpdateStatus(ctx, orderID, domain.OrderStatusCancelled, status)
+	if err != nil && !errors.Is(err, domain.ErrOrderStatusChanged) {
+		log.Printf("Failed to reopen order %d: %v", orderID, err)
+	}
+}
+
+// pendingOrder finds an order that can still be paid.
+func (u *OrderUsecase) pendingOrder(ctx context.Context, id uint) (domain.Order, error) {
+	order, err := u.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	// Business rule: only pending orders can be paid
+	if !order.IsPending() {
+		return domain.Order{}, domain.ErrOrderNotPending
+	}
+
+	return order, nil
+}
+
+// claimReservation consumes an order's reservation so nobody else can.
+// It fails with ErrReservationNotFound if the order holds none.
+func (u *OrderUsecase) claimReservation(ctx context.Context, orderID uint) (domain.Reservation, error) {
+	reservation, err := u.reservationRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return domain.Reservation{}, err
+	}
+
+	// Checkout, cancellation and the sweeper race for the same reservation
+	if err := u.reservationRepo.Delete(ctx, reservation.ID); err != nil {
+		return domain.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// releaseReservation consumes an order's reservation and returns its
+// stock. It fails with ErrReservationNotFound if the order holds none.
+func (u *OrderUsecase) releaseReservation(ctx context.Context, orderID uint) error {
+	reservation, err := u.claimReservation(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.releaseStock(ctx, reservation.OrderID, reservation.BookID, reservation.Quantity); err != nil {
+		u.restoreReservation(ctx, reservation)
+		return err
+	}
+
+	return nil
+}
+
+// restoreReservation puts back a reservation whose stock could not be committed.
+func (u *OrderUsecase) restoreReservation(ctx context.Context, reservation domain.Reservation) {
+	reservation.ID = 0
+	if _, err := u.reservationRepo.Save(ctx, reservation); err != nil {
+		log.Printf("Failed to restore reservation of order %d: %v", reservation.OrderID, err)
+	}
+}
+
+// userActor identifies a user as the actor of a stock movement.
+func userActor(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// orderReference identifies an order as the reference of a stock movement.
+func orderReference(orderID uint) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
+
 // FindByID finds an order by ID.
 func (u *OrderUsecase) FindByID(ctx context.Context, id uint) (OrderOutput, error) {
 	order, err := u.orderRepo.FindByID(ctx, id)
@@ -113,9 +1016,42 @@ func (u *OrderUsecase) FindByUserID(ctx context.Context, userID uint) ([]OrderOu
 	return outputs, nil
 }
 
-// FindAll returns all orders.
-func (u *OrderUsecase) FindAll(ctx context.Context) ([]OrderOutput, error) {
-	orders, err := u.orderRepo.FindAll(ctx)
+// FindAll returns a page of orders and how many orders there are in all.
+func (u *OrderUsecase) FindAll(ctx context.Context, page domain.Page) ([]OrderOutput, int64, error) {
+	orders, total, err := u.orderRepo.FindAll(ctx, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	outputs := make([]OrderOutput, len(orders))
+	for i, order := range orders {
+		outputs[i] = toOrderOutput(order)
+	}
+
+	return outputs, total, nil
+}
+
+// Delete soft deletes an order by ID.
+// An open order is cancelled first so its reserved stock is released.
+func (u *OrderUsecase) Delete(ctx context.Context, id uint) error {
+	// Check if order exists
+	order, err := u.orderRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if order.IsOpen() {
+		if _, err := u.cancel(ctx, order); err != nil {
+			return err
+		}
+	}
+
+	return u.orderRepo.Delete(ctx, id)
+}
+
+// FindDeleted returns all soft deleted orders.
+func (u *OrderUsecase) FindDeleted(ctx context.Context) ([]OrderOutput, error) {
+	orders, err := u.orderRepo.FindDeleted(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -128,14 +1064,57 @@ func (u *OrderUsecase) FindAll(ctx context.Context) ([]OrderOutput, error) {
 	return outputs, nil
 }
 
+// Restore restores a soft deleted order.
+func (u *OrderUsecase) Restore(ctx context.Context, id uint) (OrderOutput, error) {
+	if err := u.orderRepo.Restore(ctx, id); err != nil {
+		return OrderOutput{}, err
+	}
+
+	return u.FindByID(ctx, id)
+}
+
 // toOrderOutput converts domain.Order to OrderOutput.
 func toOrderOutput(order domain.Order) OrderOutput {
 	return OrderOutput{
-		ID:       order.ID,
-		UserID:   order.UserID,
-		BookID:   order.BookID,
-		Quantity: order.Quantity,
-		Total:    order.Total,
-		Status:   order.Status,
+		ID:              order.ID,
+		UserID:          order.UserID,
+		BookID:          order.BookID,
+		Quantity:        order.Quantity,
+		UnitPrice:       order.UnitPrice,
+		Subtotal:        order.Subtotal,
+		Tax:             order.Tax,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingCost:    order.ShippingCost,
+		Total:           order.Total,
+		Discount:        order.Discount,
+		PromotionCode:   order.PromotionCode,
+		RefundedAmount:  order.RefundedAmount,
+		ShippingAddress: toOrderAddressFields(order.ShippingAddress),
+		BillingAddress:  toOrderAddressFields(order.BillingAddress),
+		Status:          order.Status,
+		DeletedAt:       order.DeletedAt,
+	}
+}
+
+// toOrderAddressFields converts an order's address to AddressFields.
+func toOrderAddressFields(snapshot *domain.AddressSnapshot) *AddressFields {
+	if snapshot == nil {
+		return nil
+	}
+	fields := toAddressFields(*snapshot)
+	return &fields
+}
+
+// toPaymentOutput converts domain.Payment to PaymentOutput.
+func toPaymentOutput(payment domain.Payment) PaymentOutput {
+	return PaymentOutput{
+		ID:             payment.ID,
+		OrderID:        payment.OrderID,
+		Amount:         payment.Amount,
+		Status:         payment.Status,
+		Reference:      payment.Reference,
+		FailureReason:  payment.FailureReason,
+		RefundedAmount: payment.RefundedAmount,
+		CreatedAt:      payment.CreatedAt,
 	}
 }
@@ -0,0 +1,677 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestOrderUsecaseCreate(t *testing.T) {
+	tests := []struct {
+		name            string
+		stock           int
+		verified        bool
+		requireVerified bool
+		quantity        int
+		unknownUser     bool
+		unknownBook     bool
+		wantErr         error
+		// wantAvailable is the unreserved stock left afterwards
+		wantAvailable int
+	}{
+		{name: "valid", stock: 5, verified: true, quantity: 2, wantAvailable: 3},
+		{name: "exact stock", stock: 2, verified: true, quantity: 2, wantAvailable: 0},
+		{name: "insufficient stock", stock: 1, verified: true, quantity: 2, wantErr: domain.ErrInsufficientStock, wantAvailable: 1},
+		{name: "zero quantity", stock: 5, verified: true, quantity: 0, wantErr: domain.ErrInvalidQuantity, wantAvailable: 5},
+		{name: "unknown user", stock: 5, quantity: 1, unknownUser: true, wantErr: domain.ErrUserNotFound, wantAvailable: 5},
+		{name: "unknown book", stock: 5, quantity: 1, unknownBook: true, wantErr: domain.ErrBookNotFound, wantAvailable: 5},
+		{name: "unverified allowed", stock: 5, quantity: 1, wantAvailable: 4},
+		{name: "unverified blocked", stock: 5, quantity: 1, requireVerified: true, wantErr: domain.ErrEmailNotVerified, wantAvailable: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture()
+			uc := f.orderUsecase(usecase.OrderConfig{RequireVerifiedEmail: tt.requireVerified})
+			book := f.book(t, 12.5, tt.stock)
+			user := f.user(t, "a@example.com", tt.verified)
+
+			input := usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: tt.quantity}
+			if tt.unknownUser {
+				input.UserID = 999
+			}
+			if tt.unknownBook {
+				input.BookID = 999
+			}
+
+			output, err := uc.Create(ctx, input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+
+			// Stock stays on hand until the order is completed
+			current, _ := f.books.FindByID(ctx, book.ID)
+			if current.Stock != tt.stock || current.Available() != tt.wantAvailable {
+				t.Fatalf("expected stock %d with %d available, got %+v", tt.stock, tt.wantAvailable, current)
+			}
+
+			if tt.wantErr != nil {
+				// Rejected orders are not left behind
+				if orders, _, _ := f.orders.FindAll(ctx, domain.Page{}); len(orders) != 0 {
+					t.Fatalf("expected no orders, got %+v", orders)
+				}
+				return
+			}
+			if output.Status != domain.OrderStatusPending {
+				t.Fatalf("expected pending order, got %s", output.Status)
+			}
+			if output.Total != 12.5*float64(tt.quantity) {
+				t.Fatalf("unexpected total %v", output.Total)
+			}
+		})
+	}
+}
+
+func TestOrderUsecaseCreateConcurrentOrdersNeverOversell(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	const workers = 10
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			_, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+			errs <- err
+		}()
+	}
+
+	var created int
+	for i := 0; i < workers; i++ {
+		if err := <-errs; err == nil {
+			created++
+		}
+	}
+
+	current, _ := f.books.FindByID(ctx, book.ID)
+	if created != 5 || current.Available() != 0 {
+		t.Fatalf("expected 5 orders and nothing available, got %d orders and %+v", created, current)
+	}
+}
+
+func TestOrderUsecaseCompleteTakesReservedStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Reserving stock is not a stock movement
+	if movements, _ := f.stockMovements.FindByBookID(ctx, book.ID); len(movements) != 1 {
+		t.Fatalf("expected only the initial movement, got %+v", movements)
+	}
+
+	// Business rule: unpaid orders cannot be completed
+	if _, err := uc.Complete(ctx, order.ID); !errors.Is(err, domain.ErrPaymentRequired) {
+		t.Fatalf("expected ErrPaymentRequired, got %v", err)
+	}
+
+	f.pay(t, uc, order.ID)
+	completed, err := uc.Complete(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if completed.Status != domain.OrderStatusCompleted {
+		t.Fatalf("expected completed order, got %s", completed.Status)
+	}
+
+	current, _ := f.books.FindByID(ctx, book.ID)
+	if current.Stock != 3 || current.Reserved != 0 {
+		t.Fatalf("expected stock 3 with nothing reserved, got %+v", current)
+	}
+
+	movements, _ := f.stockMovements.FindByBookID(ctx, book.ID)
+	if len(movements) != 2 {
+		t.Fatalf("expected initial and order movements, got %+v", movements)
+	}
+	got := movements[1]
+	if got.Reason != domain.StockReasonOrder || got.Delta != -2 || got.Balance != 3 ||
+		got.Actor != fmt.Sprintf("user:%d", user.ID) || got.Reference != fmt.Sprintf("order:%d", order.ID) {
+		t.Fatalf("unexpected movement: %+v", got)
+	}
+
+	if _, err := uc.Complete(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+	if _, err := uc.Cancel(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+}
+
+func TestOrderUsecaseCancelReleasesStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 5})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1}); !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("expected reserved stock to be unavailable, got %v", err)
+	}
+
+	cancelled, err := uc.Cancel(ctx, order.ID)
+	if err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if cancelled.Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected cancelled order, got %s", cancelled.Status)
+	}
+
+	current, _ := f.books.FindByID(ctx, book.ID)
+	if current.Stock != 5 || current.Available() != 5 {
+		t.Fatalf("expected all stock available again, got %+v", current)
+	}
+	if _, err := uc.Complete(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+}
+
+func TestOrderUsecaseExpireReservations(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{ReservationTTL: time.Minute})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	stale, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	paid, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	f.pay(t, uc, paid.ID)
+	if _, err := uc.Complete(ctx, paid.ID); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if expired, err := uc.ExpireReservations(ctx, time.Now()); err != nil || expired != 0 {
+		t.Fatalf("expected nothing to expire yet, got %d, %v", expired, err)
+	}
+
+	expired, err := uc.ExpireReservations(ctx, time.Now().Add(2*time.Minute))
+	if err != nil || expired != 1 {
+		t.Fatalf("expected 1 expired order, got %d, %v", expired, err)
+	}
+
+	if order, _ := uc.FindByID(ctx, stale.ID); order.Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected stale order to be cancelled, got %s", order.Status)
+	}
+	if order, _ := uc.FindByID(ctx, paid.ID); order.Status != domain.OrderStatusCompleted {
+		t.Fatalf("expected paid order to stay completed, got %s", order.Status)
+	}
+
+	current, _ := f.books.FindByID(ctx, book.ID)
+	if current.Stock != 4 || current.Reserved != 0 {
+		t.Fatalf("expected stock 4 with nothing reserved, got %+v", current)
+	}
+	if _, err := uc.Complete(ctx, stale.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+}
+
+func TestOrderUsecaseDeleteReleasesPendingStock(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 3})
+	if err := uc.Delete(ctx, order.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if current, _ := f.books.FindByID(ctx, book.ID); current.Available() != 5 {
+		t.Fatalf("expected deleted order to release its stock, got %+v", current)
+	}
+}
+
+func TestOrderUsecaseCreateAlertsWhenCrossingReorderThreshold(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	user := f.user(t, "a@example.com", true)
+
+	book := domain.NewBook("Go", 10, 10)
+	book.ReorderThreshold = 5
+	book.ReorderQuantity = 20
+	book, err := f.books.Save(ctx, book, domain.NewInitialStock(""))
+	if err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+
+	// 10 -> 6 available stays above the threshold
+	if _, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 4}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if alerts := f.notifier.sent(); len(alerts) != 0 {
+		t.Fatalf("expected no alert yet, got %+v", alerts)
+	}
+
+	// 6 -> 4 crosses the threshold
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	alerts := f.notifier.sent()
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert, got %+v", alerts)
+	}
+	if alert := alerts[0]; alert.BookID != book.ID || alert.Available != 4 || alert.ReorderQuantity != 20 || alert.OrderID != order.ID {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+
+	// Already below the threshold, so no repeat alert
+	if _, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if alerts := f.notifier.sent(); len(alerts) != 1 {
+		t.Fatalf("expected no repeat alert, got %+v", alerts)
+	}
+}
+
+func TestOrderUsecaseCreateBackorders(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		setup      func(book *domain.Book)
+		wantErr    error
+		wantStatus string
+	}{
+		{name: "no backorders", setup: func(book *domain.Book) {}, wantErr: domain.ErrInsufficientStock},
+		{name: "backorders allowed", setup: func(book *domain.Book) { book.AllowBackorder = true }, wantStatus: domain.OrderStatusBackordered},
+		{name: "pre-order before release", setup: func(book *domain.Book) {
+			book.AllowPreorder = true
+			book.ReleaseDate = &future
+		}, wantStatus: domain.OrderStatusBackordered},
+		{name: "pre-order after release", setup: func(book *domain.Book) {
+			book.AllowPreorder = true
+			book.ReleaseDate = &past
+		}, wantErr: domain.ErrInsufficientStock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture()
+			uc := f.orderUsecase(usecase.OrderConfig{})
+			user := f.user(t, "a@example.com", true)
+
+			book := domain.NewBook("Go", 10, 1)
+			tt.setup(&book)
+			book, err := f.books.Save(ctx, book, domain.NewInitialStock(""))
+			if err != nil {
+				t.Fatalf("save book: %v", err)
+			}
+
+			output, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if output.Status != tt.wantStatus {
+				t.Fatalf("expected %s order, got %s", tt.wantStatus, output.Status)
+			}
+
+			// Backordered orders hold no stock
+			if current, _ := f.books.FindByID(ctx, book.ID); current.Reserved != 0 {
+				t.Fatalf("expected nothing reserved, got %+v", current)
+			}
+			if _, err := f.reservations.FindByOrderID(ctx, output.ID); !errors.Is(err, domain.ErrReservationNotFound) {
+				t.Fatalf("expected no reservation, got %v", err)
+			}
+			if _, err := uc.Complete(ctx, output.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+				t.Fatalf("expected ErrOrderNotPending, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderUsecaseAllocateBackordersInOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	inventory := f.inventoryUsecase()
+	user := f.user(t, "a@example.com", true)
+
+	book := domain.NewBook("Go", 10, 0)
+	book.AllowBackorder = true
+	book, err := f.books.Save(ctx, book, domain.NewInitialStock(""))
+	if err != nil {
+		t.Fatalf("save book: %v", err)
+	}
+
+	var orders []usecase.OrderOutput
+	for _, quantity := range []int{2, 3, 1} {
+		order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: quantity})
+		if err != nil || order.Status != domain.OrderStatusBackordered {
+			t.Fatalf("Create: %+v, %v", order, err)
+		}
+		orders = append(orders, order)
+	}
+
+	status := func(order usecase.OrderOutput) string {
+		t.Helper()
+		current, err := uc.FindByID(ctx, order.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		return current.Status
+	}
+
+	// 4 copies serve the first order; the second does not fit and the
+	// third must wait behind it
+	output, err := inventory.Adjust(ctx, usecase.AdjustStockInput{BookID: book.ID, Delta: 4, Reason: domain.StockReasonRestock})
+	if err != nil {
+		t.Fatalf("Adjust: %v", err)
+	}
+	if output.Reserved != 2 || output.Available != 2 {
+		t.Fatalf("expected 2 reserved and 2 available, got %+v", output)
+	}
+	if status(orders[0]) != domain.OrderStatusPending || status(orders[1]) != domain.OrderStatusBackordered || status(orders[2]) != domain.OrderStatusBackordered {
+		t.Fatalf("expected only the first order to be allocated")
+	}
+
+	// New orders queue behind the waiting ones even though stock is available
+	late, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if err != nil || late.Status != domain.OrderStatusBackordered {
+		t.Fatalf("expected late order to be backordered, got %+v, %v", late, err)
+	}
+
+	// Cancelling the second order frees the queue for the ones behind it
+	if _, err := uc.Cancel(ctx, orders[1].ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if status(orders[2]) != domain.OrderStatusPending || status(late) != domain.OrderStatusPending {
+		t.Fatalf("expected the remaining orders to be allocated")
+	}
+
+	current, _ := f.books.FindByID(ctx, book.ID)
+	if current.Stock != 4 || current.Reserved != 4 {
+		t.Fatalf("expected all 4 copies reserved, got %+v", current)
+	}
+	f.pay(t, uc, orders[2].ID)
+	if _, err := uc.Complete(ctx, orders[2].ID); err != nil {
+		t.Fatalf("Complete allocated order: %v", err)
+	}
+}
+
+func TestOrderUsecaseAllocatedBackorderOutlivesCheckoutReservations(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{ReservationTTL: 15 * time.Minute, BackorderReservationTTL: 72 * time.Hour})
+	inventory := usecase.NewInventoryUsecase(f.books, f.stockMovements, uc, f.pricingService())
+	user := f.user(t, "a@example.com", true)
+
+	book := domain.NewBook("Go", 10, 0)
+	book.AllowBackorder = true
+	book, _ = f.books.Save(ctx, book, domain.NewInitialStock(""))
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if err != nil || order.Status != domain.OrderStatusBackordered {
+		t.Fatalf("Create: %+v, %v", order, err)
+	}
+
+	if _, err := inventory.Adjust(ctx, usecase.AdjustStockInput{BookID: book.ID, Delta: 1, Reason: domain.StockReasonRestock}); err != nil {
+		t.Fatalf("Adjust: %v", err)
+	}
+	mails := f.mailer.sent()
+	if len(mails) != 1 || mails[0].To != "a@example.com" || !strings.Contains(mails[0].Body, "72h0m0s") {
+		t.Fatalf("expected the customer to be told to pay, got %+v", mails)
+	}
+
+	// The customer was not at checkout, so the sweeper leaves the order
+	// long after a checkout reservation would have expired
+	if expired, err := uc.ExpireReservations(ctx, time.Now().Add(time.Hour)); err != nil || expired != 0 {
+		t.Fatalf("expected no expiry, got %d, %v", expired, err)
+	}
+	if current, _ := uc.FindByID(ctx, order.ID); current.Status != domain.OrderStatusPending {
+		t.Fatalf("expected the allocated order to stay pending, got %s", current.Status)
+	}
+
+	// Left unpaid, the allocation goes to the next customer after all
+	if expired, err := uc.ExpireReservations(ctx, time.Now().Add(73*time.Hour)); err != nil || expired != 1 {
+		t.Fatalf("expected the allocation to expire, got %d, %v", expired, err)
+	}
+	if current, _ := uc.FindByID(ctx, order.ID); current.Status != domain.OrderStatusCancelled {
+		t.Fatalf("expected the unpaid order to be cancelled, got %s", current.Status)
+	}
+}
+
+func TestOrderUsecasePayment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, err := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID}); !errors.Is(err, domain.ErrInvalidPaymentToken) {
+		t.Fatalf("expected ErrInvalidPaymentToken, got %v", err)
+	}
+	if _, err := uc.CapturePayment(ctx, order.ID); !errors.Is(err, domain.ErrPaymentNotAuthorized) {
+		t.Fatalf("expected ErrPaymentNotAuthorized, got %v", err)
+	}
+
+	// Declined and timed out attempts are recorded and the order stays pending
+	f.gateway.Script(payment.FakeDecline, payment.FakeTimeout)
+	declined, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"})
+	if !errors.Is(err, domain.ErrPaymentDeclined) || declined.Status != domain.PaymentStatusDeclined {
+		t.Fatalf("expected declined payment, got %+v, %v", declined, err)
+	}
+	failed, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"})
+	if !errors.Is(err, domain.ErrPaymentTimeout) || failed.Status != domain.PaymentStatusFailed {
+		t.Fatalf("expected failed payment, got %+v, %v", failed, err)
+	}
+
+	authorized, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"})
+	if err != nil || authorized.Status != domain.PaymentStatusAuthorized || authorized.Amount != 20 || authorized.Reference == "" {
+		t.Fatalf("expected authorized payment of 20, got %+v, %v", authorized, err)
+	}
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"}); !errors.Is(err, domain.ErrPaymentExists) {
+		t.Fatalf("expected ErrPaymentExists, got %v", err)
+	}
+
+	// A failed capture keeps the stock reserved so it can be retried
+	f.gateway.Script(payment.FakeTimeout)
+	if _, err := uc.CapturePayment(ctx, order.ID); !errors.Is(err, domain.ErrPaymentTimeout) {
+		t.Fatalf("expected ErrPaymentTimeout, got %v", err)
+	}
+	if current, _ := f.books.FindByID(ctx, book.ID); current.Stock != 5 || current.Reserved != 2 {
+		t.Fatalf("expected stock to stay reserved, got %+v", current)
+	}
+
+	paid, err := uc.CapturePayment(ctx, order.ID)
+	if err != nil || paid.Status != domain.OrderStatusPaid {
+		t.Fatalf("expected paid order, got %+v, %v", paid, err)
+	}
+	if current, _ := f.books.FindByID(ctx, book.ID); current.Stock != 3 || current.Reserved != 0 {
+		t.Fatalf("expected stock 3 with nothing reserved, got %+v", current)
+	}
+
+	payments, err := uc.Payments(ctx, order.ID)
+	if err != nil || len(payments) != 3 {
+		t.Fatalf("expected 3 payment attempts, got %+v, %v", payments, err)
+	}
+	if payments[2].Status != domain.PaymentStatusCaptured {
+		t.Fatalf("expected captured payment, got %+v", payments[2])
+	}
+
+	// Paid orders wait to be completed and can no longer be cancelled
+	if _, err := uc.Cancel(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+	if completed, err := uc.Complete(ctx, order.ID); err != nil || completed.Status != domain.OrderStatusCompleted {
+		t.Fatalf("expected completed order, got %+v, %v", completed, err)
+	}
+}
+
+func TestOrderUsecaseCancelVoidsAuthorizedPayment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"}); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+
+	if _, err := uc.Cancel(ctx, order.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	payments, _ := uc.Payments(ctx, order.ID)
+	if len(payments) != 1 || payments[0].Status != domain.PaymentStatusVoided {
+		t.Fatalf("expected voided payment, got %+v", payments)
+	}
+	calls := f.gateway.Calls()
+	if last := calls[len(calls)-1]; last.Operation != "void" || last.Reference != payments[0].Reference {
+		t.Fatalf("expected the authorization to be voided at the provider, got %+v", calls)
+	}
+}
+
+// interleavedGateway runs a step while an authorization or a capture is
+// on its way to the provider.
+type interleavedGateway struct {
+	*payment.FakeGateway
+	duringAuthorize func()
+	duringCapture   func()
+	duringRefund    func()
+}
+
+func (g *interleavedGateway) Authorize(ctx context.Context, request domain.PaymentRequest) (domain.PaymentResult, error) {
+	if step := g.duringAuthorize; step != nil {
+		g.duringAuthorize = nil
+		step()
+	}
+	return g.FakeGateway.Authorize(ctx, request)
+}
+
+func (g *interleavedGateway) Capture(ctx context.Context, reference string, amount float64) (domain.PaymentResult, error) {
+	if step := g.duringCapture; step != nil {
+		g.duringCapture = nil
+		step()
+	}
+	return g.FakeGateway.Capture(ctx, reference, amount)
+}
+
+func (g *interleavedGateway) Refund(ctx context.Context, reference string, amount float64) (domain.PaymentResult, error) {
+	if step := g.duringRefund; step != nil {
+		g.duringRefund = nil
+		step()
+	}
+	return g.FakeGateway.Refund(ctx, reference, amount)
+}
+
+func TestOrderUsecaseCancelBacksOffFromACapture(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	gateway := &interleavedGateway{FakeGateway: f.gateway}
+	uc := usecase.NewOrderUsecase(f.orders, f.books, f.users, f.reservations, f.payments, f.promotions, f.addresses, f.pricingService(), f.taxes, f.shipping, gateway, f.notifier, f.mailer, usecase.OrderConfig{ReservationTTL: time.Hour})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	order, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"}); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+
+	// The customer cancels while checkout is capturing the payment
+	var cancelErr error
+	gateway.duringCapture = func() {
+		_, cancelErr = uc.Cancel(ctx, order.ID)
+	}
+	paid, err := uc.CapturePayment(ctx, order.ID)
+	if err != nil || paid.Status != domain.OrderStatusPaid {
+		t.Fatalf("expected the capture to pay the order, got %+v, %v", paid, err)
+	}
+	if !errors.Is(cancelErr, domain.ErrOrderStatusChanged) {
+		t.Fatalf("expected the cancellation to back off with ErrOrderStatusChanged, got %v", cancelErr)
+	}
+
+	if stored, _ := f.orders.FindByID(ctx, order.ID); stored.Status != domain.OrderStatusPaid {
+		t.Fatalf("expected the order to stay paid, got %s", stored.Status)
+	}
+	if payments, _ := uc.Payments(ctx, order.ID); len(payments) != 1 || payments[0].Status != domain.PaymentStatusCaptured {
+		t.Fatalf("expected the payment to stay captured, got %+v", payments)
+	}
+	if current, _ := f.books.FindByID(ctx, book.ID); current.Stock != 3 || current.Reserved != 0 {
+		t.Fatalf("expected the paid order to take its stock, got %+v", current)
+	}
+
+	// The order cancelled before checkout gets the reservation cannot be paid
+	order, _ = uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if _, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"}); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if _, err := uc.Cancel(ctx, order.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if _, err := uc.CapturePayment(ctx, order.ID); !errors.Is(err, domain.ErrOrderNotPending) {
+		t.Fatalf("expected ErrOrderNotPending, got %v", err)
+	}
+}
+
+func TestOrderUsecasePayRefusesAConcurrentPayment(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	gateway := &interleavedGateway{FakeGateway: f.gateway}
+	uc := usecase.NewOrderUsecase(f.orders, f.books, f.users, f.reservations, f.payments, f.promotions, f.addresses, f.pricingService(), f.taxes, f.shipping, gateway, f.notifier, f.mailer, usecase.OrderConfig{ReservationTTL: time.Hour})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+	order, _ := uc.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+
+	// The customer submits checkout twice; the second arrives mid-authorization
+	var secondErr error
+	gateway.duringAuthorize = func() {
+		_, secondErr = uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"})
+	}
+	first, err := uc.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"})
+	if err != nil || first.Status != domain.PaymentStatusAuthorized {
+		t.Fatalf("expected the first payment to be authorized, got %+v, %v", first, err)
+	}
+	if !errors.Is(secondErr, domain.ErrPaymentExists) {
+		t.Fatalf("expected the concurrent payment to fail with ErrPaymentExists, got %v", secondErr)
+	}
+
+	if calls := gateway.Calls(); len(calls) != 1 {
+		t.Fatalf("expected one authorization at the provider, got %v", calls)
+	}
+	if payments, _ := f.payments.FindByOrderID(ctx, order.ID); len(payments) != 1 {
+		t.Fatalf("expected one recorded payment, got %+v", payments)
+	}
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// minPasswordLength is the minimum length of a new password.
+const minPasswordLength = 8
+
+// PasswordUsecase handles password reset business logic.
+type PasswordUsecase struct {
+	userRepo  domain.UserRepository
+	resetRepo domain.PasswordResetRepository
+	mailer    domain.Mailer
+	resetURL  string
+	tokenTTL  time.Duration
+}
+
+// NewPasswordUsecase creates a new PasswordUsecase. Reset mails link to
+// resetURL, the page where users choose a new password, with the token
+// in its query.
+func NewPasswordUsecase(
+	userRepo domain.UserRepository,
+	resetRepo domain.PasswordResetRepository,
+	mailer domain.Mailer,
+	resetURL string,
+	tokenTTL time.Duration,
+) *PasswordUsecase {
+	return &PasswordUsecase{
+		userRepo:  userRepo,
+		resetRepo: resetRepo,
+		mailer:    mailer,
+		resetURL:  resetURL,
+		tokenTTL:  tokenTTL,
+	}
+}
+
+// ForgotPasswordInput is the input for requesting a password reset.
+type ForgotPasswordInput struct {
+	Email string
+}
+
+// ResetPasswordInput is the input for resetting a password.
+type ResetPasswordInput struct {
+	Token    string
+	Password string
+}
+
+// Forgot issues a reset token and mails it to the user.
+// It returns nil for unknown emails so callers cannot enumerate users.
+func (u *PasswordUsecase) Forgot(ctx context.Context, input ForgotPasswordInput) error {
+	user, err := u.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Business rule: only the latest token is valid
+	if err := u.resetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	reset := domain.NewPasswordReset(user.ID, hashToken(token), u.tokenTTL)
+	if _, err := u.resetRepo.Save(ctx, reset); err != nil {
+		return err
+	}
+
+	mail := domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. It expires in %s.\n\n%s?token=%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Name, u.tokenTTL, u.resetURL, token,
+		),
+	}
+
+	// Mail failures are logged, not returned, so the response is the same
+	// whether or not the email belongs to an account.
+	if err := u.mailer.Send(ctx, mail); err != nil {
+		log.Printf("Failed to send password reset mail: %v", err)
+	}
+
+	return nil
+}
+
+// Reset consumes a reset token and sets the new password.
+func (u *PasswordUsecase) Reset(ctx context.Context, input ResetPasswordInput) error {
+	// Business rule: password must be long enough
+	if len(input.Password) < minPasswordLength {
+		return domain.ErrInvalidPassword
+	}
+
+	reset, err := u.resetRepo.FindByTokenHash(ctx, hashToken(input.Token))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if reset.IsUsed() || reset.IsExpired(now) {
+		return domain.ErrInvalidResetToken
+	}
+
+	user, err := u.userRepo.FindByID(ctx, reset.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// Consume the token before changing the password so it cannot be replayed
+	if err := u.resetRepo.MarkUsed(ctx, reset.ID, now); err != nil {
+		return err
+	}
+
+	// In real app, hash password here
+	user.Password = input.Password
+	if _, err := u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return nil
+}
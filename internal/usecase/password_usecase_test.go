@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestPasswordUsecaseReset(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewPasswordUsecase(f.users, f.passwordResets, f.mailer, "http://localhost/reset-password", time.Hour)
+	user := f.user(t, "a@example.com", true)
+
+	if err := uc.Forgot(ctx, usecase.ForgotPasswordInput{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("expected nil for unknown email, got %v", err)
+	}
+	if len(f.mailer.sent()) != 0 {
+		t.Fatal("expected no mail for unknown email")
+	}
+
+	if err := uc.Forgot(ctx, usecase.ForgotPasswordInput{Email: user.Email}); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	body := f.mailer.sent()[0].Body
+	match := tokenPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatal("reset mail has no token")
+	}
+	token := match[1]
+	if !strings.Contains(body, "http://localhost/reset-password?token="+token) {
+		t.Fatalf("expected the reset page link in the mail, got %q", body)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		password string
+		wantErr  error
+	}{
+		{name: "short password", token: token, password: "short", wantErr: domain.ErrInvalidPassword},
+		{name: "unknown token", token: "nope", password: "newsecret123", wantErr: domain.ErrInvalidResetToken},
+		{name: "valid", token: token, password: "newsecret123"},
+		{name: "reused token", token: token, password: "another123", wantErr: domain.ErrInvalidResetToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uc.Reset(ctx, usecase.ResetPasswordInput{Token: tt.token, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	updated, _ := f.users.FindByID(ctx, user.ID)
+	if updated.Password != "newsecret123" {
+		t.Fatalf("expected password to be reset, got %q", updated.Password)
+	}
+}
+
+func TestPasswordUsecaseResetExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewPasswordUsecase(f.users, f.passwordResets, f.mailer, "http://localhost", -time.Minute)
+	user := f.user(t, "a@example.com", true)
+
+	if err := uc.Forgot(ctx, usecase.ForgotPasswordInput{Email: user.Email}); err != nil {
+		t.Fatalf("Forgot: %v", err)
+	}
+	token := tokenPattern.FindStringSubmatch(f.mailer.sent()[0].Body)[1]
+
+	err := uc.Reset(ctx, usecase.ResetPasswordInput{Token: token, Password: "newsecret123"})
+	if !errors.Is(err, domain.ErrInvalidResetToken) {
+		t.Fatalf("expected ErrInvalidResetToken, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PricingService works out what books cost from their price history and
+// schedules price changes and sales. Books and orders are both priced
+// through it, so customers pay the price they were shown.
+type PricingService struct {
+	priceRepo domain.BookPriceRepository
+	bookRepo  domain.BookRepository
+}
+
+// NewPricingService creates a new PricingService.
+func NewPricingService(priceRepo domain.BookPriceRepository, bookRepo domain.BookRepository) *PricingService {
+	return &PricingService{
+		priceRepo: priceRepo,
+		bookRepo:  bookRepo,
+	}
+}
+
+// SchedulePriceInput is the input for scheduling a price.
+type SchedulePriceInput struct {
+	BookID uint
+	Type   string
+	Price  float64
+	// EffectiveFrom is when the price takes effect; nil means now.
+	EffectiveFrom *time.Time
+	// EffectiveTo is when a sale ends. Regular prices run until the next
+	// regular price starts.
+	EffectiveTo *time.Time
+}
+
+// BookPriceOutput is the output for price history operations.
+type BookPriceOutput struct {
+	ID            uint
+	BookID        uint
+	Type          string
+	Price         float64
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+	CreatedAt     time.Time
+}
+
+// Quote returns what a book costs at a point in time.
+func (s *PricingService) Quote(ctx context.Context, book domain.Book, at time.Time) (domain.PriceQuote, error) {
+	quotes, err := s.Quotes(ctx, []domain.Book{book}, at)
+	if err != nil {
+		return domain.PriceQuote{}, err
+	}
+
+	return quotes[book.ID], nil
+}
+
+// Quotes returns what books cost at a point in time, keyed by book ID.
+func (s *PricingService) Quotes(ctx context.Context, books []domain.Book, at time.Time) (map[uint]domain.PriceQuote, error) {
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	prices, err := s.priceRepo.FindEffective(ctx, ids, at)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[uint]domain.PriceQuote, len(books))
+	for _, book := range books {
+		quotes[book.ID] = domain.QuotePrice(book, prices, at)
+	}
+
+	return quotes, nil
+}
+
+// Schedule adds a regular price change or a sale to a book's price history.
+// Prices cannot start in the past, so the history keeps explaining what
+// earlier orders cost.
+func (s *PricingService) Schedule(ctx context.Context, input SchedulePriceInput) (BookPriceOutput, error) {
+	// Business rule: price must be positive
+	if input.Price <= 0 {
+		return BookPriceOutput{}, domain.ErrInvalidPrice
+	}
+
+	now := time.Now()
+	from := now
+	if input.EffectiveFrom != nil {
+		from = *input.EffectiveFrom
+	}
+
+	switch input.Type {
+	case domain.BookPriceTypeRegular:
+		if input.EffectiveTo != nil {
+			return BookPriceOutput{}, domain.ErrInvalidPriceSchedule
+		}
+	case domain.BookPriceTypeSale:
+		if input.EffectiveTo == nil || !input.EffectiveTo.After(from) {
+			return BookPriceOutput{}, domain.ErrInvalidPriceSchedule
+		}
+	default:
+		return BookPriceOutput{}, domain.ErrInvalidPriceType
+	}
+
+	// Business rule: the past is not rewritten; allow for clock skew
+	if from.Before(now.Add(-time.Minute)) {
+		return BookPriceOutput{}, domain.ErrInvalidPriceSchedule
+	}
+
+	// Check if book exists
+	if _, err := s.bookRepo.FindByID(ctx, input.BookID); err != nil {
+		return BookPriceOutput{}, err
+	}
+
+	price := domain.NewBookPrice(input.BookID, input.Type, input.Price, from, input.EffectiveTo)
+	saved, err := s.schedule(ctx, price)
+	if err != nil {
+		return BookPriceOutput{}, err
+	}
+
+	return toBookPriceOutput(saved), nil
+}
+
+// History returns a book's price history, including scheduled prices,
+// ordered by when they take effect.
+func (s *PricingService) History(ctx context.Context, bookID uint) ([]BookPriceOutput, error) {
+	// Check if book exists
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.priceRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]BookPriceOutput, len(prices))
+	for i, price := range prices {
+		outputs[i] = toBookPriceOutput(price)
+	}
+
+	return outputs, nil
+}
+
+// bookOutput prices a book and converts it to BookOutput.
+func (s *PricingService) bookOutput(ctx context.Context, book domain.Book) (BookOutput, error) {
+	quote, err := s.Quote(ctx, book, time.Now())
+	if err != nil {
+		return BookOutput{}, err
+	}
+
+	return toBookOutput(book, quote), nil
+}
+
+// bookOutputs prices books with one query and converts them to BookOutput values.
+func (s *PricingService) bookOutputs(ctx context.Context, books []domain.Book) ([]BookOutput, error) {
+	quotes, err := s.Quotes(ctx, books, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]BookOutput, len(books))
+	for i, book := range books {
+		outputs[i] = toBookOutput(book, quotes[book.ID])
+	}
+
+	return outputs, nil
+}
+
+// recordListPrice records a book's list price as its regular price from
+// now on. Failures are logged; until the history is fixed the book is
+// quoted at its list price.
+func (s *PricingService) recordListPrice(ctx context.Context, book domain.Book) {
+	price := domain.NewBookPrice(book.ID, domain.BookPriceTypeRegular, book.Price, time.Now(), nil)
+	if _, err := s.schedule(ctx, price); err != nil {
+		log.Printf("Failed to record price of book %d: %v", book.ID, err)
+	}
+}
+
+// schedule fits a price into its book's history. Sales may not overlap each
+// other. A regular price ends the one before it and runs until the next
+// one starts.
+func (s *PricingService) schedule(ctx context.Context, price domain.BookPrice) (domain.BookPrice, error) {
+	history, err := s.priceRepo.FindByBookID(ctx, price.BookID)
+	if err != nil {
+		return domain.BookPrice{}, err
+	}
+
+	if price.Type == domain.BookPriceTypeSale {
+		for _, existing := range history {
+			if existing.Type == domain.BookPriceTypeSale && existing.Overlaps(price) {
+				return domain.BookPrice{}, domain.ErrPriceOverlap
+			}
+		}
+		return s.priceRepo.Save(ctx, price)
+	}
+
+	var previous *domain.BookPrice
+	for i, existing := range history {
+		if existing.Type != domain.BookPriceTypeRegular {
+			continue
+		}
+		switch {
+		case existing.EffectiveFrom.Equal(price.EffectiveFrom):
+			return domain.BookPrice{}, domain.ErrPriceOverlap
+		case existing.EffectiveFrom.Before(price.EffectiveFrom):
+			previous = &history[i]
+		case price.EffectiveTo == nil:
+			// History is ordered, so this is the next regular price
+			next := existing.EffectiveFrom
+			price.EffectiveTo = &next
+		}
+	}
+
+	saved, err := s.priceRepo.Save(ctx, price)
+	if err != nil {
+		return domain.BookPrice{}, err
+	}
+
+	if previous != nil {
+		previous.EffectiveTo = &saved.EffectiveFrom
+		if _, err := s.priceRepo.Update(ctx, *previous); err != nil {
+			return domain.BookPrice{}, err
+		}
+	}
+
+	return saved, nil
+}
+
+// toBookPriceOutput converts domain.BookPrice to BookPriceOutput.
+func toBookPriceOutput(price domain.BookPrice) BookPriceOutput {
+	return BookPriceOutput{
+		ID:            price.ID,
+		BookID:        price.BookID,
+		Type:          price.Type,
+		Price:         price.Price,
+		EffectiveFrom: price.EffectiveFrom,
+		EffectiveTo:   price.EffectiveTo,
+		CreatedAt:     price.CreatedAt,
+	}
+}
@@ -0,0 +1,144 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestPricingServiceSchedulesRegularPrices(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	pricing := f.pricingService()
+	book := f.book(t, 10, 5)
+	now := time.Now()
+	nextWeek := now.Add(7 * 24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
+
+	// Books without history cost their list price
+	if quote, err := pricing.Quote(ctx, book, now); err != nil || quote.Price != 10 {
+		t.Fatalf("expected the list price, got %+v, %v", quote, err)
+	}
+
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeRegular, Price: 10}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeRegular, Price: 14, EffectiveFrom: &nextWeek}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	// A change slotted in between runs until the later one starts
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeRegular, Price: 12, EffectiveFrom: &tomorrow}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeRegular, Price: 13, EffectiveFrom: &tomorrow}); !errors.Is(err, domain.ErrPriceOverlap) {
+		t.Fatalf("expected ErrPriceOverlap for the same start, got %v", err)
+	}
+
+	for _, tc := range []struct {
+		at   time.Time
+		want float64
+	}{
+		{now.Add(time.Minute), 10},
+		{tomorrow.Add(time.Minute), 12},
+		{nextWeek.Add(time.Minute), 14},
+	} {
+		if quote, _ := pricing.Quote(ctx, book, tc.at); quote.Price != tc.want || quote.SaleEndsAt != nil {
+			t.Fatalf("expected %v at %v, got %+v", tc.want, tc.at, quote)
+		}
+	}
+
+	history, err := pricing.History(ctx, book.ID)
+	if err != nil || len(history) != 3 {
+		t.Fatalf("expected three prices, got %+v, %v", history, err)
+	}
+	if !history[0].EffectiveTo.Equal(tomorrow) || !history[1].EffectiveTo.Equal(nextWeek) || history[2].EffectiveTo != nil {
+		t.Fatalf("expected each price to end when the next starts, got %+v", history)
+	}
+}
+
+func TestPricingServiceSales(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	pricing := f.pricingService()
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	saleEnds := now.Add(time.Hour)
+
+	for _, tc := range []struct {
+		name  string
+		input usecase.SchedulePriceInput
+		want  error
+	}{
+		{"unknown type", usecase.SchedulePriceInput{BookID: book.ID, Type: "clearance", Price: 5}, domain.ErrInvalidPriceType},
+		{"no price", usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeSale, EffectiveTo: &saleEnds}, domain.ErrInvalidPrice},
+		{"open-ended sale", usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeSale, Price: 5}, domain.ErrInvalidPriceSchedule},
+		{"regular price with an end", usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeRegular, Price: 5, EffectiveTo: &saleEnds}, domain.ErrInvalidPriceSchedule},
+		{"in the past", usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeSale, Price: 5, EffectiveFrom: &past, EffectiveTo: &saleEnds}, domain.ErrInvalidPriceSchedule},
+		{"unknown book", usecase.SchedulePriceInput{BookID: 999, Type: domain.BookPriceTypeSale, Price: 5, EffectiveTo: &saleEnds}, domain.ErrBookNotFound},
+	} {
+		if _, err := pricing.Schedule(ctx, tc.input); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeSale, Price: 8, EffectiveTo: &saleEnds}); err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if _, err := pricing.Schedule(ctx, usecase.SchedulePriceInput{BookID: book.ID, Type: domain.BookPriceTypeSale, Price: 6, EffectiveTo: &saleEnds}); !errors.Is(err, domain.ErrPriceOverlap) {
+		t.Fatalf("expected ErrPriceOverlap, got %v", err)
+	}
+
+	// The sale overrides the regular price until it ends
+	quote, _ := pricing.Quote(ctx, book, now.Add(time.Minute))
+	if quote.Price != 8 || quote.RegularPrice != 10 || quote.SaleEndsAt == nil || !quote.SaleEndsAt.Equal(saleEnds) {
+		t.Fatalf("expected the sale price, got %+v", quote)
+	}
+	if quote, _ := pricing.Quote(ctx, book, saleEnds); quote.Price != 10 {
+		t.Fatalf("expected the regular price once the sale ends, got %+v", quote)
+	}
+
+	order, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 3})
+	if err != nil || order.UnitPrice != 8 || order.Total != 24 {
+		t.Fatalf("expected the order at the sale price, got %+v, %v", order, err)
+	}
+}
+
+func TestBookUsecaseRecordsPriceChanges(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	books := f.bookUsecase()
+	pricing := f.pricingService()
+
+	created, err := books.Create(ctx, usecase.CreateBookInput{Title: "Go", Price: 10, Stock: 1})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Only price changes are recorded
+	update := usecase.UpdateBookInput{ID: created.ID, Title: "Go, 2nd edition", Price: 10, Version: created.Version}
+	updated, err := books.Update(ctx, update)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	update.Price = 11
+	update.Version = updated.Version
+	updated, err = books.Update(ctx, update)
+	if err != nil || updated.Price != 11 || updated.RegularPrice != 11 {
+		t.Fatalf("expected the new price, got %+v, %v", updated, err)
+	}
+
+	history, err := pricing.History(ctx, created.ID)
+	if err != nil || len(history) != 2 || history[0].Price != 10 || history[1].Price != 11 {
+		t.Fatalf("expected the old and new price, got %+v, %v", history, err)
+	}
+	if history[0].EffectiveTo == nil || !history[0].EffectiveTo.Equal(history[1].EffectiveFrom) {
+		t.Fatalf("expected the old price to end when the new one starts, got %+v", history[0])
+	}
+}
@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PromotionUsecase handles managing promotions. Promotions are applied to
+// orders by OrderUsecase.
+type PromotionUsecase struct {
+	promotionRepo domain.PromotionRepository
+	bookRepo      domain.BookRepository
+}
+
+// NewPromotionUsecase creates a new PromotionUsecase.
+func NewPromotionUsecase(promotionRepo domain.PromotionRepository, bookRepo domain.BookRepository) *PromotionUsecase {
+	return &PromotionUsecase{
+		promotionRepo: promotionRepo,
+		bookRepo:      bookRepo,
+	}
+}
+
+// CreatePromotionInput is the input for creating a promotion.
+type CreatePromotionInput struct {
+	Code         string
+	Description  string
+	Type         string
+	Value        float64
+	MinSpend     float64
+	UsageLimit   int
+	PerUserLimit int
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	BookID       *uint
+	Category     string
+}
+
+// UpdatePromotionInput is the input for updating a promotion.
+type UpdatePromotionInput struct {
+	ID           uint
+	Code         string
+	Description  string
+	Type         string
+	Value        float64
+	MinSpend     float64
+	UsageLimit   int
+	PerUserLimit int
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	BookID       *uint
+	Category     string
+}
+
+// PromotionOutput is the output for promotion operations.
+type PromotionOutput struct {
+	ID           uint
+	Code         string
+	Description  string
+	Type         string
+	Value        float64
+	MinSpend     float64
+	UsageLimit   int
+	PerUserLimit int
+	UsedCount    int
+	StartsAt     *time.Time
+	EndsAt       *time.Time
+	BookID       *uint
+	Category     string
+	CreatedAt    time.Time
+}
+
+// Create creates a new promotion with validation.
+func (u *PromotionUsecase) Create(ctx context.Context, input CreatePromotionInput) (PromotionOutput, error) {
+	now := time.Now()
+	promotion := domain.Promotion{
+		Code:         domain.NormalizePromotionCode(input.Code),
+		Description:  input.Description,
+		Type:         input.Type,
+		Value:        input.Value,
+		MinSpend:     input.MinSpend,
+		UsageLimit:   input.UsageLimit,
+		PerUserLimit: input.PerUserLimit,
+		StartsAt:     input.StartsAt,
+		EndsAt:       input.EndsAt,
+		BookID:       input.BookID,
+		Category:     input.Category,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	if err := u.validate(ctx, promotion); err != nil {
+		return PromotionOutput{}, err
+	}
+
+	saved, err := u.promotionRepo.Save(ctx, promotion)
+	if err != nil {
+		return PromotionOutput{}, err
+	}
+
+	return toPromotionOutput(saved), nil
+}
+
+// FindByID finds a promotion by ID.
+func (u *PromotionUsecase) FindByID(ctx context.Context, id uint) (PromotionOutput, error) {
+	promotion, err := u.promotionRepo.FindByID(ctx, id)
+	if err != nil {
+		return PromotionOutput{}, err
+	}
+
+	return toPromotionOutput(promotion), nil
+}
+
+// FindAll returns all promotions, oldest first.
+func (u *PromotionUsecase) FindAll(ctx context.Context) ([]PromotionOutput, error) {
+	promotions, err := u.promotionRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]PromotionOutput, len(promotions))
+	for i, promotion := range promotions {
+		outputs[i] = toPromotionOutput(promotion)
+	}
+
+	return outputs, nil
+}
+
+// Update updates a promotion with validation. Orders that already used the
+// promotion keep the discount they got.
+func (u *PromotionUsecase) Update(ctx context.Context, input UpdatePromotionInput) (PromotionOutput, error) {
+	// Check if promotion exists
+	promotion, err := u.promotionRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return PromotionOutput{}, err
+	}
+
+	promotion.Code = domain.NormalizePromotionCode(input.Code)
+	promotion.Description = input.Description
+	promotion.Type = input.Type
+	promotion.Value = input.Value
+	promotion.MinSpend = input.MinSpend
+	promotion.UsageLimit = input.UsageLimit
+	promotion.PerUserLimit = input.PerUserLimit
+	promotion.StartsAt = input.StartsAt
+	promotion.EndsAt = input.EndsAt
+	promotion.BookID = input.BookID
+	promotion.Category = input.Category
+	promotion.UpdatedAt = time.Now()
+
+	if err := u.validate(ctx, promotion); err != nil {
+		return PromotionOutput{}, err
+	}
+
+	updated, err := u.promotionRepo.Update(ctx, promotion)
+	if err != nil {
+		return PromotionOutput{}, err
+	}
+
+	return toPromotionOutput(updated), nil
+}
+
+// Delete deletes a promotion. Orders that used it keep their discount and code.
+func (u *PromotionUsecase) Delete(ctx context.Context, id uint) error {
+	return u.promotionRepo.Delete(ctx, id)
+}
+
+// validate checks a promotion and that the book it targets exists.
+func (u *PromotionUsecase) validate(ctx context.Context, promotion domain.Promotion) error {
+	if err := promotion.Validate(); err != nil {
+		return err
+	}
+
+	if promotion.BookID != nil {
+		if _, err := u.bookRepo.FindByID(ctx, *promotion.BookID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toPromotionOutput converts domain.Promotion to PromotionOutput.
+func toPromotionOutput(promotion domain.Promotion) PromotionOutput {
+	return PromotionOutput{
+		ID:           promotion.ID,
+		Code:         promotion.Code,
+		Description:  promotion.Description,
+		Type:         promotion.Type,
+		Value:        promotion.Value,
+		MinSpend:     promotion.MinSpend,
+		UsageLimit:   promotion.UsageLimit,
+		PerUserLimit: promotion.PerUserLimit,
+		UsedCount:    promotion.UsedCount,
+		StartsAt:     promotion.StartsAt,
+		EndsAt:       promotion.EndsAt,
+		BookID:       promotion.BookID,
+		Category:     promotion.Category,
+		CreatedAt:    promotion.CreatedAt,
+	}
+}
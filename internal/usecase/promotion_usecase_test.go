@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestOrderUsecaseAppliesPromotions(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	promotions := f.promotionUsecase()
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 20)
+	other := f.book(t, 10, 20)
+	user := f.user(t, "a@example.com", true)
+
+	if _, err := promotions.Create(ctx, usecase.CreatePromotionInput{Code: " tenoff ", Type: domain.PromotionTypeFixed, Value: 50, BookID: &book.ID}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := promotions.Create(ctx, usecase.CreatePromotionInput{Code: "QUARTER", Type: domain.PromotionTypePercentage, Value: 25}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Fixed discounts never exceed the order total
+	order, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 3, PromotionCode: "TENOFF"})
+	if err != nil || order.Total != 0 || order.Discount != 30 || order.PromotionCode != "TENOFF" {
+		t.Fatalf("expected a free order, got %+v, %v", order, err)
+	}
+	if _, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: other.ID, Quantity: 1, PromotionCode: "TENOFF"}); !errors.Is(err, domain.ErrPromotionNotApplicable) {
+		t.Fatalf("expected ErrPromotionNotApplicable for another book, got %v", err)
+	}
+
+	order, err = orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: other.ID, Quantity: 3, PromotionCode: "quarter"})
+	if err != nil || order.Total != 22.5 || order.Discount != 7.5 {
+		t.Fatalf("expected a quarter off, got %+v, %v", order, err)
+	}
+	stored, _ := f.orders.FindByID(ctx, order.ID)
+	if stored.Discount != 7.5 || stored.PromotionCode != "QUARTER" {
+		t.Fatalf("expected the discount to be recorded on the order, got %+v", stored)
+	}
+
+	if _, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1, PromotionCode: "NOPE"}); !errors.Is(err, domain.ErrPromotionNotFound) {
+		t.Fatalf("expected ErrPromotionNotFound, got %v", err)
+	}
+}
+
+func TestOrderUsecasePromotionLimits(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	promotions := f.promotionUsecase()
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	book := f.book(t, 10, 20)
+	first := f.user(t, "a@example.com", true)
+	second := f.user(t, "b@example.com", true)
+
+	ended := time.Now().Add(-time.Hour)
+	if _, err := promotions.Create(ctx, usecase.CreatePromotionInput{Code: "OLD", Type: domain.PromotionTypeFixed, Value: 1, EndsAt: &ended}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: first.ID, BookID: book.ID, Quantity: 1, PromotionCode: "OLD"}); !errors.Is(err, domain.ErrPromotionNotActive) {
+		t.Fatalf("expected ErrPromotionNotActive, got %v", err)
+	}
+
+	once, err := promotions.Create(ctx, usecase.CreatePromotionInput{Code: "ONCE", Type: domain.PromotionTypeFixed, Value: 1, UsageLimit: 1})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	used, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: first.ID, BookID: book.ID, Quantity: 1, PromotionCode: "ONCE"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// An order over the limit is discarded and holds no stock
+	if _, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: second.ID, BookID: book.ID, Quantity: 1, PromotionCode: "ONCE"}); !errors.Is(err, domain.ErrPromotionUsedUp) {
+		t.Fatalf("expected ErrPromotionUsedUp, got %v", err)
+	}
+	stocked, _ := f.books.FindByID(ctx, book.ID)
+	if stocked.Reserved != 1 {
+		t.Fatalf("expected only the first order to reserve stock, got %d", stocked.Reserved)
+	}
+
+	// Cancelling the order gives its use back
+	if _, err := orders.Cancel(ctx, used.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	if found, _ := promotions.FindByID(ctx, once.ID); found.UsedCount != 0 {
+		t.Fatalf("expected the cancelled order to release the promotion, got %d", found.UsedCount)
+	}
+	if _, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: second.ID, BookID: book.ID, Quantity: 1, PromotionCode: "ONCE"}); err != nil {
+		t.Fatalf("expected the released promotion to be usable, got %v", err)
+	}
+}
+
+func TestPromotionUsecaseValidates(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.promotionUsecase()
+	missing := uint(999)
+	starts := time.Now()
+
+	invalid := map[error]usecase.CreatePromotionInput{
+		domain.ErrInvalidPromotionCode:   {Code: " ", Type: domain.PromotionTypeFixed, Value: 1},
+		domain.ErrInvalidPromotionType:   {Code: "A", Type: "bogo", Value: 1},
+		domain.ErrInvalidPromotionValue:  {Code: "A", Type: domain.PromotionTypePercentage, Value: 101},
+		domain.ErrInvalidPromotionLimits: {Code: "A", Type: domain.PromotionTypeFixed, Value: 1, PerUserLimit: -1},
+		domain.ErrInvalidPromotionWindow: {Code: "A", Type: domain.PromotionTypeFixed, Value: 1, StartsAt: &starts, EndsAt: &starts},
+		domain.ErrBookNotFound:           {Code: "A", Type: domain.PromotionTypeFixed, Value: 1, BookID: &missing},
+	}
+	for want, input := range invalid {
+		if _, err := uc.Create(ctx, input); !errors.Is(err, want) {
+			t.Fatalf("Create(%+v): expected %v, got %v", input, want, err)
+		}
+	}
+
+	created, _ := uc.Create(ctx, usecase.CreatePromotionInput{Code: "A", Type: domain.PromotionTypeFixed, Value: 1})
+	if _, err := uc.Create(ctx, usecase.CreatePromotionInput{Code: "a", Type: domain.PromotionTypeFixed, Value: 1}); !errors.Is(err, domain.ErrPromotionCodeExists) {
+		t.Fatalf("expected codes to be unique whatever their case, got %v", err)
+	}
+
+	updated, err := uc.Update(ctx, usecase.UpdatePromotionInput{ID: created.ID, Code: "b", Type: domain.PromotionTypePercentage, Value: 5})
+	if err != nil || updated.Code != "B" || updated.Type != domain.PromotionTypePercentage {
+		t.Fatalf("Update: %+v, %v", updated, err)
+	}
+	if err := uc.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := uc.Update(ctx, usecase.UpdatePromotionInput{ID: created.ID, Code: "B", Type: domain.PromotionTypeFixed, Value: 1}); !errors.Is(err, domain.ErrPromotionNotFound) {
+		t.Fatalf("expected ErrPromotionNotFound, got %v", err)
+	}
+}
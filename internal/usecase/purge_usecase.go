@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PurgeUsecase permanently removes soft deleted records after a retention period.
+type PurgeUsecase struct {
+	bookRepo  domain.BookRepository
+	userRepo  domain.UserRepository
+	orderRepo domain.OrderRepository
+}
+
+// NewPurgeUsecase creates a new PurgeUsecase.
+func NewPurgeUsecase(
+	bookRepo domain.BookRepository,
+	userRepo domain.UserRepository,
+	orderRepo domain.OrderRepository,
+) *PurgeUsecase {
+	return &PurgeUsecase{
+		bookRepo:  bookRepo,
+		userRepo:  userRepo,
+		orderRepo: orderRepo,
+	}
+}
+
+// PurgeOutput is the output for purge operations.
+type PurgeOutput struct {
+	Orders int64
+	Books  int64
+	Users  int64
+}
+
+// Purge permanently deletes records soft deleted longer than retention ago.
+func (u *PurgeUsecase) Purge(ctx context.Context, retention time.Duration) (PurgeOutput, error) {
+	before := time.Now().Add(-retention)
+
+	// Orders go first so books and users they referenced become purgeable
+	orders, err := u.orderRepo.Purge(ctx, before)
+	if err != nil {
+		return PurgeOutput{}, err
+	}
+
+	books, err := u.bookRepo.Purge(ctx, before)
+	if err != nil {
+		return PurgeOutput{}, err
+	}
+
+	users, err := u.userRepo.Purge(ctx, before)
+	if err != nil {
+		return PurgeOutput{}, err
+	}
+
+	return PurgeOutput{
+		Orders: orders,
+		Books:  books,
+		Users:  users,
+	}, nil
+}
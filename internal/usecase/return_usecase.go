@@ -0,0 +1,349 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// ReturnUsecase handles returns of completed orders and their refunds.
+type ReturnUsecase struct {
+	returnRepo  domain.ReturnRequestRepository
+	orderRepo   domain.OrderRepository
+	bookRepo    domain.BookRepository
+	paymentRepo domain.PaymentRepository
+	gateway     domain.PaymentGateway
+	allocator   BackorderAllocator
+}
+
+// NewReturnUsecase creates a new ReturnUsecase.
+func NewReturnUsecase(
+	returnRepo domain.ReturnRequestRepository,
+	orderRepo domain.OrderRepository,
+	bookRepo domain.BookRepository,
+	paymentRepo domain.PaymentRepository,
+	gateway domain.PaymentGateway,
+	allocator BackorderAllocator,
+) *ReturnUsecase {
+	return &ReturnUsecase{
+		returnRepo:  returnRepo,
+		orderRepo:   orderRepo,
+		bookRepo:    bookRepo,
+		paymentRepo: paymentRepo,
+		gateway:     gateway,
+		allocator:   allocator,
+	}
+}
+
+// RequestReturnInput is the input for requesting a return.
+type RequestReturnInput struct {
+	OrderID  uint
+	Quantity int
+	Reason   string
+}
+
+// ApproveReturnInput is the input for approving a return.
+type ApproveReturnInput struct {
+	ReturnID uint
+	// Condition is the state the copies arrived in; damaged copies are not restocked.
+	Condition string
+	// Amount is the amount to refund; zero refunds the returned copies'
+	// share of the order total without shipping.
+	Amount float64
+}
+
+// RejectReturnInput is the input for rejecting a return.
+type RejectReturnInput struct {
+	ReturnID uint
+	Reason   string
+}
+
+// ReturnOutput is the output for return operations.
+type ReturnOutput struct {
+	ID              uint
+	OrderID         uint
+	Quantity        int
+	Reason          string
+	Status          string
+	Condition       string
+	RefundAmount    float64
+	RejectionReason string
+	CreatedAt       time.Time
+}
+
+// Request asks to return copies of a completed order. Copies covered by
+// earlier returns that were not rejected cannot be returned again.
+func (u *ReturnUsecase) Request(ctx context.Context, input RequestReturnInput) (ReturnOutput, error) {
+	// Business rule: quantity must be positive
+	if input.Quantity <= 0 {
+		return ReturnOutput{}, domain.ErrInvalidQuantity
+	}
+
+	order, err := u.orderRepo.FindByID(ctx, input.OrderID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	// Business rule: only delivered orders can be returned
+	if !order.IsReturnable() {
+		return ReturnOutput{}, domain.ErrOrderNotReturnable
+	}
+
+	requests, err := u.returnRepo.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+	returned := 0
+	for _, request := range requests {
+		if !request.IsRejected() {
+			returned += request.Quantity
+		}
+	}
+	if returned+input.Quantity > order.Quantity {
+		return ReturnOutput{}, domain.ErrReturnQuantityExceeded
+	}
+
+	saved, err := u.returnRepo.Save(ctx, domain.NewReturnRequest(order.ID, input.Quantity, input.Reason))
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	return toReturnOutput(saved), nil
+}
+
+// Approve refunds a requested return through the payment gateway and
+// restocks its copies unless they arrived damaged. Nothing is changed
+// unless the provider refunds the money.
+func (u *ReturnUsecase) Approve(ctx context.Context, input ApproveReturnInput) (ReturnOutput, error) {
+	if err := domain.ValidateStockCondition(input.Condition); err != nil {
+		return ReturnOutput{}, err
+	}
+
+	request, err := u.requestedReturn(ctx, input.ReturnID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	order, err := u.orderRepo.FindByID(ctx, request.OrderID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	amount := input.Amount
+	if amount == 0 {
+		amount = order.RefundFor(request.Quantity)
+	}
+
+	// Check the refund against the order before any money moves
+	if err := order.Refund(amount); err != nil {
+		return ReturnOutput{}, err
+	}
+
+	payment, err := u.capturedPayment(ctx, order.ID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	// Hold the return while the provider refunds it, so a concurrent
+	// approval or rejection finds it already resolved
+	if err := u.returnRepo.UpdateStatus(ctx, request.ID, domain.ReturnStatusRequested, domain.ReturnStatusApproving); err != nil {
+		return ReturnOutput{}, err
+	}
+
+	result, err := u.gateway.Refund(ctx, payment.Reference, amount)
+	if err == nil && !result.Approved {
+		err = domain.ErrRefundDeclined
+	}
+	if err != nil {
+		u.release(ctx, request)
+		return ReturnOutput{}, err
+	}
+
+	// The money is back with the customer; record the return first so a
+	// retry cannot refund it twice
+	request.Approve(input.Condition, amount)
+	approved, err := u.returnRepo.Update(ctx, request)
+	if err != nil {
+		log.Printf("Return %d was refunded but could not be recorded: %v", request.ID, err)
+		return ReturnOutput{}, err
+	}
+
+	refunded, err := u.recordRefund(ctx, order.ID, amount)
+	if err != nil {
+		log.Printf("Return %d was refunded but its order %d could not be updated: %v", request.ID, order.ID, err)
+		return ReturnOutput{}, err
+	}
+
+	// The order's refunds all went to this payment; the provider's
+	// refunded webhook reports the same total and changes nothing
+	if err := recordRefunded(ctx, u.paymentRepo, payment.ID, refunded.RefundedAmount); err != nil {
+		log.Printf("Failed to record refund of payment %d: %v", payment.ID, err)
+	}
+
+	u.restock(ctx, order, approved)
+
+	return toReturnOutput(approved), nil
+}
+
+// Reject rejects a requested return.
+func (u *ReturnUsecase) Reject(ctx context.Context, input RejectReturnInput) (ReturnOutput, error) {
+	request, err := u.requestedReturn(ctx, input.ReturnID)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	// Claim the return so an approval racing this cannot refund it
+	if err := u.returnRepo.UpdateStatus(ctx, request.ID, domain.ReturnStatusRequested, domain.ReturnStatusRejected); err != nil {
+		return ReturnOutput{}, err
+	}
+
+	request.Reject(input.Reason)
+	updated, err := u.returnRepo.Update(ctx, request)
+	if err != nil {
+		return ReturnOutput{}, err
+	}
+
+	return toReturnOutput(updated), nil
+}
+
+// FindByOrderID returns an order's return requests, oldest first.
+func (u *ReturnUsecase) FindByOrderID(ctx context.Context, orderID uint) ([]ReturnOutput, error) {
+	// Check if order exists
+	if _, err := u.orderRepo.FindByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	requests, err := u.returnRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toReturnOutputs(requests), nil
+}
+
+// FindByStatus returns a page of the return requests in a status, oldest
+// first, and how many are in it in all. An empty status selects all of them.
+func (u *ReturnUsecase) FindByStatus(ctx context.Context, status string, page domain.Page) ([]ReturnOutput, int64, error) {
+	requests, total, err := u.returnRepo.FindByStatus(ctx, status, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return toReturnOutputs(requests), total, nil
+}
+
+// requestedReturn finds a return request that still waits for approval.
+func (u *ReturnUsecase) requestedReturn(ctx context.Context, id uint) (domain.ReturnRequest, error) {
+	request, err := u.returnRepo.FindByID(ctx, id)
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	// Business rule: a return is approved or rejected once
+	if !request.IsRequested() {
+		return domain.ReturnRequest{}, domain.ErrReturnNotRequested
+	}
+
+	return request, nil
+}
+
+// capturedPayment finds the payment that collected an order's money.
+func (u *ReturnUsecase) capturedPayment(ctx context.Context, orderID uint) (domain.Payment, error) {
+	payments, err := u.paymentRepo.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	for _, payment := range payments {
+		if payment.IsCaptured() {
+			return payment, nil
+		}
+	}
+
+	return domain.Payment{}, domain.ErrPaymentRequired
+}
+
+// release puts a return whose refund failed back up for approval. Failures
+// are logged; the return stays held until it is released by hand.
+func (u *ReturnUsecase) release(ctx context.Context, request domain.ReturnRequest) {
+	if err := u.returnRepo.UpdateStatus(ctx, request.ID, domain.ReturnStatusApproving, domain.ReturnStatusRequested); err != nil {
+		log.Printf("Failed to release return %d: %v", request.ID, err)
+	}
+}
+
+// recordRefund adds a refund to an order with a conditional update. When
+// another refund of the same order got there first, it rereads the order
+// and tries again.
+func (u *ReturnUsecase) recordRefund(ctx context.Context, orderID uint, amount float64) (domain.Order, error) {
+	for {
+		order, err := u.orderRepo.FindByID(ctx, orderID)
+		if err != nil {
+			return domain.Order{}, err
+		}
+
+		refunded := order
+		if err := refunded.Refund(amount); err != nil {
+			return domain.Order{}, err
+		}
+
+		err = u.orderRepo.UpdateRefund(ctx, order, refunded)
+		if !errors.Is(err, domain.ErrOrderStatusChanged) {
+			return refunded, err
+		}
+	}
+}
+
+// restock puts the copies of an approved return back into stock and hands
+// them to backorders. Failures are logged; the refund stands and the stock
+// can be corrected with an adjustment.
+func (u *ReturnUsecase) restock(ctx context.Context, order domain.Order, request domain.ReturnRequest) {
+	movement := domain.NewStockMovement(domain.StockReasonReturn, userActor(order.UserID), returnReference(request.ID))
+	_, err := u.bookRepo.UpdateStock(ctx, order.BookID, movement, func(book *domain.Book) error {
+		book.IncreaseStock(request.Quantity, request.Condition)
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to restock return %d of book %d: %v", request.ID, order.BookID, err)
+		return
+	}
+
+	if request.Condition != domain.StockConditionSellable {
+		return
+	}
+	if _, err := u.allocator.AllocateBackorders(ctx, order.BookID); err != nil {
+		log.Printf("Failed to allocate backorders of book %d: %v", order.BookID, err)
+	}
+}
+
+// returnReference identifies a return request in stock movements.
+func returnReference(returnID uint) string {
+	return fmt.Sprintf("return:%d", returnID)
+}
+
+// toReturnOutputs converts domain.ReturnRequest values to ReturnOutput values.
+func toReturnOutputs(requests []domain.ReturnRequest) []ReturnOutput {
+	outputs := make([]ReturnOutput, len(requests))
+	for i, request := range requests {
+		outputs[i] = toReturnOutput(request)
+	}
+	return outputs
+}
+
+// toReturnOutput converts domain.ReturnRequest to ReturnOutput.
+func toReturnOutput(request domain.ReturnRequest) ReturnOutput {
+	return ReturnOutput{
+		ID:              request.ID,
+		OrderID:         request.OrderID,
+		Quantity:        request.Quantity,
+		Reason:          request.Reason,
+		Status:          request.Status,
+		Condition:       request.Condition,
+		RefundAmount:    request.RefundAmount,
+		RejectionReason: request.RejectionReason,
+		CreatedAt:       request.CreatedAt,
+	}
+}
@@ -0,0 +1,291 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestReturnUsecaseApproveRefundsAndRestocks(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 3)
+
+	// A sellable copy is refunded at its share of the total and restocked
+	sellable, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1, Reason: "gift duplicate"})
+	if err != nil || sellable.Status != domain.ReturnStatusRequested {
+		t.Fatalf("Request: %+v, %v", sellable, err)
+	}
+	approved, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: sellable.ID, Condition: domain.StockConditionSellable})
+	if err != nil || approved.Status != domain.ReturnStatusApproved || approved.RefundAmount != 10 {
+		t.Fatalf("Approve: %+v, %v", approved, err)
+	}
+
+	refunded, _ := f.orders.FindByID(ctx, order.ID)
+	if refunded.Status != domain.OrderStatusPartiallyRefunded || refunded.RefundedAmount != 10 {
+		t.Fatalf("expected a partially refunded order, got %+v", refunded)
+	}
+	restocked, _ := f.books.FindByID(ctx, book.ID)
+	if restocked.Stock != 3 {
+		t.Fatalf("expected the returned copy to be restocked, got %d", restocked.Stock)
+	}
+	calls := f.gateway.Calls()
+	if last := calls[len(calls)-1]; last.Operation != "refund" || last.Amount != 10 {
+		t.Fatalf("expected the refund to go through the provider, got %+v", calls)
+	}
+
+	// Damaged copies are refunded but written off
+	damaged, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 2, Reason: "water damage"})
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: damaged.ID, Condition: domain.StockConditionDamaged}); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	refunded, _ = f.orders.FindByID(ctx, order.ID)
+	if refunded.Status != domain.OrderStatusRefunded || refunded.RefundedAmount != 30 {
+		t.Fatalf("expected a refunded order, got %+v", refunded)
+	}
+	restocked, _ = f.books.FindByID(ctx, book.ID)
+	if restocked.Stock != 3 {
+		t.Fatalf("expected damaged copies to stay out of stock, got %d", restocked.Stock)
+	}
+	payments, _ := f.payments.FindByOrderID(ctx, order.ID)
+	if payments[0].Status != domain.PaymentStatusRefunded || payments[0].RefundedAmount != 30 {
+		t.Fatalf("expected a refunded payment, got %+v", payments[0])
+	}
+
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: damaged.ID, Condition: domain.StockConditionDamaged}); !errors.Is(err, domain.ErrReturnNotRequested) {
+		t.Fatalf("expected ErrReturnNotRequested, got %v", err)
+	}
+	if _, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1}); !errors.Is(err, domain.ErrOrderNotReturnable) {
+		t.Fatalf("expected a refunded order to take no more returns, got %v", err)
+	}
+}
+
+func TestReturnUsecaseRefundsShippingOnlyWhenAsked(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	fulfilment := f.fulfilmentUsecase()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+
+	// Two copies cost 20 and their express shipping 15
+	order, err := f.shippedOrder(t, orders, book, 2, "express")
+	if err != nil || order.Total != 35 {
+		t.Fatalf("Create: %+v, %v", order, err)
+	}
+	f.pay(t, orders, order.ID)
+	_, _ = fulfilment.Pack(ctx, order.ID)
+	_, _ = fulfilment.Ship(ctx, usecase.ShipOrderInput{OrderID: order.ID, Carrier: "JNE", TrackingNumber: "JNE123"})
+	if _, err := fulfilment.Deliver(ctx, order.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	// A returned copy is refunded at its share of the goods, not of the shipping
+	first, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1, Reason: "gift duplicate"})
+	approved, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: first.ID, Condition: domain.StockConditionSellable})
+	if err != nil || approved.RefundAmount != 10 {
+		t.Fatalf("expected a refund of the copy alone, got %+v, %v", approved, err)
+	}
+
+	// Staff can refund the shipping on top by naming the amount
+	second, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1, Reason: "arrived late"})
+	approved, err = uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: second.ID, Condition: domain.StockConditionSellable, Amount: 25})
+	if err != nil || approved.RefundAmount != 25 {
+		t.Fatalf("expected a refund of the copy and the shipping, got %+v, %v", approved, err)
+	}
+
+	refunded, _ := f.orders.FindByID(ctx, order.ID)
+	if refunded.Status != domain.OrderStatusRefunded || refunded.RefundedAmount != 35 {
+		t.Fatalf("expected a refunded order, got %+v", refunded)
+	}
+}
+
+func TestReturnUsecaseRefundsAFullReturnWithoutShipping(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.shipping = newShipping(shippingMethods)
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	fulfilment := f.fulfilmentUsecase()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+
+	// Two copies cost 20 and their express shipping 15
+	order, err := f.shippedOrder(t, orders, book, 2, "express")
+	if err != nil || order.Total != 35 {
+		t.Fatalf("Create: %+v, %v", order, err)
+	}
+	f.pay(t, orders, order.ID)
+	_, _ = fulfilment.Pack(ctx, order.ID)
+	_, _ = fulfilment.Ship(ctx, usecase.ShipOrderInput{OrderID: order.ID, Carrier: "JNE", TrackingNumber: "JNE123"})
+	if _, err := fulfilment.Deliver(ctx, order.ID); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	// Returning every copy at the default amount refunds the order
+	request, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 2, Reason: "wrong edition"})
+	approved, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: request.ID, Condition: domain.StockConditionSellable})
+	if err != nil || approved.RefundAmount != 20 {
+		t.Fatalf("expected a refund of the copies, got %+v, %v", approved, err)
+	}
+
+	refunded, _ := f.orders.FindByID(ctx, order.ID)
+	if refunded.Status != domain.OrderStatusRefunded || refunded.RefundedAmount != 20 {
+		t.Fatalf("expected a refunded order, got %+v", refunded)
+	}
+}
+
+func TestReturnUsecaseRequestRules(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+	user := f.user(t, "b@example.com", true)
+
+	pending, _ := f.orderUsecase(usecase.OrderConfig{}).Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if _, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: pending.ID, Quantity: 1}); !errors.Is(err, domain.ErrOrderNotReturnable) {
+		t.Fatalf("expected ErrOrderNotReturnable for a pending order, got %v", err)
+	}
+
+	order := f.completedOrder(t, book, 3)
+	if _, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 0}); !errors.Is(err, domain.ErrInvalidQuantity) {
+		t.Fatalf("expected ErrInvalidQuantity, got %v", err)
+	}
+
+	first, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 2})
+	if _, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 2}); !errors.Is(err, domain.ErrReturnQuantityExceeded) {
+		t.Fatalf("expected ErrReturnQuantityExceeded, got %v", err)
+	}
+
+	// A rejected return frees its copies for another request
+	rejected, err := uc.Reject(ctx, usecase.RejectReturnInput{ReturnID: first.ID, Reason: "outside the return window"})
+	if err != nil || rejected.Status != domain.ReturnStatusRejected || rejected.RejectionReason != "outside the return window" {
+		t.Fatalf("Reject: %+v, %v", rejected, err)
+	}
+	if _, err := uc.Reject(ctx, usecase.RejectReturnInput{ReturnID: first.ID}); !errors.Is(err, domain.ErrReturnNotRequested) {
+		t.Fatalf("expected ErrReturnNotRequested, got %v", err)
+	}
+	all, err := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 3})
+	if err != nil {
+		t.Fatalf("expected the rejected copies to be returnable, got %v", err)
+	}
+
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: all.ID, Condition: "lost"}); !errors.Is(err, domain.ErrInvalidStockCondition) {
+		t.Fatalf("expected ErrInvalidStockCondition, got %v", err)
+	}
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: all.ID, Condition: domain.StockConditionSellable, Amount: 31}); !errors.Is(err, domain.ErrInvalidRefundAmount) {
+		t.Fatalf("expected ErrInvalidRefundAmount, got %v", err)
+	}
+
+	returns, err := uc.FindByOrderID(ctx, order.ID)
+	if err != nil || len(returns) != 2 {
+		t.Fatalf("FindByOrderID: %+v, %v", returns, err)
+	}
+	if requested, _, _ := uc.FindByStatus(ctx, domain.ReturnStatusRequested, domain.Page{}); len(requested) != 1 || requested[0].ID != all.ID {
+		t.Fatalf("expected one requested return, got %+v", requested)
+	}
+}
+
+func TestReturnUsecaseDeclinedRefundChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 1)
+	request, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1})
+
+	f.gateway.Script(payment.FakeDecline)
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: request.ID, Condition: domain.StockConditionSellable}); !errors.Is(err, domain.ErrRefundDeclined) {
+		t.Fatalf("expected ErrRefundDeclined, got %v", err)
+	}
+
+	unchanged, _ := f.orders.FindByID(ctx, order.ID)
+	if unchanged.Status != domain.OrderStatusCompleted || unchanged.RefundedAmount != 0 {
+		t.Fatalf("expected the order to be unchanged, got %+v", unchanged)
+	}
+	stocked, _ := f.books.FindByID(ctx, book.ID)
+	if stocked.Stock != 4 {
+		t.Fatalf("expected no restock, got %d", stocked.Stock)
+	}
+
+	// The return can be approved once the provider refunds it
+	approved, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: request.ID, Condition: domain.StockConditionSellable})
+	if err != nil || approved.Status != domain.ReturnStatusApproved {
+		t.Fatalf("expected a retried approval to succeed, got %+v, %v", approved, err)
+	}
+}
+
+func TestReturnUsecaseRefundsAReturnOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	gateway := &interleavedGateway{FakeGateway: f.gateway}
+	uc := usecase.NewReturnUsecase(f.returnRequests, f.orders, f.books, f.payments, gateway, f.orderUsecase(usecase.OrderConfig{}))
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 3)
+	first, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1})
+	second, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1})
+
+	// While the provider refunds the first return, it cannot be approved or
+	// rejected again, and another return of the order is refunded alongside
+	gateway.duringRefund = func() {
+		_, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: first.ID, Condition: domain.StockConditionSellable})
+		if !errors.Is(err, domain.ErrReturnNotRequested) {
+			t.Errorf("expected a second approval to find the return resolved, got %v", err)
+		}
+		_, err = uc.Reject(ctx, usecase.RejectReturnInput{ReturnID: first.ID, Reason: "too late"})
+		if !errors.Is(err, domain.ErrReturnNotRequested) {
+			t.Errorf("expected a rejection to find the return resolved, got %v", err)
+		}
+		if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: second.ID, Condition: domain.StockConditionSellable}); err != nil {
+			t.Errorf("Approve: %v", err)
+		}
+	}
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: first.ID, Condition: domain.StockConditionSellable}); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	refunds := 0
+	for _, call := range f.gateway.Calls() {
+		if call.Operation == "refund" {
+			refunds++
+		}
+	}
+	if refunds != 2 {
+		t.Fatalf("expected one refund per return, got %d", refunds)
+	}
+	refunded, _ := f.orders.FindByID(ctx, order.ID)
+	if refunded.Status != domain.OrderStatusPartiallyRefunded || refunded.RefundedAmount != 20 {
+		t.Fatalf("expected both refunds on the order, got %+v", refunded)
+	}
+}
+
+func TestReturnUsecaseReleasesAReturnTheProviderFailed(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := f.returnUsecase()
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 1)
+	request, _ := uc.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1})
+
+	f.gateway.Script(payment.FakeDecline)
+	if _, err := uc.Approve(ctx, usecase.ApproveReturnInput{ReturnID: request.ID, Condition: domain.StockConditionSellable}); !errors.Is(err, domain.ErrRefundDeclined) {
+		t.Fatalf("expected ErrRefundDeclined, got %v", err)
+	}
+
+	// The declined return is up for a decision again
+	released, _ := f.returnRequests.FindByID(ctx, request.ID)
+	if !released.IsRequested() {
+		t.Fatalf("expected the return to be requested again, got %s", released.Status)
+	}
+	rejected, err := uc.Reject(ctx, usecase.RejectReturnInput{ReturnID: request.ID, Reason: "not ours"})
+	if err != nil || rejected.Status != domain.ReturnStatusRejected || rejected.RejectionReason != "not ours" {
+		t.Fatalf("Reject: %+v, %v", rejected, err)
+	}
+}
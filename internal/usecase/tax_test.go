@@ -0,0 +1,86 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+func TestOrderUsecaseTaxesOrders(t *testing.T) {
+	ctx := context.Background()
+	rules := []domain.TaxRule{
+		{Rate: 5},
+		{Category: "ebooks", Rate: 2},
+		{Region: "US", Rate: 6},
+		{Region: "US-CA", Rate: 7.25},
+		{Region: "ID", Rate: 11, Inclusive: true},
+		{Region: "ID", Category: "ebooks", Rate: 0, Inclusive: true},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		region   string
+		category string
+		price    float64
+		quantity int
+		want     [3]float64 // subtotal, tax, total
+	}{
+		{"any region", "SG", "fiction", 10, 1, [3]float64{10, 0.5, 10.5}},
+		{"any region, category", "SG", "ebooks", 10, 1, [3]float64{10, 0.2, 10.2}},
+		{"country of a state", "US-NY", "ebooks", 10, 1, [3]float64{10, 0.6, 10.6}},
+		{"state", "us-ca", "fiction", 10, 1, [3]float64{10, 0.73, 10.73}},
+		{"inclusive", "ID", "fiction", 11.1, 1, [3]float64{10, 1.1, 11.1}},
+		{"inclusive, category", "ID", "EBooks", 11.1, 1, [3]float64{11.1, 0, 11.1}},
+		// Tax is rounded once for the line: 3 x 0.0725 would round to 0.21
+		{"rounded per line", "US-CA", "fiction", 1, 3, [3]float64{3, 0.22, 3.22}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture()
+			f.taxes = newTaxes(rules)
+			orders := f.orderUsecase(usecase.OrderConfig{TaxRegion: tc.region})
+			book := domain.NewBook("Go", tc.price, 5)
+			book.Category = tc.category
+			book, _ = f.books.Save(ctx, book, domain.NewInitialStock(""))
+			user := f.user(t, "a@example.com", true)
+
+			order, err := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: tc.quantity})
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if got := [3]float64{order.Subtotal, order.Tax, order.Total}; got != tc.want {
+				t.Fatalf("expected subtotal, tax and total %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOrderUsecaseTaxesDiscountedPrice(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.taxes = newTaxes([]domain.TaxRule{{Region: "ID", Rate: 10}})
+	promotions := f.promotionUsecase()
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+
+	if _, err := promotions.Create(ctx, usecase.CreatePromotionInput{Code: "FIVE", Type: domain.PromotionTypeFixed, Value: 5}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	order, err := f.orderUsecase(usecase.OrderConfig{TaxRegion: "ID"}).Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2, PromotionCode: "FIVE"})
+	if err != nil || order.Subtotal != 15 || order.Tax != 1.5 || order.Total != 16.5 {
+		t.Fatalf("expected tax on the discounted price, got %+v, %v", order, err)
+	}
+	stored, _ := f.orders.FindByID(ctx, order.ID)
+	if stored.Subtotal != 15 || stored.Tax != 1.5 {
+		t.Fatalf("expected the breakdown to be recorded on the order, got %+v", stored)
+	}
+
+	// Regions without a rule cannot be taxed, so they cannot order
+	_, err = f.orderUsecase(usecase.OrderConfig{TaxRegion: "SG"}).Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 1})
+	if !errors.Is(err, domain.ErrTaxRegionUnsupported) {
+		t.Fatalf("expected ErrTaxRegionUnsupported, got %v", err)
+	}
+}
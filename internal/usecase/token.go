@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newToken generates a random URL-safe token.
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken returns the SHA-256 hash of a token.
+// Tokens are stored hashed so a database leak does not expose usable tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signToken creates a token in the form "<id>.<expires>.<signature>".
+// The subject (e.g. an email) is covered by the signature but not embedded,
+// so the token becomes invalid if the subject changes.
+func signToken(secret []byte, id uint, subject string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%d", id, expiresAt.Unix())
+	return payload + "." + signature(secret, payload, subject)
+}
+
+// parseSignedToken validates a token created by signToken and returns its ID.
+// The subject must be looked up by the caller from the returned ID.
+func parseSignedToken(token string) (id uint, expiresAt time.Time, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, time.Time{}, false
+	}
+
+	parsedID, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return uint(parsedID), time.Unix(expires, 0), true
+}
+
+// verifySignedToken checks the signature of a token against the subject.
+func verifySignedToken(secret []byte, token, subject string) bool {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return false
+	}
+
+	expected := signature(secret, token[:i], subject)
+	return hmac.Equal([]byte(expected), []byte(token[i+1:]))
+}
+
+// signature computes the HMAC-SHA256 of payload and subject.
+func signature(secret []byte, payload, subject string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload + "." + subject))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -2,19 +2,46 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
 
 	"kikukafandi/book-shop-api/internal/domain"
 )
 
 // UserUsecase handles all user business logic.
 type UserUsecase struct {
-	userRepo domain.UserRepository
+	userRepo     domain.UserRepository
+	orderRepo    domain.OrderRepository
+	mailer       domain.Mailer
+	verification VerificationConfig
+	resends      *resendThrottle
+}
+
+// VerificationConfig holds settings for email verification.
+type VerificationConfig struct {
+	Secret         string
+	AppURL         string
+	TokenTTL       time.Duration
+	ResendInterval time.Duration
 }
 
 // NewUserUsecase creates a new UserUsecase.
-func NewUserUsecase(userRepo domain.UserRepository) *UserUsecase {
+func NewUserUsecase(
+	userRepo domain.UserRepository,
+	orderRepo domain.OrderRepository,
+	mailer domain.Mailer,
+	verification VerificationConfig,
+) *UserUsecase {
 	return &UserUsecase{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		orderRepo:    orderRepo,
+		mailer:       mailer,
+		verification: verification,
+		resends:      &resendThrottle{requested: make(map[string]time.Time)},
 	}
 }
 
@@ -32,12 +59,19 @@ type LoginInput struct {
 	Password string
 }
 
+// ResendVerificationInput is the input for resending a verification email.
+type ResendVerificationInput struct {
+	Email string
+}
+
 // UserOutput is the output for user operations.
 type UserOutput struct {
-	ID    uint
-	Name  string
-	Email string
-	Role  string
+	ID        uint
+	Name      string
+	Email     string
+	Role      string
+	Verified  bool
+	DeletedAt *time.Time
 }
 
 // Register creates a new user with validation.
@@ -53,15 +87,130 @@ func (u *UserUsecase) Register(ctx context.Context, input RegisterInput) (UserOu
 
 	// In real app, hash password here
 	user := domain.NewUser(input.Name, input.Email, input.Password, input.Role)
+	now := time.Now()
+	user.VerificationSentAt = &now
 
 	saved, err := u.userRepo.Save(ctx, user)
 	if err != nil {
 		return UserOutput{}, err
 	}
 
+	// Registration succeeds even if the mail cannot be sent - the user can resend it
+	if err := u.sendVerification(ctx, saved); err != nil {
+		log.Printf("Failed to send verification mail: %v", err)
+	}
+
 	return toUserOutput(saved), nil
 }
 
+// VerifyEmail confirms a user's email from a signed verification token.
+func (u *UserUsecase) VerifyEmail(ctx context.Context, token string) (UserOutput, error) {
+	id, expiresAt, ok := parseSignedToken(token)
+	if !ok || !time.Now().Before(expiresAt) {
+		return UserOutput{}, domain.ErrInvalidVerifyToken
+	}
+
+	user, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return UserOutput{}, domain.ErrInvalidVerifyToken
+		}
+		return UserOutput{}, err
+	}
+
+	if !verifySignedToken([]byte(u.verification.Secret), token, user.Email) {
+		return UserOutput{}, domain.ErrInvalidVerifyToken
+	}
+
+	// Verifying twice is harmless
+	if user.Verified {
+		return toUserOutput(user), nil
+	}
+
+	user.MarkVerified()
+	updated, err := u.userRepo.Update(ctx, user)
+	if err != nil {
+		return UserOutput{}, err
+	}
+
+	return toUserOutput(updated), nil
+}
+
+// ResendVerification sends a new verification email. Requests are
+// throttled per email whether or not it belongs to an account, and unknown
+// or already verified emails return nil, so callers cannot enumerate users.
+func (u *UserUsecase) ResendVerification(ctx context.Context, input ResendVerificationInput) error {
+	// Business rule: limit how often verification emails are requested
+	now := time.Now()
+	if !u.resends.allow(input.Email, now, u.verification.ResendInterval) {
+		return domain.ErrVerifyThrottled
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, input.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.Verified {
+		return nil
+	}
+
+	user.VerificationSentAt = &now
+	if _, err := u.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return u.sendVerification(ctx, user)
+}
+
+// resendThrottle remembers when each email last requested a verification
+// email. It is kept in memory, so each instance throttles on its own.
+type resendThrottle struct {
+	mu        sync.Mutex
+	requested map[string]time.Time
+}
+
+// allow records a request for an email and reports whether the previous
+// one was at least interval ago.
+func (t *resendThrottle) allow(email string, now time.Time, interval time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Forget requests that no longer throttle anything
+	for key, at := range t.requested {
+		if !now.Before(at.Add(interval)) {
+			delete(t.requested, key)
+		}
+	}
+
+	key := strings.ToLower(strings.TrimSpace(email))
+	if _, ok := t.requested[key]; ok {
+		return false
+	}
+	t.requested[key] = now
+	return true
+}
+
+// sendVerification mails a signed verification link to the user.
+func (u *UserUsecase) sendVerification(ctx context.Context, user domain.User) error {
+	expiresAt := time.Now().Add(u.verification.TokenTTL)
+	token := signToken([]byte(u.verification.Secret), user.ID, user.Email, expiresAt)
+
+	mail := domain.Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s/verify-email?token=%s\n",
+			user.Name, u.verification.TokenTTL, u.verification.AppURL, token,
+		),
+	}
+
+	return u.mailer.Send(ctx, mail)
+}
+
 // Login authenticates user and returns user data.
 func (u *UserUsecase) Login(ctx context.Context, input LoginInput) (UserOutput, error) {
 	user, err := u.userRepo.FindByEmail(ctx, input.Email)
@@ -102,12 +251,59 @@ func (u *UserUsecase) FindAll(ctx context.Context) ([]UserOutput, error) {
 	return outputs, nil
 }
 
+// Delete soft deletes a user by ID.
+func (u *UserUsecase) Delete(ctx context.Context, id uint) error {
+	// Check if user exists
+	_, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Business rule: a user with unfinished orders cannot be deleted. Users
+	// with finished orders can; purging keeps them while orders reference them
+	open, err := u.orderRepo.CountOpenByUserID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return domain.ErrUserHasOpenOrders
+	}
+
+	return u.userRepo.Delete(ctx, id)
+}
+
+// FindDeleted returns all soft deleted users.
+func (u *UserUsecase) FindDeleted(ctx context.Context) ([]UserOutput, error) {
+	users, err := u.userRepo.FindDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]UserOutput, len(users))
+	for i, user := range users {
+		outputs[i] = toUserOutput(user)
+	}
+
+	return outputs, nil
+}
+
+// Restore restores a soft deleted user.
+func (u *UserUsecase) Restore(ctx context.Context, id uint) (UserOutput, error) {
+	if err := u.userRepo.Restore(ctx, id); err != nil {
+		return UserOutput{}, err
+	}
+
+	return u.FindByID(ctx, id)
+}
+
 // toUserOutput converts domain.User to UserOutput.
 func toUserOutput(user domain.User) UserOutput {
 	return UserOutput{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		Verified:  user.Verified,
+		DeletedAt: user.DeletedAt,
 	}
 }
@@ -0,0 +1,165 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+var verificationConfig = usecase.VerificationConfig{
+	Secret:         "test-secret",
+	AppURL:         "http://localhost",
+	TokenTTL:       time.Hour,
+	ResendInterval: time.Minute,
+}
+
+var tokenPattern = regexp.MustCompile(`token=([^\s]+)`)
+
+func TestUserUsecaseRegister(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewUserUsecase(f.users, f.orders, f.mailer, verificationConfig)
+
+	input := usecase.RegisterInput{Name: "Kikuk", Email: "kikuk@example.com", Password: "secret123", Role: "customer"}
+	output, err := uc.Register(ctx, input)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if output.Verified {
+		t.Fatal("expected new user to be unverified")
+	}
+	if len(f.mailer.sent()) != 1 {
+		t.Fatalf("expected a verification mail, got %d", len(f.mailer.sent()))
+	}
+
+	if _, err := uc.Register(ctx, input); !errors.Is(err, domain.ErrEmailExists) {
+		t.Fatalf("expected ErrEmailExists, got %v", err)
+	}
+}
+
+func TestUserUsecaseLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", email: "a@example.com", password: "secret123"},
+		{name: "wrong password", email: "a@example.com", password: "wrong", wantErr: domain.ErrInvalidCredential},
+		{name: "unknown email", email: "b@example.com", password: "secret123", wantErr: domain.ErrInvalidCredential},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			uc := usecase.NewUserUsecase(f.users, f.orders, f.mailer, verificationConfig)
+			f.user(t, "a@example.com", true)
+
+			_, err := uc.Login(context.Background(), usecase.LoginInput{Email: tt.email, Password: tt.password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewUserUsecase(f.users, f.orders, f.mailer, verificationConfig)
+
+	output, err := uc.Register(ctx, usecase.RegisterInput{Name: "A", Email: "a@example.com", Password: "secret123", Role: "customer"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	match := tokenPattern.FindStringSubmatch(f.mailer.sent()[0].Body)
+	if match == nil {
+		t.Fatal("verification mail has no token")
+	}
+	token := match[1]
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "tampered", token: token + "0", wantErr: domain.ErrInvalidVerifyToken},
+		{name: "garbage", token: "not-a-token", wantErr: domain.ErrInvalidVerifyToken},
+		{name: "valid", token: token},
+		{name: "already verified", token: token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := uc.VerifyEmail(ctx, tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && (!verified.Verified || verified.ID != output.ID) {
+				t.Fatalf("unexpected output: %+v", verified)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseResendVerificationIsThrottled(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewUserUsecase(f.users, f.orders, f.mailer, verificationConfig)
+
+	if _, err := uc.Register(ctx, usecase.RegisterInput{Name: "A", Email: "a@example.com", Password: "secret123"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	f.user(t, "b@example.com", true)
+
+	// Unknown and verified emails are throttled exactly like unverified ones
+	for _, email := range []string{"a@example.com", "b@example.com", "nobody@example.com"} {
+		if err := uc.ResendVerification(ctx, usecase.ResendVerificationInput{Email: email}); err != nil {
+			t.Fatalf("expected nil for the first request of %s, got %v", email, err)
+		}
+		err := uc.ResendVerification(ctx, usecase.ResendVerificationInput{Email: email})
+		if !errors.Is(err, domain.ErrVerifyThrottled) {
+			t.Fatalf("expected ErrVerifyThrottled for %s, got %v", email, err)
+		}
+	}
+
+	if err := uc.ResendVerification(ctx, usecase.ResendVerificationInput{Email: " A@Example.com"}); !errors.Is(err, domain.ErrVerifyThrottled) {
+		t.Fatalf("expected the throttle to ignore case, got %v", err)
+	}
+	if sent := len(f.mailer.sent()); sent != 2 {
+		t.Fatalf("expected the registration mail and one resend, got %d mails", sent)
+	}
+}
+
+func TestUserUsecaseDelete(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	uc := usecase.NewUserUsecase(f.users, f.orders, f.mailer, verificationConfig)
+	user := f.user(t, "a@example.com", true)
+	book := f.book(t, 10, 5)
+	order, _ := f.orders.Save(ctx, domain.NewOrder(user.ID, book.ID, 1, 10))
+
+	if err := uc.Delete(ctx, user.ID); !errors.Is(err, domain.ErrUserHasOpenOrders) {
+		t.Fatalf("expected ErrUserHasOpenOrders, got %v", err)
+	}
+
+	// Neither can users whose orders are on their way
+	order.MarkPaid()
+	_, _ = f.orders.Update(ctx, order)
+	if err := uc.Delete(ctx, user.ID); !errors.Is(err, domain.ErrUserHasOpenOrders) {
+		t.Fatalf("expected ErrUserHasOpenOrders for a paid order, got %v", err)
+	}
+
+	// Users with finished orders can be deleted
+	order.Complete()
+	_, _ = f.orders.Update(ctx, order)
+	if err := uc.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("expected a user with completed orders to be soft deleted, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/domain"
+)
+
+// PaymentEventHandler applies payment results reported by the payment provider.
+type PaymentEventHandler interface {
+	ApplyPaymentEvent(ctx context.Context, event domain.PaymentEvent) error
+}
+
+// WebhookConfig holds settings for verifying payment provider webhooks.
+type WebhookConfig struct {
+	// Secret is shared with the provider, which signs every webhook with it.
+	Secret string
+	// Tolerance is how far a webhook's signing time may be from now.
+	Tolerance time.Duration
+}
+
+// WebhookUsecase receives payment provider webhooks. It only processes
+// signed, recent webhooks, and each event only once.
+type WebhookUsecase struct {
+	eventRepo domain.WebhookEventRepository
+	handler   PaymentEventHandler
+	config    WebhookConfig
+}
+
+// NewWebhookUsecase creates a new WebhookUsecase.
+func NewWebhookUsecase(eventRepo domain.WebhookEventRepository, handler PaymentEventHandler, config WebhookConfig) *WebhookUsecase {
+	return &WebhookUsecase{
+		eventRepo: eventRepo,
+		handler:   handler,
+		config:    config,
+	}
+}
+
+// ReceiveWebhookInput is the input for receiving a webhook.
+type ReceiveWebhookInput struct {
+	// Payload is the raw request body the signature covers.
+	Payload []byte
+	// Signature is the signature header created by SignWebhook.
+	Signature string
+	// The payment event decoded from Payload.
+	EventID   string
+	Type      string
+	PaymentID uint
+	Reference string
+	Amount    float64
+	Reason    string
+}
+
+// WebhookOutput is the outcome of receiving a webhook.
+type WebhookOutput struct {
+	EventID string
+	// Duplicate is set when the event was processed before and nothing was done.
+	Duplicate bool
+}
+
+// StoredWebhooksInput selects stored webhooks.
+type StoredWebhooksInput struct {
+	// EventID selects a single event; empty selects all of them.
+	EventID string
+	// Unprocessed skips events that were processed.
+	Unprocessed bool
+}
+
+// WebhookEventOutput is the output for stored webhooks.
+type WebhookEventOutput struct {
+	ID          uint
+	EventID     string
+	Type        string
+	Payload     []byte
+	Error       string
+	ProcessedAt *time.Time
+	ReceivedAt  time.Time
+}
+
+// webhookClaimTTL is how long a delivery holds the event it processes.
+// A delivery that stopped without recording the outcome loses it after.
+const webhookClaimTTL = 5 * time.Minute
+
+// Receive verifies a webhook, stores it and applies its event. Deliveries
+// of an event that was already processed are acknowledged without doing
+// anything; deliveries of an event that failed are processed again. A
+// delivery that arrives while another one is processing the event fails
+// with ErrWebhookEventInProgress, so the provider retries it later.
+func (u *WebhookUsecase) Receive(ctx context.Context, input ReceiveWebhookInput) (WebhookOutput, error) {
+	if !u.verifySignature(input.Payload, input.Signature, time.Now()) {
+		return WebhookOutput{}, domain.ErrInvalidWebhookSignature
+	}
+	if input.EventID == "" || input.Type == "" {
+		return WebhookOutput{}, domain.ErrInvalidWebhookEvent
+	}
+
+	event, err := u.eventRepo.Create(ctx, domain.NewWebhookEvent(input.EventID, input.Type, input.Payload))
+	if errors.Is(err, domain.ErrWebhookEventExists) {
+		event, err = u.eventRepo.FindByEventID(ctx, input.EventID)
+		if err == nil && event.IsProcessed() {
+			return WebhookOutput{EventID: event.EventID, Duplicate: true}, nil
+		}
+		if err == nil {
+			now := time.Now()
+			err = u.eventRepo.Claim(ctx, event.ID, now, now.Add(-webhookClaimTTL))
+		}
+	}
+	if err != nil {
+		return WebhookOutput{}, err
+	}
+
+	paymentEvent := domain.PaymentEvent{
+		ID:        input.EventID,
+		Type:      input.Type,
+		PaymentID: input.PaymentID,
+		Reference: input.Reference,
+		Amount:    input.Amount,
+		Reason:    input.Reason,
+	}
+	if err := u.handler.ApplyPaymentEvent(ctx, paymentEvent); err != nil {
+		if err := u.eventRepo.MarkFailed(ctx, event.ID, err.Error()); err != nil {
+			log.Printf("Failed to record failure of webhook event %s: %v", event.EventID, err)
+		}
+		return WebhookOutput{}, err
+	}
+
+	if err := u.eventRepo.MarkProcessed(ctx, event.ID, time.Now()); err != nil {
+		return WebhookOutput{}, err
+	}
+
+	return WebhookOutput{EventID: event.EventID}, nil
+}
+
+// StoredEvents returns stored webhooks, oldest first, e.g. to replay them.
+func (u *WebhookUsecase) StoredEvents(ctx context.Context, input StoredWebhooksInput) ([]WebhookEventOutput, error) {
+	var events []domain.WebhookEvent
+	if input.EventID != "" {
+		event, err := u.eventRepo.FindByEventID(ctx, input.EventID)
+		if err != nil {
+			return nil, err
+		}
+		events = []domain.WebhookEvent{event}
+	} else {
+		all, err := u.eventRepo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		events = all
+	}
+
+	outputs := make([]WebhookEventOutput, 0, len(events))
+	for _, event := range events {
+		if input.Unprocessed && event.IsProcessed() {
+			continue
+		}
+		outputs = append(outputs, toWebhookEventOutput(event))
+	}
+
+	return outputs, nil
+}
+
+// Sign signs a payload with the configured secret, as the provider does.
+func (u *WebhookUsecase) Sign(payload []byte, now time.Time) string {
+	return SignWebhook(u.config.Secret, now, payload)
+}
+
+// SignWebhook creates the signature header of a webhook payload in the
+// form "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">".
+func SignWebhook(secret string, timestamp time.Time, payload []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + webhookSignature(secret, unix, payload)
+}
+
+// verifySignature checks a signature header against the payload. The
+// header may carry several v1 signatures while the secret is rotated.
+func (u *WebhookUsecase) verifySignature(payload []byte, header string, now time.Time) bool {
+	if u.config.Secret == "" {
+		return false
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	// Business rule: stale webhooks are rejected so a captured request
+	// cannot be replayed later
+	age := now.Sub(time.Unix(unix, 0))
+	if age > u.config.Tolerance || age < -u.config.Tolerance {
+		return false
+	}
+
+	expected := webhookSignature(u.config.Secret, timestamp, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookSignature computes the HMAC-SHA256 of the signing time and payload.
+func webhookSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// toWebhookEventOutput converts domain.WebhookEvent to WebhookEventOutput.
+func toWebhookEventOutput(event domain.WebhookEvent) WebhookEventOutput {
+	return WebhookEventOutput{
+		ID:          event.ID,
+		EventID:     event.EventID,
+		Type:        event.Type,
+		Payload:     event.Payload,
+		Error:       event.Error,
+		ProcessedAt: event.ProcessedAt,
+		ReceivedAt:  event.ReceivedAt,
+	}
+}
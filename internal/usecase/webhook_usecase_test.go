@@ -0,0 +1,251 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kikukafandi/book-shop-api/internal/adapter/payment"
+	"kikukafandi/book-shop-api/internal/domain"
+	"kikukafandi/book-shop-api/internal/usecase"
+)
+
+const testWebhookSecret = "test-secret"
+
+func TestWebhookUsecaseRejectsUnsignedWebhooks(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	handler := &recordingEventHandler{}
+	uc := usecase.NewWebhookUsecase(f.webhookEvents, handler, usecase.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute})
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+
+	signatures := map[string]string{
+		"missing":      "",
+		"wrong secret": usecase.SignWebhook("other-secret", now, payload),
+		"stale":        usecase.SignWebhook(testWebhookSecret, now.Add(-10*time.Minute), payload),
+		"future":       usecase.SignWebhook(testWebhookSecret, now.Add(10*time.Minute), payload),
+	}
+	for name, signature := range signatures {
+		input := usecase.ReceiveWebhookInput{Payload: payload, Signature: signature, EventID: "evt_1", Type: domain.PaymentEventCaptured}
+		if _, err := uc.Receive(ctx, input); !errors.Is(err, domain.ErrInvalidWebhookSignature) {
+			t.Fatalf("%s signature: expected ErrInvalidWebhookSignature, got %v", name, err)
+		}
+	}
+
+	// The signature covers the payload, so a tampered payload is rejected
+	input := usecase.ReceiveWebhookInput{Payload: []byte(`{"id":"evt_2"}`), Signature: uc.Sign(payload, now), EventID: "evt_1", Type: domain.PaymentEventCaptured}
+	if _, err := uc.Receive(ctx, input); !errors.Is(err, domain.ErrInvalidWebhookSignature) {
+		t.Fatalf("expected ErrInvalidWebhookSignature for a tampered payload, got %v", err)
+	}
+
+	// A rotated secret is accepted next to the old one
+	_, current, _ := strings.Cut(uc.Sign(payload, now), ",")
+	rotated := usecase.SignWebhook("old-secret", now, payload) + "," + current
+	input = usecase.ReceiveWebhookInput{Payload: payload, Signature: rotated, EventID: "evt_1", Type: domain.PaymentEventCaptured}
+	if _, err := uc.Receive(ctx, input); err != nil {
+		t.Fatalf("expected any matching signature to be accepted, got %v", err)
+	}
+
+	if len(handler.events) != 1 {
+		t.Fatalf("expected only the signed webhook to be applied, got %+v", handler.events)
+	}
+}
+
+func TestWebhookUsecaseProcessesEachEventOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	handler := &recordingEventHandler{err: errors.New("order is locked")}
+	uc := usecase.NewWebhookUsecase(f.webhookEvents, handler, usecase.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute})
+	input := signedWebhook(t, uc, "evt_1", domain.PaymentEventCaptured)
+
+	// A failed event is stored with its error and processed again on redelivery
+	if _, err := uc.Receive(ctx, input); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	failed, _ := uc.StoredEvents(ctx, usecase.StoredWebhooksInput{Unprocessed: true})
+	if len(failed) != 1 || failed[0].Error != "order is locked" {
+		t.Fatalf("expected the failed event to be kept, got %+v", failed)
+	}
+
+	handler.err = nil
+	output, err := uc.Receive(ctx, input)
+	if err != nil || output.Duplicate {
+		t.Fatalf("expected the redelivered event to be processed, got %+v, %v", output, err)
+	}
+
+	output, err = uc.Receive(ctx, input)
+	if err != nil || !output.Duplicate {
+		t.Fatalf("expected the processed event to be acknowledged, got %+v, %v", output, err)
+	}
+	if len(handler.events) != 2 {
+		t.Fatalf("expected the event to be applied until it succeeded, got %+v", handler.events)
+	}
+
+	if pending, _ := uc.StoredEvents(ctx, usecase.StoredWebhooksInput{Unprocessed: true}); len(pending) != 0 {
+		t.Fatalf("expected no unprocessed events, got %+v", pending)
+	}
+	stored, err := uc.StoredEvents(ctx, usecase.StoredWebhooksInput{EventID: "evt_1"})
+	if err != nil || len(stored) != 1 || stored[0].ProcessedAt == nil || stored[0].Error != "" {
+		t.Fatalf("expected the processed event to be stored, got %+v, %v", stored, err)
+	}
+
+	missing := signedWebhook(t, uc, "", domain.PaymentEventCaptured)
+	if _, err := uc.Receive(ctx, missing); !errors.Is(err, domain.ErrInvalidWebhookEvent) {
+		t.Fatalf("expected ErrInvalidWebhookEvent, got %v", err)
+	}
+}
+
+func TestWebhookUsecaseLeavesAnEventToTheDeliveryProcessingIt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	handler := &recordingEventHandler{}
+	uc := usecase.NewWebhookUsecase(f.webhookEvents, handler, usecase.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute})
+	input := signedWebhook(t, uc, "evt_1", domain.PaymentEventRefunded)
+
+	// The provider redelivers the event while the first delivery applies it
+	var redeliveryErr error
+	handler.during = func() {
+		_, redeliveryErr = uc.Receive(ctx, input)
+	}
+	if _, err := uc.Receive(ctx, input); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if !errors.Is(redeliveryErr, domain.ErrWebhookEventInProgress) {
+		t.Fatalf("expected ErrWebhookEventInProgress for the redelivery, got %v", redeliveryErr)
+	}
+	if len(handler.events) != 1 {
+		t.Fatalf("expected the event to be applied once, got %+v", handler.events)
+	}
+
+	// Once processed, redeliveries are acknowledged
+	if output, err := uc.Receive(ctx, input); err != nil || !output.Duplicate {
+		t.Fatalf("expected the processed event to be acknowledged, got %+v, %v", output, err)
+	}
+}
+
+func TestWebhookUsecaseAppliesPaymentEventsToOrders(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	book := f.book(t, 10, 5)
+	user := f.user(t, "a@example.com", true)
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	uc := usecase.NewWebhookUsecase(f.webhookEvents, orders, usecase.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute})
+
+	order, _ := orders.Create(ctx, usecase.CreateOrderInput{UserID: user.ID, BookID: book.ID, Quantity: 2})
+	f.gateway.Script(payment.FakeTimeout)
+	if _, err := orders.Pay(ctx, usecase.PayOrderInput{OrderID: order.ID, PaymentToken: "tok_visa"}); !errors.Is(err, domain.ErrPaymentTimeout) {
+		t.Fatalf("expected ErrPaymentTimeout, got %v", err)
+	}
+	payments, _ := orders.Payments(ctx, order.ID)
+
+	// The provider reports the payment that timed out for us as captured
+	input := signedWebhook(t, uc, "evt_1", domain.PaymentEventCaptured)
+	input.PaymentID = payments[0].ID
+	input.Reference = "provider_ref"
+	if _, err := uc.Receive(ctx, input); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	paid, _ := orders.FindByID(ctx, order.ID)
+	if paid.Status != domain.OrderStatusPaid {
+		t.Fatalf("expected the order to be paid, got %s", paid.Status)
+	}
+	payments, _ = orders.Payments(ctx, order.ID)
+	if payments[0].Status != domain.PaymentStatusCaptured || payments[0].Reference != "provider_ref" {
+		t.Fatalf("expected the payment to be captured, got %+v", payments[0])
+	}
+	stocked, _ := f.books.FindByID(ctx, book.ID)
+	if stocked.Stock != 3 {
+		t.Fatalf("expected the stock to be taken once, got %d", stocked.Stock)
+	}
+
+	// Events for payments we do not know are acknowledged
+	unknown := signedWebhook(t, uc, "evt_2", domain.PaymentEventCaptured)
+	unknown.PaymentID = 999
+	if _, err := uc.Receive(ctx, unknown); err != nil {
+		t.Fatalf("expected an unknown payment to be ignored, got %v", err)
+	}
+}
+
+func TestWebhookUsecaseCountsARefundOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	orders := f.orderUsecase(usecase.OrderConfig{})
+	returns := f.returnUsecase()
+	uc := usecase.NewWebhookUsecase(f.webhookEvents, orders, usecase.WebhookConfig{Secret: testWebhookSecret, Tolerance: 5 * time.Minute})
+	book := f.book(t, 10, 5)
+	order := f.completedOrder(t, book, 3)
+
+	request, _ := returns.Request(ctx, usecase.RequestReturnInput{OrderID: order.ID, Quantity: 1})
+	if _, err := returns.Approve(ctx, usecase.ApproveReturnInput{ReturnID: request.ID, Condition: domain.StockConditionSellable}); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	payments, _ := f.payments.FindByOrderID(ctx, order.ID)
+
+	// The provider reports the refund the approval already recorded
+	input := signedWebhook(t, uc, "evt_1", domain.PaymentEventRefunded)
+	input.PaymentID = payments[0].ID
+	input.Amount = 10
+	if _, err := uc.Receive(ctx, input); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	payments, _ = f.payments.FindByOrderID(ctx, order.ID)
+	if payments[0].Status != domain.PaymentStatusCaptured || payments[0].RefundedAmount != 10 {
+		t.Fatalf("expected the refund to be counted once, got %+v", payments[0])
+	}
+
+	// Reports of the total refunded only ever raise it
+	earlier := signedWebhook(t, uc, "evt_2", domain.PaymentEventRefunded)
+	earlier.PaymentID = payments[0].ID
+	earlier.Amount = 5
+	if _, err := uc.Receive(ctx, earlier); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	later := signedWebhook(t, uc, "evt_3", domain.PaymentEventRefunded)
+	later.PaymentID = payments[0].ID
+	later.Amount = 30.004
+	if _, err := uc.Receive(ctx, later); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	payments, _ = f.payments.FindByOrderID(ctx, order.ID)
+	if payments[0].Status != domain.PaymentStatusRefunded || payments[0].RefundedAmount != 30 {
+		t.Fatalf("expected a rounded refunded total, got %+v", payments[0])
+	}
+}
+
+// signedWebhook builds a webhook input signed with the usecase's secret.
+func signedWebhook(t *testing.T, uc *usecase.WebhookUsecase, eventID, eventType string) usecase.ReceiveWebhookInput {
+	t.Helper()
+	payload, err := json.Marshal(map[string]string{"id": eventID, "type": eventType})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return usecase.ReceiveWebhookInput{
+		Payload:   payload,
+		Signature: uc.Sign(payload, time.Now()),
+		EventID:   eventID,
+		Type:      eventType,
+	}
+}
+
+// recordingEventHandler is a usecase.PaymentEventHandler that keeps events
+// for assertions and fails with err when it is set.
+type recordingEventHandler struct {
+	events []domain.PaymentEvent
+	err    error
+	// during runs once while the next event is being applied.
+	during func()
+}
+
+func (h *recordingEventHandler) ApplyPaymentEvent(_ context.Context, event domain.PaymentEvent) error {
+	if step := h.during; step != nil {
+		h.during = nil
+		step()
+	}
+	h.events = append(h.events, event)
+	return h.err
+}